@@ -0,0 +1,82 @@
+// storectl reports the size & contents of a block/transaction store and compacts it.
+//
+//	storectl [-db path] stats
+//	storectl [-db path] compact
+//
+// stats only reads the store, compact requires it to be closed. Both wait for the server to release its lock, stop it
+// first.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sochain-client/pkg/store"
+	"sochain-client/pkg/util"
+	"sort"
+)
+
+func main() {
+
+	path := flag.String("db", util.GetEnv("STORE_PATH", "sochain.db"), "path of the store")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-db path] stats|compact\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	switch flag.Arg(0) {
+	case "stats":
+		stats(*path)
+	case "compact":
+		before, after, err := store.Compact(*path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("compacted %s: %d -> %d bytes\n", *path, before, after)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func stats(path string) {
+	s, err := store.OpenReadOnly(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer s.Close()
+
+	stats, err := s.Stats()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("path:           %s\n", path)
+	fmt.Printf("schema version: %d\n", stats.SchemaVersion)
+	fmt.Printf("size:           %d bytes\n", stats.Size)
+
+	networks := map[string]bool{}
+	for id := range stats.Blocks {
+		networks[id] = true
+	}
+	for id := range stats.Transactions {
+		networks[id] = true
+	}
+	ids := make([]string, 0, len(networks))
+	for id := range networks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	fmt.Printf("%-10s %10s %14s\n", "network", "blocks", "transactions")
+	for _, id := range ids {
+		fmt.Printf("%-10s %10d %14d\n", id, stats.Blocks[id], stats.Transactions[id])
+	}
+}
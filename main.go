@@ -3,53 +3,184 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"sochain-client/pkg/cache"
+	"sochain-client/pkg/coalesce"
 	"sochain-client/pkg/controller"
+	"sochain-client/pkg/esplora"
+	"sochain-client/pkg/failover"
+	"sochain-client/pkg/metrics"
+	"sochain-client/pkg/network"
+	"sochain-client/pkg/rpcnode"
 	"sochain-client/pkg/sochain"
+	"sochain-client/pkg/store"
+	"sochain-client/pkg/tracing"
 	"sochain-client/pkg/util"
-	"os"
-	"os/signal"
+	"sochain-client/pkg/watcher"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 )
 
 func main() {
+	// deferred closes flush spans & the store, failures return with exitCode instead of skipping them by log.Fatal
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
 
+	if err := run(); err != nil {
+		log.Print(err)
+		exitCode = 1
+	}
+}
+
+// run serves until SIGINT or SIGTERM, setup failures are returned so the deferred closes run before main exits
+func run() error {
 	err := godotenv.Load()
 	if err != nil {
-		log.Fatal("missing .env file")
+		return errors.New("missing .env file")
 	}
 
 	logger, err := util.NewLogger(util.GetEnv("CI_ENV", util.EnvironmentDev))
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
+	timeout, err := time.ParseDuration(util.GetEnv("REQUEST_TIMEOUT", "30s"))
+	if err != nil {
+		return fmt.Errorf("REQUEST_TIMEOUT: %w", err)
+	}
+
+	concurrency, err := txConcurrency()
+	if err != nil {
+		return err
+	}
+
+	m := metrics.New(prometheus.DefaultRegisterer)
+
+	tp, shutdownTracing, err := newTracerProvider()
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing()
+	t := tracing.New(tp, tracing.WithConcurrency(concurrency))
+
 	r := gin.Default()
+	r.Use(t.Middleware(), m.Middleware())
+
+	networks := network.DefaultRegistry()
+	if path, ok := os.LookupEnv("NETWORKS_CONFIG"); ok {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+
+		networks, err = network.LoadRegistry(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	if disabled, ok := os.LookupEnv("NETWORKS_DISABLED"); ok {
+		networks.Disable(strings.Split(disabled, ",")...)
+	}
+
+	var providers []failover.Provider
+	for _, name := range strings.Split(util.GetEnv("PROVIDER", "sochain"), ",") {
+		name = strings.TrimSpace(name)
+
+		var c sochain.Connector
+		switch name {
+		case "sochain":
+			c, err = newSochain(logger, m, networks, concurrency)
+		case "esplora":
+			c, err = newEsplora(networks, concurrency)
+		case "node":
+			c, err = newNode(networks, concurrency)
+		default:
+			err = fmt.Errorf("unknown provider '%s'", name)
+		}
+		if err != nil {
+			return err
+		}
+		providers = append(providers, failover.Provider{Name: name, Connector: m.Connector(name, t.Connector(name, c))})
+	}
+
+	client := providers[0].Connector
+	if len(providers) > 1 {
+		if client, err = newFailover(logger, providers, concurrency); err != nil {
+			return err
+		}
+	}
+	if path, ok := os.LookupEnv("STORE_PATH"); ok {
+		s, err := store.Open(path)
+		if err != nil {
+			return err
+		}
+		defer s.Close()
+
+		if client, err = newReadThrough(logger, s, client, networks); err != nil {
+			return err
+		}
+	}
+	if client, err = newCache(m, client, networks); err != nil {
+		return err
+	}
+	if client, err = newCoalescer(m, client, concurrency); err != nil {
+		return err
+	}
+
+	w, err := newWatcher(logger, client, networks)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
 
-	client := sochain.NewSochain()
-	controller := controller.NewController(logger, client)
-	RegisterRoutes(r, controller)
+	controller := controller.NewController(logger, client, networks,
+		controller.WithWatcher(w),
+		controller.WithTxFetchObserver(m.ObserveTxFetches),
+	)
+	RegisterRoutes(r, controller, timeout)
 
 	srv := &http.Server{
-		Addr:    util.GetEnv("HOST", "localhost") +":"+ util.GetEnv("API_PORT", "8080"), 
+		Addr:    util.GetEnv("HOST", "localhost") + ":" + util.GetEnv("API_PORT", "8080"),
 		Handler: r,
 	}
+	// block streams only end once the watcher closes, Shutdown would wait for them until forced
+	srv.RegisterOnShutdown(w.Close)
 
+	serveErr := make(chan error, 1)
 	go func() {
-		err := srv.ListenAndServe()
-		if err != nil && errors.Is(err, http.ErrServerClosed) {
-			log.Fatal(err)
-		}
+		serveErr <- srv.ListenAndServe()
 	}()
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	select {
+	case err := <-serveErr:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("server failed %w", err)
+		}
+	case <-quit:
+	}
 	log.Println("server shutdown started...")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -58,9 +189,307 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Printf("server shutdown was forced %v", err)
 	}
+
+	return nil
+}
+
+// newTracerProvider samples TRACE_SAMPLE_RATIO of the traces started here, traces of callers keep their sampling
+// decision. The returned func flushes pending spans
+func newTracerProvider() (trace.TracerProvider, func(), error) {
+
+	exp, err := tracing.NewExporter(util.GetEnv("TRACE_EXPORTER", tracing.ExporterNone), os.Stdout)
+	if err != nil {
+		return nil, nil, err
+	}
+	if exp == nil {
+		return trace.NewNoopTracerProvider(), func() {}, nil
+	}
+
+	ratio, err := strconv.ParseFloat(util.GetEnv("TRACE_SAMPLE_RATIO", "1"), 64)
+	if err != nil {
+		return nil, nil, fmt.Errorf("TRACE_SAMPLE_RATIO: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String("sochain-client"))),
+	)
+
+	return tp, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := tp.Shutdown(ctx); err != nil {
+			log.Printf("unable to flush spans %v", err)
+		}
+	}, nil
+}
+
+func newSochain(logger *zap.Logger, m *metrics.Metrics, networks *network.Registry, concurrency int) (sochain.Connector, error) {
+
+	upstreamTimeout, err := time.ParseDuration(util.GetEnv("SOCHAIN_TIMEOUT", "10s"))
+	if err != nil {
+		return nil, fmt.Errorf("SOCHAIN_TIMEOUT: %w", err)
+	}
+
+	retryPolicy := sochain.DefaultRetryPolicy()
+	retryPolicy.MaxAttempts, err = strconv.Atoi(util.GetEnv("SOCHAIN_MAX_ATTEMPTS", "3"))
+	if err != nil {
+		return nil, fmt.Errorf("SOCHAIN_MAX_ATTEMPTS: %w", err)
+	}
+
+	verifyTx, err := strconv.ParseBool(util.GetEnv("SOCHAIN_VERIFY_TX", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("SOCHAIN_VERIFY_TX: %w", err)
+	}
+
+	verifyMerkle, err := strconv.ParseBool(util.GetEnv("SOCHAIN_VERIFY_MERKLE", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("SOCHAIN_VERIFY_MERKLE: %w", err)
+	}
+
+	opts := []sochain.Option{
+		sochain.WithTxVerification(verifyTx),
+		sochain.WithMerkleVerification(verifyMerkle),
+		sochain.WithLogger(logger),
+		sochain.WithNetworks(networks),
+		sochain.WithRetryPolicy(retryPolicy),
+		sochain.WithRetryObserver(m.ObserveRetry),
+		sochain.WithTimeout(upstreamTimeout),
+		sochain.WithUserAgent(util.GetEnv("SOCHAIN_USER_AGENT", "sochain-client")),
+		sochain.WithConcurrency(concurrency),
+	}
+	if baseURL, ok := os.LookupEnv("SOCHAIN_URL"); ok {
+		opts = append(opts, sochain.WithBaseURL(baseURL))
+	}
+
+	if v, ok := os.LookupEnv("SOCHAIN_RATE_LIMIT"); ok {
+		rps, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("SOCHAIN_RATE_LIMIT: %w", err)
+		}
+
+		burst, err := strconv.Atoi(util.GetEnv("SOCHAIN_RATE_BURST", "1"))
+		if err != nil {
+			return nil, fmt.Errorf("SOCHAIN_RATE_BURST: %w", err)
+		}
+		if burst < 1 {
+			return nil, fmt.Errorf("SOCHAIN_RATE_BURST must be at least 1, got %d", burst)
+		}
+
+		opts = append(opts,
+			sochain.WithRateLimit(sochain.RateLimit{Rate: rps, Burst: burst}),
+			sochain.WithRateLimitObserver(func(networkID string, wait time.Duration) {
+				m.ObserveRateLimit(networkID, wait)
+				if wait > time.Second {
+					logger.Warn("sochain rate limit saturated", zap.String("network", networkID), zap.Duration("wait", wait))
+				}
+			}),
+		)
+	}
+
+	return sochain.NewSochain(opts...), nil
+}
+
+func newEsplora(networks *network.Registry, concurrency int) (sochain.Connector, error) {
+
+	upstreamTimeout, err := time.ParseDuration(util.GetEnv("ESPLORA_TIMEOUT", "10s"))
+	if err != nil {
+		return nil, fmt.Errorf("ESPLORA_TIMEOUT: %w", err)
+	}
+
+	opts := []esplora.Option{
+		esplora.WithNetworks(networks),
+		esplora.WithTimeout(upstreamTimeout),
+		esplora.WithUserAgent(util.GetEnv("ESPLORA_USER_AGENT", "sochain-client")),
+		esplora.WithConcurrency(concurrency),
+	}
+	if urls, ok := os.LookupEnv("ESPLORA_URLS"); ok {
+		for _, u := range strings.Split(urls, ",") {
+			kv := strings.SplitN(u, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("invalid ESPLORA_URLS entry '%s', expected <network>=<url>", u)
+			}
+			opts = append(opts, esplora.WithBaseURL(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])))
+		}
+	}
+
+	return esplora.NewEsplora(opts...), nil
+}
+
+func newNode(networks *network.Registry, concurrency int) (sochain.Connector, error) {
+
+	upstreamTimeout, err := time.ParseDuration(util.GetEnv("NODE_TIMEOUT", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("NODE_TIMEOUT: %w", err)
+	}
+
+	opts := []rpcnode.Option{
+		rpcnode.WithNetworks(networks),
+		rpcnode.WithTimeout(upstreamTimeout),
+		rpcnode.WithConcurrency(concurrency),
+	}
+	for _, id := range networks.IDs() {
+		suffix := strings.ToUpper(id)
+		url, ok := os.LookupEnv("NODE_RPC_URL_" + suffix)
+		if !ok {
+			continue
+		}
+
+		opts = append(opts, rpcnode.WithEndpoint(id, rpcnode.Endpoint{
+			URL:        url,
+			User:       os.Getenv("NODE_RPC_USER_" + suffix),
+			Password:   os.Getenv("NODE_RPC_PASSWORD_" + suffix),
+			CookieFile: os.Getenv("NODE_RPC_COOKIE_FILE_" + suffix),
+		}))
+	}
+
+	return rpcnode.NewNode(opts...), nil
 }
 
-func RegisterRoutes(e *gin.Engine, c *controller.Controller) {
+func newFailover(logger *zap.Logger, providers []failover.Provider, concurrency int) (sochain.Connector, error) {
+
+	cooldown, err := time.ParseDuration(util.GetEnv("FAILOVER_COOLDOWN", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("FAILOVER_COOLDOWN: %w", err)
+	}
+
+	threshold, err := strconv.Atoi(util.GetEnv("FAILOVER_THRESHOLD", "3"))
+	if err != nil {
+		return nil, fmt.Errorf("FAILOVER_THRESHOLD: %w", err)
+	}
+
+	consensus, err := strconv.ParseBool(util.GetEnv("FAILOVER_CONSENSUS", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("FAILOVER_CONSENSUS: %w", err)
+	}
+
+	return failover.NewFailover(providers,
+		failover.WithLogger(logger),
+		failover.WithCooldown(cooldown),
+		failover.WithFailureThreshold(threshold),
+		failover.WithConsensus(consensus),
+		failover.WithConcurrency(concurrency),
+	), nil
+}
+
+// txConcurrency is the limit of parallel transaction requests of a batch, e.g. the transactions of a block
+func txConcurrency() (int, error) {
+	n, err := strconv.Atoi(util.GetEnv("TX_CONCURRENCY", strconv.Itoa(sochain.DefaultConcurrency)))
+	if err != nil {
+		return 0, fmt.Errorf("TX_CONCURRENCY: %w", err)
+	}
+
+	return n, nil
+}
+
+func newCache(m *metrics.Metrics, client sochain.Connector, networks *network.Registry) (sochain.Connector, error) {
+
+	size, err := strconv.Atoi(util.GetEnv("CACHE_SIZE", "10000"))
+	if err != nil {
+		return nil, fmt.Errorf("CACHE_SIZE: %w", err)
+	}
+	if size <= 0 {
+		return client, nil
+	}
+
+	ttl, err := time.ParseDuration(util.GetEnv("CACHE_TTL", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("CACHE_TTL: %w", err)
+	}
+
+	infoTTL, err := time.ParseDuration(util.GetEnv("CACHE_INFO_TTL", "10s"))
+	if err != nil {
+		return nil, fmt.Errorf("CACHE_INFO_TTL: %w", err)
+	}
+
+	c := cache.NewCache(client,
+		cache.WithNetworks(networks),
+		cache.WithMaxEntries(size),
+		cache.WithTTL(ttl),
+		cache.WithNetworkInfoTTL(infoTTL),
+	)
+	m.RegisterCache(c)
+
+	return c, nil
+}
+
+func newCoalescer(m *metrics.Metrics, client sochain.Connector, concurrency int) (sochain.Connector, error) {
+
+	enabled, err := strconv.ParseBool(util.GetEnv("COALESCE", "true"))
+	if err != nil {
+		return nil, fmt.Errorf("COALESCE: %w", err)
+	}
+	if !enabled {
+		return client, nil
+	}
+
+	c := coalesce.NewCoalescer(client, coalesce.WithConcurrency(concurrency))
+	m.RegisterCoalescer(c)
+
+	return c, nil
+}
+
+func newReadThrough(logger *zap.Logger, s *store.Store, client sochain.Connector, networks *network.Registry) (sochain.Connector, error) {
+
+	offline, err := strconv.ParseBool(util.GetEnv("STORE_OFFLINE", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("STORE_OFFLINE: %w", err)
+	}
+
+	opts := []store.Option{
+		store.WithLogger(logger),
+		store.WithNetworks(networks),
+	}
+	if offline {
+		opts = append(opts, store.WithOffline())
+	}
+
+	return store.NewReadThrough(s, client, opts...), nil
+}
+
+func newWatcher(logger *zap.Logger, client sochain.Connector, networks *network.Registry) (*watcher.Watcher, error) {
+
+	interval, err := time.ParseDuration(util.GetEnv("WATCH_INTERVAL", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("WATCH_INTERVAL: %w", err)
+	}
+
+	opts := []watcher.Option{
+		watcher.WithLogger(logger),
+		watcher.WithInterval(interval),
+	}
+	for _, id := range networks.IDs() {
+		v, ok := os.LookupEnv("WATCH_INTERVAL_" + strings.ToUpper(id))
+		if !ok {
+			continue
+		}
+
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("WATCH_INTERVAL_%s: %w", strings.ToUpper(id), err)
+		}
+		opts = append(opts, watcher.WithNetworkInterval(id, d))
+	}
+
+	return watcher.NewWatcher(client, opts...), nil
+}
+
+func RegisterRoutes(e *gin.Engine, c *controller.Controller, timeout time.Duration) {
+	// streams outlive the request timeout, scrapes never reach the providers
+	e.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	e.GET("/network/:id/blocks/stream", c.HandleStreamBlocks)
+
+	e.Use(controller.Timeout(timeout))
 	e.GET("/network/:id", c.HandleGetBlock)
+	e.GET("/network/:id/blocks", c.HandleGetBlocks)
 	e.GET("/network/:id/tx/:txhash", c.HandleGetTransaction)
+	e.GET("/network/:id/tx/:txhash/outputs/:n/spender", c.HandleGetOutputSpender)
+	e.GET("/network/:id/tx/:txhash/proof", c.HandleGetTransactionProof)
+	e.POST("/network/:id/tx", c.HandlePostTransaction)
+	e.GET("/network/:id/address/:address", c.HandleGetAddressBalance)
+	e.GET("/network/:id/address/:address/received", c.HandleGetReceivedTransactions)
+	e.GET("/network/:id/address/:address/spent", c.HandleGetSpentTransactions)
+	e.GET("/network/:id/address/:address/unspent", c.HandleGetUnspentOutputs)
 }
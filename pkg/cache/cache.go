@@ -0,0 +1,281 @@
+// Package cache decorates a sochain.Connector with an in-memory LRU cache of blocks, transactions & network infos.
+// Blocks & transactions with at least the finality threshold of confirmations of their network never change and are
+// kept until evicted, everything else expires after a short TTL. Every caller gets its own copy of a cached entry
+package cache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sochain-client/pkg/network"
+	"sochain-client/pkg/sochain"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats are the counters of a Cache
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Entries   int
+}
+
+type entry struct {
+	key   string
+	value interface{}
+	// zero for final entries
+	expires time.Time
+}
+
+var _ sochain.Connector = (*Cache)(nil)
+
+type Cache struct {
+	next       sochain.Connector
+	networks   *network.Registry
+	maxEntries int
+	ttl        time.Duration
+	infoTTL    time.Duration
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+
+	hits, misses, evictions uint64
+}
+
+// NewCache caches the responses of next
+func NewCache(next sochain.Connector, opts ...Option) *Cache {
+	c := &Cache{
+		next:       next,
+		networks:   network.DefaultRegistry(),
+		maxEntries: 10000,
+		ttl:        30 * time.Second,
+		infoTTL:    10 * time.Second,
+		now:        time.Now,
+		entries:    map[string]*list.Element{},
+		lru:        list.New(),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Stats reports the counters of c
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	entries := c.lru.Len()
+	c.mu.Unlock()
+
+	return Stats{
+		Hits:      atomic.LoadUint64(&c.hits),
+		Misses:    atomic.LoadUint64(&c.misses),
+		Evictions: atomic.LoadUint64(&c.evictions),
+		Entries:   entries,
+	}
+}
+
+func (c *Cache) NetworkInfo(ctx context.Context, networkID string) (*sochain.NetworkInfo, error) {
+
+	key := fmt.Sprintf("info/%s", strings.ToLower(networkID))
+	if v, ok := c.get(key); ok {
+		info := *v.(*sochain.NetworkInfo)
+		return &info, nil
+	}
+
+	info, err := c.next.NetworkInfo(ctx, networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := *info
+	c.add(key, &cached, c.now().Add(c.infoTTL))
+	return info, nil
+}
+
+func (c *Cache) BlockHeight(ctx context.Context, networkID string, height int) (*sochain.Block, error) {
+
+	key := fmt.Sprintf("block/%s/height/%d", strings.ToLower(networkID), height)
+	if v, ok := c.get(key); ok {
+		return v.(*sochain.Block).Copy(), nil
+	}
+
+	b, err := c.next.BlockHeight(ctx, networkID, height)
+	if err != nil {
+		return nil, err
+	}
+
+	c.addBlock(networkID, b)
+	return b, nil
+}
+
+func (c *Cache) BlockHash(ctx context.Context, networkID, blockHash string) (*sochain.Block, error) {
+
+	key := fmt.Sprintf("block/%s/hash/%s", strings.ToLower(networkID), strings.ToLower(blockHash))
+	if v, ok := c.get(key); ok {
+		return v.(*sochain.Block).Copy(), nil
+	}
+
+	b, err := c.next.BlockHash(ctx, networkID, blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	c.addBlock(networkID, b)
+	return b, nil
+}
+
+func (c *Cache) Transaction(ctx context.Context, networkID, txHash string) (*sochain.Transaction, error) {
+
+	key := fmt.Sprintf("tx/%s/%s", strings.ToLower(networkID), strings.ToLower(txHash))
+	if v, ok := c.get(key); ok {
+		return v.(*sochain.Transaction).Copy(), nil
+	}
+
+	tx, err := c.next.Transaction(ctx, networkID, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	c.addTransaction(networkID, txHash, tx)
+	return tx, nil
+}
+
+// Transactions serves the cached transactions of hashes & fetches the others from next in one batch
+func (c *Cache) Transactions(ctx context.Context, networkID string, hashes []string) ([]sochain.TxResult, error) {
+
+	results := make([]sochain.TxResult, len(hashes))
+	var missing []string
+	var missingIdx []int
+	for i, hash := range hashes {
+		key := fmt.Sprintf("tx/%s/%s", strings.ToLower(networkID), strings.ToLower(hash))
+		if v, ok := c.get(key); ok {
+			results[i] = sochain.TxResult{Hash: hash, Tx: v.(*sochain.Transaction).Copy()}
+			continue
+		}
+
+		missing = append(missing, hash)
+		missingIdx = append(missingIdx, i)
+	}
+	if len(missing) == 0 {
+		return results, nil
+	}
+
+	fetched, err := c.next.Transactions(ctx, networkID, missing)
+	if err != nil {
+		return nil, err
+	}
+	for j, r := range fetched {
+		if r.Err == nil {
+			c.addTransaction(networkID, r.Hash, r.Tx)
+		}
+		results[missingIdx[j]] = r
+	}
+
+	return results, nil
+}
+
+// Address lists change with every transaction of the address & are never cached
+func (c *Cache) AddressBalance(ctx context.Context, networkID, address string) (*sochain.AddressBalance, error) {
+	return c.next.AddressBalance(ctx, networkID, address)
+}
+
+func (c *Cache) ReceivedTransactions(ctx context.Context, networkID, address, afterTxid string) (*sochain.ReceivedTxs, error) {
+	return c.next.ReceivedTransactions(ctx, networkID, address, afterTxid)
+}
+
+// OutputSpender is never cached, outputs of final transactions are spent later
+func (c *Cache) OutputSpender(ctx context.Context, networkID, txHash string, outputNo int) (*sochain.SpentBy, error) {
+	return c.next.OutputSpender(ctx, networkID, txHash, outputNo)
+}
+
+func (c *Cache) SpentTransactions(ctx context.Context, networkID, address, afterTxid string) (*sochain.SpentTxs, error) {
+	return c.next.SpentTransactions(ctx, networkID, address, afterTxid)
+}
+
+func (c *Cache) UnspentOutputs(ctx context.Context, networkID, address, afterTxid string) (*sochain.UnspentOutputs, error) {
+	return c.next.UnspentOutputs(ctx, networkID, address, afterTxid)
+}
+
+func (c *Cache) BroadcastTransaction(ctx context.Context, networkID, txHex string) (*sochain.BroadcastTx, error) {
+	return c.next.BroadcastTransaction(ctx, networkID, txHex)
+}
+
+// addBlock caches b by height & hash, orphaned blocks expire like unconfirmed ones
+func (c *Cache) addBlock(networkID string, b *sochain.Block) {
+	confirmations := b.Data.Confirmations
+	if b.Data.IsOrphan {
+		confirmations = 0
+	}
+	expires := c.expires(networkID, confirmations)
+	cached := b.Copy()
+
+	networkID = strings.ToLower(networkID)
+	c.add(fmt.Sprintf("block/%s/height/%d", networkID, b.Data.BlockNo), cached, expires)
+	c.add(fmt.Sprintf("block/%s/hash/%s", networkID, strings.ToLower(b.Data.Blockhash)), cached, expires)
+}
+
+func (c *Cache) addTransaction(networkID, txHash string, tx *sochain.Transaction) {
+	c.add(fmt.Sprintf("tx/%s/%s", strings.ToLower(networkID), strings.ToLower(txHash)), tx.Copy(), c.expires(networkID, tx.Data.Confirmations))
+}
+
+// expires returns the expiry of an entry with confirmations, zero once it reached the finality threshold of networkID.
+// Final entries keep the confirmations they were cached with
+func (c *Cache) expires(networkID string, confirmations int) time.Time {
+	n, err := c.networks.Lookup(networkID)
+	if err == nil && n.Confirmations > 0 && confirmations >= n.Confirmations {
+		return time.Time{}
+	}
+
+	return c.now().Add(c.ttl)
+}
+
+func (c *Cache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if ok {
+		e := el.Value.(*entry)
+		if e.expires.IsZero() || c.now().Before(e.expires) {
+			c.lru.MoveToFront(el)
+			atomic.AddUint64(&c.hits, 1)
+			return e.value, true
+		}
+
+		c.lru.Remove(el)
+		delete(c.entries, key)
+	}
+
+	atomic.AddUint64(&c.misses, 1)
+	return nil, false
+}
+
+func (c *Cache) add(key string, value interface{}, expires time.Time) {
+	if c.maxEntries <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		el.Value = &entry{key: key, value: value, expires: expires}
+		c.lru.MoveToFront(el)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(&entry{key: key, value: value, expires: expires})
+	for c.lru.Len() > c.maxEntries {
+		el := c.lru.Back()
+		c.lru.Remove(el)
+		delete(c.entries, el.Value.(*entry).key)
+		atomic.AddUint64(&c.evictions, 1)
+	}
+}
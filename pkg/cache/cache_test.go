@@ -0,0 +1,228 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sochain-client/pkg/sochain"
+	mock_client "sochain-client/pkg/sochain/mock"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	blockHash = "000000000003ba27aa200b1cecaad478d2b00432346c3f1f3986da1afd33e506"
+	txHash    = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+)
+
+func newTestCache(t *testing.T, opts ...Option) (*Cache, *mock_client.MockConnector, *time.Time) {
+	m := mock_client.NewMockConnector(gomock.NewController(t))
+	now := time.Unix(1600000000, 0)
+
+	c := NewCache(m, opts...)
+	c.now = func() time.Time { return now }
+	return c, m, &now
+}
+
+func testBlock(confirmations int) *sochain.Block {
+	return &sochain.Block{Data: sochain.BlockData{Blockhash: blockHash, BlockNo: 100000, Confirmations: confirmations}}
+}
+
+func Test_Block_Final(t *testing.T) {
+	c, m, now := newTestCache(t)
+
+	m.EXPECT().BlockHeight(gomock.Any(), "btc", 100000).Return(testBlock(6), nil).Times(1)
+
+	for i := 0; i < 3; i++ {
+		got, err := c.BlockHeight(context.Background(), "btc", 100000)
+		assert.Nil(t, err)
+		assert.Equal(t, testBlock(6), got)
+	}
+
+	// cached by hash as well & never expires
+	*now = now.Add(24 * time.Hour)
+	got, err := c.BlockHash(context.Background(), "BTC", blockHash)
+	assert.Nil(t, err)
+	assert.Equal(t, testBlock(6), got)
+
+	assert.Equal(t, Stats{Hits: 3, Misses: 1, Entries: 2}, c.Stats())
+}
+
+func Test_Block_Unconfirmed(t *testing.T) {
+	c, m, now := newTestCache(t, WithTTL(time.Minute))
+
+	m.EXPECT().BlockHash(gomock.Any(), "btc", blockHash).Return(testBlock(5), nil)
+	m.EXPECT().BlockHash(gomock.Any(), "btc", blockHash).Return(testBlock(6), nil)
+
+	got, _ := c.BlockHash(context.Background(), "btc", blockHash)
+	assert.Equal(t, 5, got.Data.Confirmations)
+
+	*now = now.Add(59 * time.Second)
+	got, _ = c.BlockHash(context.Background(), "btc", blockHash)
+	assert.Equal(t, 5, got.Data.Confirmations)
+
+	*now = now.Add(time.Second)
+	got, _ = c.BlockHash(context.Background(), "btc", blockHash)
+	assert.Equal(t, 6, got.Data.Confirmations)
+}
+
+func Test_Block_Orphan(t *testing.T) {
+	c, m, now := newTestCache(t)
+
+	orphan := testBlock(10)
+	orphan.Data.IsOrphan = true
+	m.EXPECT().BlockHash(gomock.Any(), "btc", blockHash).Return(orphan, nil).Times(2)
+
+	c.BlockHash(context.Background(), "btc", blockHash)
+	*now = now.Add(time.Hour)
+	c.BlockHash(context.Background(), "btc", blockHash)
+}
+
+func Test_Transaction_FinalityPerNetwork(t *testing.T) {
+	c, m, now := newTestCache(t)
+
+	tx := &sochain.Transaction{Data: sochain.TransactionData{Txid: txHash, Confirmations: 10}}
+	m.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(tx, nil).Times(1)
+	// 10 confirmations are short of the finality of ltc
+	m.EXPECT().Transaction(gomock.Any(), "ltc", txHash).Return(tx, nil).Times(2)
+
+	for _, networkID := range []string{"btc", "ltc"} {
+		c.Transaction(context.Background(), networkID, txHash)
+		*now = now.Add(time.Hour)
+		got, err := c.Transaction(context.Background(), networkID, txHash)
+		assert.Nil(t, err)
+		assert.Equal(t, tx, got)
+	}
+}
+
+func Test_Copies(t *testing.T) {
+	c, m, _ := newTestCache(t)
+
+	newTx := func() *sochain.Transaction {
+		return &sochain.Transaction{Data: sochain.TransactionData{
+			Txid:          txHash,
+			Confirmations: 10,
+			Inputs:        sochain.Inputs{{ReceivedFrom: &sochain.ReceivedFrom{Txid: txHash}}},
+			Outputs:       sochain.Outputs{{Value: 5000000000}},
+		}}
+	}
+	newBlock := func() *sochain.Block {
+		b := testBlock(6)
+		b.Data.Txs = []string{txHash}
+		return b
+	}
+	tx, b := newTx(), newBlock()
+	m.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(tx, nil)
+	m.EXPECT().BlockHash(gomock.Any(), "btc", blockHash).Return(b, nil)
+
+	// neither the fetched nor the served values share memory with the cached entries
+	got, _ := c.Transaction(context.Background(), "btc", txHash)
+	tx.Data.Outputs[0].Value = 0
+	got.Data.Inputs[0].ReceivedFrom.Txid = ""
+	got.Data.Outputs[0].Spent = &sochain.SpentBy{Txid: txHash}
+
+	gotBlock, _ := c.BlockHash(context.Background(), "btc", blockHash)
+	b.Data.Txs[0] = ""
+	gotBlock.Data.Txs[0] = ""
+
+	got, err := c.Transaction(context.Background(), "btc", txHash)
+	assert.Nil(t, err)
+	assert.Equal(t, newTx(), got)
+
+	gotBlock, err = c.BlockHeight(context.Background(), "btc", 100000)
+	assert.Nil(t, err)
+	assert.Equal(t, newBlock(), gotBlock)
+}
+
+func Test_Transactions(t *testing.T) {
+	c, m, _ := newTestCache(t)
+
+	other := "0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098"
+	final := &sochain.Transaction{Data: sochain.TransactionData{Txid: txHash, Confirmations: 10}}
+	notFound := sochain.NewClientErr(errors.New("some"), http.StatusNotFound)
+
+	m.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(final, nil)
+	m.EXPECT().Transactions(gomock.Any(), "btc", []string{other}).Return([]sochain.TxResult{{Hash: other, Err: notFound}}, nil).Times(2)
+
+	c.Transaction(context.Background(), "btc", txHash)
+	for i := 0; i < 2; i++ {
+		got, err := c.Transactions(context.Background(), "btc", []string{other, txHash})
+		assert.Nil(t, err)
+		assert.Equal(t, []sochain.TxResult{{Hash: other, Err: notFound}, {Hash: txHash, Tx: final}}, got)
+	}
+
+	// batches served from the cache only don't reach next
+	got, err := c.Transactions(context.Background(), "btc", []string{txHash})
+	assert.Nil(t, err)
+	assert.Equal(t, []sochain.TxResult{{Hash: txHash, Tx: final}}, got)
+}
+
+func Test_Errors_NotCached(t *testing.T) {
+	c, m, _ := newTestCache(t)
+
+	notFound := sochain.NewClientErr(errors.New("some"), http.StatusNotFound)
+	m.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(nil, notFound).Times(2)
+
+	for i := 0; i < 2; i++ {
+		_, err := c.Transaction(context.Background(), "btc", txHash)
+		assert.Equal(t, notFound, err)
+	}
+}
+
+func Test_NetworkInfo_TTL(t *testing.T) {
+	c, m, now := newTestCache(t, WithNetworkInfoTTL(5*time.Second))
+
+	m.EXPECT().NetworkInfo(gomock.Any(), "btc").Return(&sochain.NetworkInfo{Data: sochain.NetworkData{Blocks: 1}}, nil)
+	m.EXPECT().NetworkInfo(gomock.Any(), "btc").Return(&sochain.NetworkInfo{Data: sochain.NetworkData{Blocks: 2}}, nil)
+
+	got, _ := c.NetworkInfo(context.Background(), "btc")
+	assert.Equal(t, 1, got.Data.Blocks)
+
+	*now = now.Add(4 * time.Second)
+	got, _ = c.NetworkInfo(context.Background(), "btc")
+	assert.Equal(t, 1, got.Data.Blocks)
+
+	*now = now.Add(time.Second)
+	got, _ = c.NetworkInfo(context.Background(), "btc")
+	assert.Equal(t, 2, got.Data.Blocks)
+}
+
+func Test_LRU(t *testing.T) {
+	c, m, _ := newTestCache(t, WithMaxEntries(2))
+
+	hashes := []string{
+		"0000000000000000000000000000000000000000000000000000000000000001",
+		"0000000000000000000000000000000000000000000000000000000000000002",
+		"0000000000000000000000000000000000000000000000000000000000000003",
+	}
+	for _, h := range hashes {
+		m.EXPECT().Transaction(gomock.Any(), "btc", h).Return(&sochain.Transaction{Data: sochain.TransactionData{Txid: h, Confirmations: 100}}, nil)
+	}
+
+	c.Transaction(context.Background(), "btc", hashes[0])
+	c.Transaction(context.Background(), "btc", hashes[1])
+	// hashes[0] becomes the most recently used, hashes[1] is evicted
+	c.Transaction(context.Background(), "btc", hashes[0])
+	c.Transaction(context.Background(), "btc", hashes[2])
+
+	m.EXPECT().Transaction(gomock.Any(), "btc", hashes[1]).Return(&sochain.Transaction{}, nil)
+	c.Transaction(context.Background(), "btc", hashes[0])
+	c.Transaction(context.Background(), "btc", hashes[2])
+	c.Transaction(context.Background(), "btc", hashes[1])
+
+	assert.Equal(t, Stats{Hits: 3, Misses: 4, Evictions: 2, Entries: 2}, c.Stats())
+}
+
+func Test_PassThrough(t *testing.T) {
+	c, m, _ := newTestCache(t)
+
+	m.EXPECT().AddressBalance(gomock.Any(), "btc", "address").Return(&sochain.AddressBalance{}, nil).Times(2)
+
+	for i := 0; i < 2; i++ {
+		_, err := c.AddressBalance(context.Background(), "btc", "address")
+		assert.Nil(t, err)
+	}
+}
@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"sochain-client/pkg/network"
+	"time"
+)
+
+// Option configures a Cache created by NewCache
+type Option func(*Cache)
+
+// WithMaxEntries bounds the number of cached entries, the least recently used entry is evicted first. Default 10000
+func WithMaxEntries(n int) Option {
+	return func(c *Cache) {
+		c.maxEntries = n
+	}
+}
+
+// WithTTL sets the lifetime of blocks & transactions short of finality, default 30s
+func WithTTL(d time.Duration) Option {
+	return func(c *Cache) {
+		c.ttl = d
+	}
+}
+
+// WithNetworkInfoTTL sets the lifetime of network infos, default 10s
+func WithNetworkInfoTTL(d time.Duration) Option {
+	return func(c *Cache) {
+		c.infoTTL = d
+	}
+}
+
+// WithNetworks takes the finality thresholds from the Confirmations of r, defaults to network.DefaultRegistry
+func WithNetworks(r *network.Registry) Option {
+	return func(c *Cache) {
+		c.networks = r
+	}
+}
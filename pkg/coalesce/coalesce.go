@@ -0,0 +1,265 @@
+// Package coalesce decorates a sochain.Connector so that concurrent identical reads share one upstream call. A shared
+// call runs detached from the context of any single caller & is cancelled once every caller waiting for it is gone
+package coalesce
+
+import (
+	"context"
+	"fmt"
+	"sochain-client/pkg/sochain"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats are the counters of a Coalescer
+type Stats struct {
+	// upstream calls started
+	Calls uint64
+	// callers served by a call started by another caller
+	Coalesced uint64
+	// shared calls cancelled because all their callers were gone
+	Cancelled uint64
+	// calls currently in flight
+	InFlight int
+}
+
+// detached keeps the values of a context but none of its cancellation
+type detached struct {
+	context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detached) Done() <-chan struct{}       { return nil }
+func (detached) Err() error                  { return nil }
+
+type call struct {
+	done    chan struct{}
+	val     interface{}
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+var _ sochain.Connector = (*Coalescer)(nil)
+
+type Coalescer struct {
+	next        sochain.Connector
+	concurrency int
+
+	mu    sync.Mutex
+	calls map[string]*call
+
+	started, coalesced, cancelled uint64
+}
+
+// NewCoalescer coalesces the reads of next
+func NewCoalescer(next sochain.Connector, opts ...Option) *Coalescer {
+	c := &Coalescer{
+		next:        next,
+		concurrency: sochain.DefaultConcurrency,
+		calls:       map[string]*call{},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Stats reports the counters of c
+func (c *Coalescer) Stats() Stats {
+	c.mu.Lock()
+	inFlight := len(c.calls)
+	c.mu.Unlock()
+
+	return Stats{
+		Calls:     atomic.LoadUint64(&c.started),
+		Coalesced: atomic.LoadUint64(&c.coalesced),
+		Cancelled: atomic.LoadUint64(&c.cancelled),
+		InFlight:  inFlight,
+	}
+}
+
+func (c *Coalescer) NetworkInfo(ctx context.Context, networkID string) (*sochain.NetworkInfo, error) {
+	v, err := c.do(ctx, fmt.Sprintf("info/%s", strings.ToLower(networkID)), func(ctx context.Context) (interface{}, error) {
+		return c.next.NetworkInfo(ctx, networkID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	info := *v.(*sochain.NetworkInfo)
+	return &info, nil
+}
+
+func (c *Coalescer) BlockHeight(ctx context.Context, networkID string, height int) (*sochain.Block, error) {
+	v, err := c.do(ctx, fmt.Sprintf("block/%s/height/%d", strings.ToLower(networkID), height), func(ctx context.Context) (interface{}, error) {
+		return c.next.BlockHeight(ctx, networkID, height)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b := *v.(*sochain.Block)
+	return &b, nil
+}
+
+func (c *Coalescer) BlockHash(ctx context.Context, networkID, blockHash string) (*sochain.Block, error) {
+	v, err := c.do(ctx, fmt.Sprintf("block/%s/hash/%s", strings.ToLower(networkID), strings.ToLower(blockHash)), func(ctx context.Context) (interface{}, error) {
+		return c.next.BlockHash(ctx, networkID, blockHash)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b := *v.(*sochain.Block)
+	return &b, nil
+}
+
+func (c *Coalescer) Transaction(ctx context.Context, networkID, txHash string) (*sochain.Transaction, error) {
+	v, err := c.do(ctx, fmt.Sprintf("tx/%s/%s", strings.ToLower(networkID), strings.ToLower(txHash)), func(ctx context.Context) (interface{}, error) {
+		return c.next.Transaction(ctx, networkID, txHash)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tx := *v.(*sochain.Transaction)
+	return &tx, nil
+}
+
+// Transactions coalesces every hash on its own, batches overlapping another one share the common lookups
+func (c *Coalescer) Transactions(ctx context.Context, networkID string, hashes []string) ([]sochain.TxResult, error) {
+	return sochain.FetchTransactions(ctx, hashes, c.concurrency, func(ctx context.Context, hash string) (*sochain.Transaction, error) {
+		return c.Transaction(ctx, networkID, hash)
+	}), nil
+}
+
+func (c *Coalescer) AddressBalance(ctx context.Context, networkID, address string) (*sochain.AddressBalance, error) {
+	v, err := c.do(ctx, fmt.Sprintf("balance/%s/%s", strings.ToLower(networkID), address), func(ctx context.Context) (interface{}, error) {
+		return c.next.AddressBalance(ctx, networkID, address)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b := *v.(*sochain.AddressBalance)
+	return &b, nil
+}
+
+func (c *Coalescer) ReceivedTransactions(ctx context.Context, networkID, address, afterTxid string) (*sochain.ReceivedTxs, error) {
+	v, err := c.do(ctx, fmt.Sprintf("received/%s/%s/%s", strings.ToLower(networkID), address, strings.ToLower(afterTxid)), func(ctx context.Context) (interface{}, error) {
+		return c.next.ReceivedTransactions(ctx, networkID, address, afterTxid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	txs := *v.(*sochain.ReceivedTxs)
+	return &txs, nil
+}
+
+func (c *Coalescer) OutputSpender(ctx context.Context, networkID, txHash string, outputNo int) (*sochain.SpentBy, error) {
+	v, err := c.do(ctx, fmt.Sprintf("spender/%s/%s/%d", strings.ToLower(networkID), strings.ToLower(txHash), outputNo), func(ctx context.Context) (interface{}, error) {
+		return c.next.OutputSpender(ctx, networkID, txHash, outputNo)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// nil while unspent
+	spent, _ := v.(*sochain.SpentBy)
+	if spent == nil {
+		return nil, nil
+	}
+
+	s := *spent
+	return &s, nil
+}
+
+func (c *Coalescer) SpentTransactions(ctx context.Context, networkID, address, afterTxid string) (*sochain.SpentTxs, error) {
+	v, err := c.do(ctx, fmt.Sprintf("spent/%s/%s/%s", strings.ToLower(networkID), address, strings.ToLower(afterTxid)), func(ctx context.Context) (interface{}, error) {
+		return c.next.SpentTransactions(ctx, networkID, address, afterTxid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	txs := *v.(*sochain.SpentTxs)
+	return &txs, nil
+}
+
+func (c *Coalescer) UnspentOutputs(ctx context.Context, networkID, address, afterTxid string) (*sochain.UnspentOutputs, error) {
+	v, err := c.do(ctx, fmt.Sprintf("unspent/%s/%s/%s", strings.ToLower(networkID), address, strings.ToLower(afterTxid)), func(ctx context.Context) (interface{}, error) {
+		return c.next.UnspentOutputs(ctx, networkID, address, afterTxid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := *v.(*sochain.UnspentOutputs)
+	return &outputs, nil
+}
+
+// Broadcasts aren't reads & are never coalesced
+func (c *Coalescer) BroadcastTransaction(ctx context.Context, networkID, txHex string) (*sochain.BroadcastTx, error) {
+	return c.next.BroadcastTransaction(ctx, networkID, txHex)
+}
+
+// do joins the call of key in flight or starts it with fn. The caller stops waiting once ctx is done, the call is
+// cancelled once no caller waits for it anymore
+func (c *Coalescer) do(ctx context.Context, key string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	cl, ok := c.calls[key]
+	if ok {
+		cl.waiters++
+		atomic.AddUint64(&c.coalesced, 1)
+	} else {
+		callCtx, cancel := context.WithCancel(detached{ctx})
+		cl = &call{done: make(chan struct{}), waiters: 1, cancel: cancel}
+		c.calls[key] = cl
+		atomic.AddUint64(&c.started, 1)
+
+		go func() {
+			defer cancel()
+
+			v, err := fn(callCtx)
+
+			c.mu.Lock()
+			cl.val, cl.err = v, err
+			c.forget(key, cl)
+			c.mu.Unlock()
+			close(cl.done)
+		}()
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.val, cl.err
+	case <-ctx.Done():
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cl.waiters--
+	if cl.waiters == 0 && c.forget(key, cl) {
+		cl.cancel()
+		atomic.AddUint64(&c.cancelled, 1)
+	}
+	return nil, ctx.Err()
+}
+
+// forget removes cl from the calls in flight, later callers of key start a new call. Reports whether cl was in flight,
+// c.mu must be held
+func (c *Coalescer) forget(key string, cl *call) bool {
+	if c.calls[key] != cl {
+		return false
+	}
+
+	delete(c.calls, key)
+	return true
+}
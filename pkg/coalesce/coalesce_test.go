@@ -0,0 +1,211 @@
+package coalesce
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sochain-client/pkg/sochain"
+	mock_client "sochain-client/pkg/sochain/mock"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	blockHash = "000000000003ba27aa200b1cecaad478d2b00432346c3f1f3986da1afd33e506"
+	txHash    = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+)
+
+func newTestCoalescer(t *testing.T) (*Coalescer, *mock_client.MockConnector) {
+	m := mock_client.NewMockConnector(gomock.NewController(t))
+	return NewCoalescer(m), m
+}
+
+func testBlock() *sochain.Block {
+	return &sochain.Block{Data: sochain.BlockData{Blockhash: blockHash, BlockNo: 100000, Confirmations: 6}}
+}
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func Test_Coalesced(t *testing.T) {
+	c, m := newTestCoalescer(t)
+
+	release := make(chan struct{})
+	m.EXPECT().BlockHash(gomock.Any(), "btc", blockHash).DoAndReturn(
+		func(ctx context.Context, networkID, blockHash string) (*sochain.Block, error) {
+			<-release
+			return testBlock(), nil
+		}).Times(1)
+
+	const callers = 5
+	got := make([]*sochain.Block, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			var err error
+			got[i], err = c.BlockHash(context.Background(), "btc", blockHash)
+			assert.Nil(t, err)
+		}(i)
+	}
+
+	waitFor(t, func() bool { return c.Stats().Coalesced == callers-1 })
+	close(release)
+	wg.Wait()
+
+	for i := range got {
+		assert.Equal(t, testBlock(), got[i])
+		if i > 0 {
+			// every caller owns its copy
+			assert.NotSame(t, got[0], got[i])
+		}
+	}
+	assert.Equal(t, Stats{Calls: 1, Coalesced: callers - 1}, c.Stats())
+}
+
+func Test_NotRemembered(t *testing.T) {
+	c, m := newTestCoalescer(t)
+
+	m.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(nil, sochain.NewClientErr(errors.New("not found"), http.StatusNotFound))
+	m.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(&sochain.Transaction{Data: sochain.TransactionData{Txid: txHash}}, nil)
+
+	// a finished call, failed or not, isn't shared with later callers
+	_, err := c.Transaction(context.Background(), "btc", txHash)
+	assert.True(t, errors.Is(err, sochain.ErrNotFound))
+
+	tx, err := c.Transaction(context.Background(), "btc", txHash)
+	assert.Nil(t, err)
+	assert.Equal(t, txHash, tx.Data.Txid)
+
+	assert.Equal(t, Stats{Calls: 2}, c.Stats())
+}
+
+func Test_CallerCancelled(t *testing.T) {
+	c, m := newTestCoalescer(t)
+
+	release := make(chan struct{})
+	m.EXPECT().BlockHeight(gomock.Any(), "btc", 100000).DoAndReturn(
+		func(ctx context.Context, networkID string, height int) (*sochain.Block, error) {
+			select {
+			case <-release:
+				return testBlock(), nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}).Times(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := c.BlockHeight(ctx, "btc", 100000)
+		first <- err
+	}()
+	waitFor(t, func() bool { return c.Stats().InFlight == 1 })
+
+	second := make(chan *sochain.Block, 1)
+	go func() {
+		b, err := c.BlockHeight(context.Background(), "btc", 100000)
+		assert.Nil(t, err)
+		second <- b
+	}()
+	waitFor(t, func() bool { return c.Stats().Coalesced == 1 })
+
+	// the caller which started the call leaves, the call carries on for the other one
+	cancel()
+	assert.Equal(t, context.Canceled, <-first)
+
+	close(release)
+	assert.Equal(t, testBlock(), <-second)
+	assert.Equal(t, Stats{Calls: 1, Coalesced: 1}, c.Stats())
+}
+
+func Test_AllCancelled(t *testing.T) {
+	c, m := newTestCoalescer(t)
+
+	upstreamDone := make(chan struct{})
+	m.EXPECT().NetworkInfo(gomock.Any(), "btc").DoAndReturn(
+		func(ctx context.Context, networkID string) (*sochain.NetworkInfo, error) {
+			<-ctx.Done()
+			close(upstreamDone)
+			return nil, ctx.Err()
+		})
+	m.EXPECT().NetworkInfo(gomock.Any(), "btc").Return(&sochain.NetworkInfo{}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := c.NetworkInfo(ctx, "btc")
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// the call is cancelled once its last caller is gone
+	<-upstreamDone
+	assert.Equal(t, Stats{Calls: 1, Cancelled: 1}, c.Stats())
+
+	// & later callers start a new one
+	_, err = c.NetworkInfo(context.Background(), "btc")
+	assert.Nil(t, err)
+	assert.Equal(t, Stats{Calls: 2, Cancelled: 1}, c.Stats())
+}
+
+func Test_Transactions(t *testing.T) {
+	c, m := newTestCoalescer(t)
+
+	other := "0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098"
+	release := make(chan struct{})
+	m.EXPECT().Transaction(gomock.Any(), "btc", txHash).DoAndReturn(
+		func(ctx context.Context, networkID, txHash string) (*sochain.Transaction, error) {
+			<-release
+			return &sochain.Transaction{Data: sochain.TransactionData{Txid: txHash}}, nil
+		}).Times(1)
+	m.EXPECT().Transaction(gomock.Any(), "btc", other).Return(&sochain.Transaction{Data: sochain.TransactionData{Txid: other}}, nil)
+
+	var wg sync.WaitGroup
+	for _, hashes := range [][]string{{txHash}, {other, txHash}} {
+		wg.Add(1)
+		go func(hashes []string) {
+			defer wg.Done()
+
+			results, err := c.Transactions(context.Background(), "btc", hashes)
+			assert.Nil(t, err)
+			for i, r := range results {
+				assert.Nil(t, r.Err)
+				assert.Equal(t, hashes[i], r.Tx.Data.Txid)
+			}
+		}(hashes)
+	}
+
+	// batches overlapping share the lookups of their common hashes
+	waitFor(t, func() bool { return c.Stats().Coalesced == 1 })
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, Stats{Calls: 2, Coalesced: 1}, c.Stats())
+}
+
+func Test_Broadcast_NotCoalesced(t *testing.T) {
+	c, m := newTestCoalescer(t)
+
+	m.EXPECT().BroadcastTransaction(gomock.Any(), "btc", "00").Return(&sochain.BroadcastTx{}, nil).Times(2)
+
+	for i := 0; i < 2; i++ {
+		_, err := c.BroadcastTransaction(context.Background(), "btc", "00")
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, Stats{}, c.Stats())
+}
@@ -0,0 +1,11 @@
+package coalesce
+
+// Option configures a Coalescer created by NewCoalescer
+type Option func(*Coalescer)
+
+// WithConcurrency limits the parallel lookups of Transactions to n, default sochain.DefaultConcurrency
+func WithConcurrency(n int) Option {
+	return func(c *Coalescer) {
+		c.concurrency = n
+	}
+}
@@ -1,71 +1,117 @@
 package controller
 
 import (
-	"log"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"net/http"
+	"sochain-client/pkg/network"
+	"sochain-client/pkg/rawtx"
 	"sochain-client/pkg/sochain"
 	"sochain-client/pkg/util"
-	"regexp"
+	"sochain-client/pkg/watcher"
 	"strconv"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 type Controller struct {
-	logger *zap.Logger
-	client sochain.Connector
+	logger   *zap.Logger
+	client   sochain.Connector
+	networks *network.Registry
+	watcher  *watcher.Watcher
+	// reports the change of transactions of blocks in flight
+	txFetches func(delta int)
 }
 
-func NewController(l *zap.Logger, client sochain.Connector) *Controller {
-	return &Controller{
-		logger: l,
-		client: client,
+// Option configures a Controller created by NewController
+type Option func(*Controller)
+
+// WithWatcher streams the new blocks found by w, by default the chain tips of the client are polled every 30s
+func WithWatcher(w *watcher.Watcher) Option {
+	return func(c *Controller) {
+		c.watcher = w
 	}
 }
 
+// WithTxFetchObserver reports the transactions of blocks HandleGetBlock starts fetching by a positive delta & those it
+// finished by a negative one, e.g. to track the fetches in flight
+func WithTxFetchObserver(f func(delta int)) Option {
+	return func(c *Controller) {
+		c.txFetches = f
+	}
+}
+
+func NewController(l *zap.Logger, client sochain.Connector, networks *network.Registry, opts ...Option) *Controller {
+	c := &Controller{
+		logger:    l,
+		client:    client,
+		networks:  networks,
+		txFetches: func(int) {},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.watcher == nil {
+		c.watcher = watcher.NewWatcher(client, watcher.WithLogger(l))
+	}
+
+	return c
+}
+
 const maxTxPerBlock = 10
 
-// BTC, LTC & DOGE use SHA-256 for blocks & tx hashes
-var HashSHA256Regex *regexp.Regexp
+// hard limit of blocks returned by a range
+const maxBlockRange = 100
 
-func init() {
-	var err error
-	HashSHA256Regex, err = regexp.Compile("^[A-Fa-f0-9]{64}$")
-	if err != nil {
-		log.Fatal(err)
+// Bounds the request context by d, cancelling all upstream calls of the request once exceeded
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		reqCtx, cancel := context.WithTimeout(ctx.Request.Context(), d)
+		defer cancel()
+
+		ctx.Request = ctx.Request.WithContext(reqCtx)
+		ctx.Next()
 	}
 }
 
 // Rturns latest block of network including transactions. Specific block can be choosen optional by providing blockcounter or blockhash
 func (c *Controller) HandleGetBlock(ctx *gin.Context) {
-	networkID, err := util.GetParamNetwork(ctx, "id")
-	if err != nil {
-		c.logger.Info("invalid path param network 'id'", zap.Error(err))
-		ctx.JSON(http.StatusBadRequest, "path param: network 'id' can only be 'btc', 'eth' or 'ltc'")
+	n, ok := c.networkParam(ctx)
+	if !ok {
 		return
 	}
+	networkID := n.ID
 
-	networkID = strings.ToLower(networkID)
-
-	var wg sync.WaitGroup
-	results := make(chan TxChanResp)
-
+	var block *sochain.Block
 	height := ctx.Query("height")
 	blockHash := ctx.Query("blockhash")
-	if height == "" && blockHash == "" {
-		info, err := c.client.NetworkInfo(networkID)
+	switch {
+	case height == "" && blockHash == "":
+		info, err := c.client.NetworkInfo(ctx.Request.Context(), networkID)
 		if err != nil {
+			if c.handleContextErr(ctx, err) {
+				return
+			}
+
 			c.logger.Info("missing query params 'height' or 'blockhash'", zap.Error(err))
 			ctx.JSON(http.StatusBadRequest, "one of query params 'height' or 'blockhash' is mandatory")
 			return
 		}
 
-		block, err := c.client.BlockHeight(strings.ToLower(networkID), info.Data.Blocks)
+		block, err = c.client.BlockHeight(ctx.Request.Context(), networkID, info.Data.Blocks)
 		if err != nil {
-			if cErr, ok := err.(sochain.ClientError); ok {
+			if c.handleContextErr(ctx, err) {
+				return
+			}
+
+			var cErr *sochain.ClientError
+			if errors.As(err, &cErr) {
 				switch cErr.Code() {
 				case http.StatusNotFound:
 					c.logger.Info("unable to fetch transaction", zap.Error(cErr))
@@ -83,43 +129,7 @@ func (c *Controller) HandleGetBlock(ctx *gin.Context) {
 			return
 		}
 
-		f := func(networkID, txHash string) (*sochain.Transaction, error) {
-			return c.client.Transaction(networkID, txHash)
-		}
-
-		if len(block.Data.Txs) >= maxTxPerBlock {
-			FetchTxAsync(f, &wg, results, networkID, block.Data.Txs[:maxTxPerBlock])
-		} else {
-			FetchTxAsync(f, &wg, results, networkID, block.Data.Txs)
-		}
-
-		go func() {
-			wg.Wait()
-			close(results)
-		}()
-
-		transactions := make(sochain.Transactions, 0)
-		for v := range results {
-			if v.err != nil {
-				if cErr, ok := err.(sochain.ClientError); ok {
-					c.logger.Warn("unable to fetch transaction", zap.String("txhash", v.hash), zap.Int("statuscode", cErr.Code()), zap.Error(cErr))
-					continue
-				}
-
-				c.logger.Info("unable to fetch transaction", zap.String("txhash", v.hash), zap.Error(v.err))
-			} else {
-				transactions = append(transactions, *v.tx)
-			}
-		}
-
-		bResp := block.Response()
-		bResp.Transactions = transactions.Response()
-
-		ctx.JSON(http.StatusOK, bResp)
-		return
-	}
-
-	if height != "" {
+	case height != "":
 		heightInt, err := strconv.Atoi(height)
 		if err != nil || heightInt <= 0 {
 			c.logger.Info("invalid query param 'height'", zap.Error(err))
@@ -127,9 +137,14 @@ func (c *Controller) HandleGetBlock(ctx *gin.Context) {
 			return
 		}
 
-		block, err := c.client.BlockHeight(strings.ToLower(networkID), heightInt)
+		block, err = c.client.BlockHeight(ctx.Request.Context(), networkID, heightInt)
 		if err != nil {
-			if cErr, ok := err.(sochain.ClientError); ok {
+			if c.handleContextErr(ctx, err) {
+				return
+			}
+
+			var cErr *sochain.ClientError
+			if errors.As(err, &cErr) {
 				switch cErr.Code() {
 				case http.StatusNotFound:
 					c.logger.Info("unable to fetch transaction", zap.Error(cErr))
@@ -147,169 +162,635 @@ func (c *Controller) HandleGetBlock(ctx *gin.Context) {
 			return
 		}
 
-		f := func(networkID, txHash string) (*sochain.Transaction, error) {
-			return c.client.Transaction(networkID, txHash)
+	default:
+		if !n.ValidHash(blockHash) {
+			c.logger.Info("provided blockhash is not a valid SHA-256 hash", zap.String("blockhash", blockHash))
+			ctx.JSON(http.StatusBadRequest, "provided blockhash is not a valid SHA-256 hash")
+			return
 		}
 
-		if len(block.Data.Txs) >= maxTxPerBlock {
-			FetchTxAsync(f, &wg, results, networkID, block.Data.Txs[:maxTxPerBlock])
-		} else {
-			FetchTxAsync(f, &wg, results, networkID, block.Data.Txs)
+		var err error
+		block, err = c.client.BlockHash(ctx.Request.Context(), networkID, blockHash)
+		if err != nil {
+			c.handleBlockHashErr(ctx, err)
+			return
 		}
+	}
 
-		go func() {
-			wg.Wait()
-			close(results)
-		}()
+	txs := block.Data.Txs
+	if len(txs) > maxTxPerBlock {
+		txs = txs[:maxTxPerBlock]
+	}
+
+	transactions := make(sochain.Transactions, 0)
+	if len(txs) > 0 {
+		c.txFetches(len(txs))
+		results, err := c.client.Transactions(ctx.Request.Context(), networkID, txs)
+		c.txFetches(-len(txs))
+		if err != nil {
+			if c.handleContextErr(ctx, err) {
+				return
+			}
 
-		transactions := make(sochain.Transactions, 0)
-		for v := range results {
-			if v.err != nil {
-				if cErr, ok := err.(sochain.ClientError); ok {
-					c.logger.Warn("unable to fetch transaction", zap.String("txhash", v.hash), zap.Int("statuscode", cErr.Code()), zap.Error(cErr))
+			c.logger.Info("unable to fetch transactions of block", zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, "unable to fetch transactions of block")
+			return
+		}
+
+		// transactions failing to fetch are left out
+		for _, r := range results {
+			if r.Err != nil {
+				var cErr *sochain.ClientError
+				if errors.As(r.Err, &cErr) {
+					c.logger.Warn("unable to fetch transaction", zap.String("txhash", r.Hash), zap.Int("statuscode", cErr.Code()), zap.Error(cErr))
 					continue
 				}
 
-				c.logger.Info("unable to fetch transaction", zap.String("txhash", v.hash), zap.Error(v.err))
-			} else {
-				transactions = append(transactions, *v.tx)
+				c.logger.Info("unable to fetch transaction", zap.String("txhash", r.Hash), zap.Error(r.Err))
+				continue
 			}
+			transactions = append(transactions, *r.Tx)
 		}
+	}
 
-		bResp := block.Response()
-		bResp.Transactions = transactions.Response()
+	if c.handleContextErr(ctx, ctx.Request.Context().Err()) {
+		return
+	}
+
+	bResp := block.Response()
+	bResp.Transactions = transactions.Response()
+
+	ctx.JSON(http.StatusOK, bResp)
+}
 
-		ctx.JSON(http.StatusOK, bResp)
+// Returns the blocks between query params 'from' & 'to', both inclusive & each a height or blockhash. Blocks are listed
+// backward if 'from' is above 'to'
+func (c *Controller) HandleGetBlocks(ctx *gin.Context) {
+	n, ok := c.networkParam(ctx)
+	if !ok {
 		return
 	}
 
-	if blockHash != "" {
-		if !HashSHA256Regex.MatchString(blockHash) {
-			c.logger.Info("provided blockhash is not a valid SHA-256 hash", zap.String("blockhash", blockHash))
-			ctx.JSON(http.StatusBadRequest, "provided blockhash is not a valid SHA-256 hash")
+	var refs [2]sochain.BlockRef
+	for i, param := range []string{"from", "to"} {
+		ref, err := sochain.ParseBlockRef(ctx.Query(param))
+		if err == nil && ref.Hash != "" && !n.ValidHash(ref.Hash) {
+			err = errors.New("not a valid SHA-256 hash")
+		}
+		if err != nil {
+			c.logger.Info("invalid query param '"+param+"'", zap.Error(err))
+			ctx.JSON(http.StatusBadRequest, "query param '"+param+"' has to be a height or blockhash")
 			return
 		}
+		refs[i] = ref
+	}
 
-		block, err := c.client.BlockHash(networkID, blockHash)
-		if err != nil {
-			if cErr, ok := err.(sochain.ClientError); ok {
-				switch cErr.Code() {
-				case http.StatusNotFound:
-					c.logger.Info("unable to fetch transaction", zap.Error(cErr))
-					ctx.JSON(http.StatusNotFound, "unable to find block for given hash")
-					return
-				case http.StatusBadRequest:
-					c.logger.Info("unable to fetch transaction", zap.Error(cErr))
-					ctx.JSON(http.StatusBadRequest, "bad request block for given hash")
-					return
-				}
-			}
+	it := sochain.NewBlockIterator(ctx.Request.Context(), c.client, n.ID, refs[0], refs[1], sochain.WithMaxBlocks(maxBlockRange))
+	defer it.Close()
 
-			c.logger.Info("unable to fetch block by hash", zap.Error(err))
-			ctx.JSON(http.StatusInternalServerError, "unable to fetch block by hash")
+	blocks := make([]sochain.BlockSummaryResponse, 0)
+	for it.Next() {
+		blocks = append(blocks, it.Block().SummaryResponse())
+	}
+
+	if err := it.Err(); err != nil {
+		if c.handleContextErr(ctx, err) {
 			return
 		}
 
-		f := func(networkID, txHash string) (*sochain.Transaction, error) {
-			return c.client.Transaction(networkID, txHash)
+		if errors.Is(err, sochain.ErrRangeTooLarge) {
+			c.logger.Info("requested range too large", zap.Error(err))
+			ctx.JSON(http.StatusBadRequest, fmt.Sprintf("range has to be at most %d blocks", maxBlockRange))
+			return
+		}
+		if errors.Is(err, sochain.ErrChainMismatch) {
+			c.logger.Warn("upstream returned unlinked blocks", zap.Error(err))
+			ctx.JSON(http.StatusBadGateway, "upstream returned blocks which don't link")
+			return
+		}
+		if errors.Is(err, sochain.ErrNoConsensus) {
+			c.logger.Warn("upstream providers didn't agree on blocks", zap.Error(err))
+			ctx.JSON(http.StatusBadGateway, "upstream providers didn't agree on the blocks")
+			return
 		}
 
-		if len(block.Data.Txs) >= maxTxPerBlock {
-			FetchTxAsync(f, &wg, results, networkID, block.Data.Txs[:maxTxPerBlock])
-		} else {
-			FetchTxAsync(f, &wg, results, networkID, block.Data.Txs)
+		var cErr *sochain.ClientError
+		if errors.As(err, &cErr) {
+			switch cErr.Code() {
+			case http.StatusNotFound:
+				c.logger.Info("unable to fetch blocks", zap.Error(cErr))
+				ctx.JSON(http.StatusNotFound, "unable to find blocks of given range")
+				return
+			case http.StatusBadRequest:
+				c.logger.Info("unable to fetch blocks", zap.Error(cErr))
+				ctx.JSON(http.StatusBadRequest, "bad request blocks of given range")
+				return
+			}
 		}
 
-		go func() {
-			wg.Wait()
-			close(results)
-		}()
+		c.logger.Info("unable to fetch blocks", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, "unable to fetch blocks of given range")
+		return
+	}
 
-		transactions := make(sochain.Transactions, 0)
-		for v := range results {
-			if v.err != nil {
-				if cErr, ok := err.(sochain.ClientError); ok {
-					c.logger.Warn("unable to fetch transaction", zap.String("txhash", v.hash), zap.Int("statuscode", cErr.Code()), zap.Error(cErr))
-					continue
-				}
+	ctx.JSON(http.StatusOK, blocks)
+}
+
+// Streams the new blocks of a network as Server-Sent Events 'block' until the client disconnects. Subscribers too slow
+// to keep up receive a final event 'error' & are disconnected
+func (c *Controller) HandleStreamBlocks(ctx *gin.Context) {
+	n, ok := c.networkParam(ctx)
+	if !ok {
+		return
+	}
+
+	sub, err := c.watcher.Subscribe(n.ID)
+	if err != nil {
+		c.logger.Info("unable to subscribe to blocks", zap.Error(err))
+		ctx.JSON(http.StatusServiceUnavailable, "block stream unavailable")
+		return
+	}
+	defer sub.Close()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
 
-				c.logger.Warn("unable to fetch transaction", zap.String("txhash", v.hash), zap.Error(v.err))
-			} else {
-				transactions = append(transactions, *v.tx)
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case b, ok := <-sub.Blocks():
+			if !ok {
+				if err := sub.Err(); err != nil {
+					c.logger.Info("block stream ended", zap.String("network", n.ID), zap.Error(err))
+					ctx.SSEvent("error", err.Error())
+					ctx.Writer.Flush()
+				}
+				return
 			}
+
+			// the stream carries the block only, its transactions are fetched by GET /network/:id?blockhash=
+			bResp := b.Response()
+			bResp.Transactions = make(sochain.TransactionResponses, 0)
+			ctx.SSEvent("block", bResp)
+			ctx.Writer.Flush()
 		}
+	}
+}
 
-		bResp := block.Response()
-		bResp.Transactions = transactions.Response()
+func (c *Controller) handleBlockHashErr(ctx *gin.Context, err error) {
+	if c.handleContextErr(ctx, err) {
+		return
+	}
 
-		ctx.JSON(http.StatusOK, bResp)
+	if errors.Is(err, sochain.ErrMerkleMismatch) {
+		c.logger.Warn("upstream returned an inconsistent block", zap.Error(err))
+		ctx.JSON(http.StatusBadGateway, "upstream returned an inconsistent block")
 		return
 	}
+	if errors.Is(err, sochain.ErrNoConsensus) {
+		c.logger.Warn("upstream providers didn't agree on block", zap.Error(err))
+		ctx.JSON(http.StatusBadGateway, "upstream providers didn't agree on the block")
+		return
+	}
+
+	var cErr *sochain.ClientError
+	if errors.As(err, &cErr) {
+		switch cErr.Code() {
+		case http.StatusNotFound:
+			c.logger.Info("unable to fetch block", zap.Error(cErr))
+			ctx.JSON(http.StatusNotFound, "unable to find block for given hash")
+			return
+		case http.StatusBadRequest:
+			c.logger.Info("unable to fetch block", zap.Error(cErr))
+			ctx.JSON(http.StatusBadRequest, "bad request block for given hash")
+			return
+		}
+	}
+
+	c.logger.Info("unable to fetch block by hash", zap.Error(err))
+	ctx.JSON(http.StatusInternalServerError, "unable to fetch block by hash")
 }
 
-type TxChanResp struct {
-	tx   *sochain.Transaction
-	hash string
-	err  error
+// Responds to upstream calls aborted by the request context. Returns false if err is not caused by the context
+func (c *Controller) handleContextErr(ctx *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, context.Canceled):
+		c.logger.Info("request cancelled by client", zap.Error(err))
+		ctx.AbortWithStatus(sochain.StatusClientClosedRequest)
+		return true
+	case errors.Is(err, context.DeadlineExceeded):
+		c.logger.Info("request deadline exceeded", zap.Error(err))
+		ctx.JSON(http.StatusGatewayTimeout, "upstream request timed out")
+		return true
+	}
+
+	return false
 }
 
-func FetchTxAsync(f func(networkID, blockHash string) (*sochain.Transaction, error),
-	wg *sync.WaitGroup, ch chan TxChanResp, networkID string, txHashList []string) {
+// Returns details of specific transaction
+func (c *Controller) HandleGetTransaction(ctx *gin.Context) {
+	n, txHash, ok := c.txParams(ctx)
+	if !ok {
+		return
+	}
 
-	for _, v := range txHashList {
-		wg.Add(1)
+	decode := false
+	if v := ctx.Query("decode"); v != "" {
+		var err error
+		if decode, err = strconv.ParseBool(v); err != nil {
+			c.logger.Info("invalid query param 'decode'", zap.String("decode", v))
+			ctx.JSON(http.StatusBadRequest, "query param: 'decode' has to be a boolean")
+			return
+		}
+	}
 
-		go func(networkID, hash string) {
-			defer wg.Done()
-			tx, err := f(networkID, hash)
-			resp := TxChanResp{
-				tx:  tx,
-				err: err,
-			}
-			ch <- resp
-		}(networkID, v)
+	tx, err := c.client.Transaction(ctx.Request.Context(), n.ID, txHash)
+	if err != nil {
+		c.handleTransactionErr(ctx, err)
+		return
+	}
+
+	response := tx.Response()
+	if decode {
+		if response.Decoded, err = tx.DecodedResponse(); err != nil {
+			c.logger.Info("unable to decode transaction", zap.String("txhash", txHash), zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, "unable to decode transaction")
+			return
+		}
 	}
+
+	ctx.JSON(http.StatusOK, response)
 }
 
-// Returns details of specific transaction
-func (c *Controller) HandleGetTransaction(ctx *gin.Context) {
-	networkID, err := util.GetParamNetwork(ctx, "id")
+// Returns the transaction which spent output 'n' of a transaction
+func (c *Controller) HandleGetOutputSpender(ctx *gin.Context) {
+	n, txHash, ok := c.txParams(ctx)
+	if !ok {
+		return
+	}
+
+	outputNo, err := strconv.Atoi(ctx.Param("n"))
+	if err != nil || outputNo < 0 {
+		c.logger.Info("path param: 'n' is not a valid output number", zap.String("n", ctx.Param("n")))
+		ctx.JSON(http.StatusBadRequest, "path param: 'n' is not a valid output number")
+		return
+	}
+
+	spent, err := c.client.OutputSpender(ctx.Request.Context(), n.ID, txHash, outputNo)
 	if err != nil {
-		c.logger.Info("invalid path param network'id'", zap.Error(err))
-		ctx.JSON(http.StatusBadRequest, "path param: Network 'id' can only be 'btc', 'eth' or 'ltc'")
+		c.handleSpenderErr(ctx, err)
 		return
 	}
 
+	ref, ok := spent.Ref(n.ID)
+	if !ok {
+		ctx.JSON(http.StatusNotFound, "output is unspent")
+		return
+	}
+
+	spender, err := c.client.Transaction(ctx.Request.Context(), ref.Network, ref.Txid)
+	if err != nil {
+		c.handleTransactionErr(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sochain.SpenderResponse{TransactionResponse: spender.Response(), InputNo: ref.InputNo})
+}
+
+// handleSpenderErr answers 501 for providers that don't know spending inputs, reporting their outputs unspent would
+// be wrong
+func (c *Controller) handleSpenderErr(ctx *gin.Context, err error) {
+	if c.handleContextErr(ctx, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, sochain.ErrUnsupported):
+		c.logger.Info("unable to look up output spender", zap.Error(err))
+		ctx.JSON(http.StatusNotImplemented, "output spends are not supported by the upstream")
+	case errors.Is(err, sochain.ErrNotFound):
+		c.logger.Info("unable to look up output spender", zap.Error(err))
+		ctx.JSON(http.StatusNotFound, "unable to find output for given hash & number")
+	default:
+		c.handleTransactionErr(ctx, err)
+	}
+}
+
+// Returns the merkle proof that a confirmed transaction is included in its block
+func (c *Controller) HandleGetTransactionProof(ctx *gin.Context) {
+	n, txHash, ok := c.txParams(ctx)
+	if !ok {
+		return
+	}
+
+	tx, err := c.client.Transaction(ctx.Request.Context(), n.ID, txHash)
+	if err != nil {
+		c.handleTransactionErr(ctx, err)
+		return
+	}
+	if tx.Data.Blockhash == "" {
+		ctx.JSON(http.StatusNotFound, "transaction is unconfirmed")
+		return
+	}
+
+	block, err := c.client.BlockHash(ctx.Request.Context(), n.ID, tx.Data.Blockhash)
+	if err != nil {
+		c.handleBlockHashErr(ctx, err)
+		return
+	}
+
+	proof, err := block.Data.Proof(tx.Data.Txid)
+	if err != nil {
+		if errors.Is(err, sochain.ErrUnsupported) {
+			c.logger.Info("unable to prove transaction", zap.String("txhash", txHash), zap.Error(err))
+			ctx.JSON(http.StatusNotImplemented, "provider doesn't report block headers")
+			return
+		}
+
+		c.logger.Warn("unable to prove transaction", zap.String("txhash", txHash), zap.Error(err))
+		ctx.JSON(http.StatusBadGateway, "upstream returned an inconsistent block")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, proof)
+}
+
+// Validates path params 'id' & 'txhash', responds with 400 Bad Request if invalid
+func (c *Controller) txParams(ctx *gin.Context) (network.Network, string, bool) {
+	n, ok := c.networkParam(ctx)
+	if !ok {
+		return network.Network{}, "", false
+	}
+
 	txHash := ctx.Param("txhash")
 	if txHash == "" {
 		c.logger.Info("path param 'txhash' missing")
 		ctx.JSON(http.StatusBadRequest, "path param: missing 'txhash'")
-		return
+		return network.Network{}, "", false
 	}
 
-	if !HashSHA256Regex.MatchString(txHash) {
+	if !n.ValidHash(txHash) {
 		c.logger.Info("path param: 'txhash' is not a valid SHA-256 hash")
 		ctx.JSON(http.StatusBadRequest, "path param: 'txhash' is not a valid SHA-256 hash")
+		return network.Network{}, "", false
+	}
+
+	return n, txHash, true
+}
+
+func (c *Controller) handleTransactionErr(ctx *gin.Context, err error) {
+	if c.handleContextErr(ctx, err) {
+		return
+	}
+
+	if errors.Is(err, sochain.ErrTxMismatch) {
+		c.logger.Warn("upstream returned a tampered transaction", zap.Error(err))
+		ctx.JSON(http.StatusBadGateway, "upstream returned an invalid transaction")
+		return
+	}
+	if errors.Is(err, sochain.ErrNoConsensus) {
+		c.logger.Warn("upstream providers didn't agree on transaction", zap.Error(err))
+		ctx.JSON(http.StatusBadGateway, "upstream providers didn't agree on the transaction")
+		return
+	}
+
+	var cErr *sochain.ClientError
+	if errors.As(err, &cErr) {
+		switch cErr.Code() {
+		case http.StatusNotFound:
+			c.logger.Info("unable to fetch transaction", zap.Error(cErr))
+			ctx.JSON(http.StatusNotFound, "unable to find tx for given hash")
+			return
+		case http.StatusBadRequest:
+			c.logger.Info("unable to fetch transaction", zap.Error(cErr))
+			ctx.JSON(http.StatusBadRequest, "bad request tx for given hash")
+			return
+		}
+	}
+
+	c.logger.Info("unable to fetch transaction", zap.Error(err))
+	ctx.JSON(http.StatusInternalServerError, "unable to fetch transaction")
+}
+
+// Returns the confirmed & unconfirmed balance of an address
+func (c *Controller) HandleGetAddressBalance(ctx *gin.Context) {
+	n, address, ok := c.addressParams(ctx)
+	if !ok {
 		return
 	}
 
-	tx, err := c.client.Transaction(networkID, txHash)
+	balance, err := c.client.AddressBalance(ctx.Request.Context(), n.ID, address)
 	if err != nil {
-		if cErr, ok := err.(sochain.ClientError); ok {
-			switch cErr.Code() {
-			case http.StatusNotFound:
-				c.logger.Info("unable to fetch transaction", zap.Error(cErr))
-				ctx.JSON(http.StatusNotFound, "unable to find tx for given hash")
-				return
-			case http.StatusBadRequest:
-				c.logger.Info("unable to fetch transaction", zap.Error(cErr))
-				ctx.JSON(http.StatusBadRequest, "bad request tx for given hash")
-				return
+		c.handleAddressErr(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, balance.Response())
+}
+
+// Returns transactions which sent funds to an address. Pages are continued by query param 'after'
+func (c *Controller) HandleGetReceivedTransactions(ctx *gin.Context) {
+	c.handleAddressTxs(ctx, func(reqCtx context.Context, networkID, address, after string) (sochain.AddressTxsResponse, error) {
+		txs, err := c.client.ReceivedTransactions(reqCtx, networkID, address, after)
+		if err != nil {
+			return sochain.AddressTxsResponse{}, err
+		}
+		return txs.Response(), nil
+	})
+}
+
+// Returns transactions which spent funds of an address. Pages are continued by query param 'after'
+func (c *Controller) HandleGetSpentTransactions(ctx *gin.Context) {
+	c.handleAddressTxs(ctx, func(reqCtx context.Context, networkID, address, after string) (sochain.AddressTxsResponse, error) {
+		txs, err := c.client.SpentTransactions(reqCtx, networkID, address, after)
+		if err != nil {
+			return sochain.AddressTxsResponse{}, err
+		}
+		return txs.Response(), nil
+	})
+}
+
+// Returns the unspent outputs of an address. Pages are continued by query param 'after'
+func (c *Controller) HandleGetUnspentOutputs(ctx *gin.Context) {
+	c.handleAddressTxs(ctx, func(reqCtx context.Context, networkID, address, after string) (sochain.AddressTxsResponse, error) {
+		outputs, err := c.client.UnspentOutputs(reqCtx, networkID, address, after)
+		if err != nil {
+			return sochain.AddressTxsResponse{}, err
+		}
+		return outputs.Response(), nil
+	})
+}
+
+func (c *Controller) handleAddressTxs(ctx *gin.Context, f func(ctx context.Context, networkID, address, after string) (sochain.AddressTxsResponse, error)) {
+	n, address, ok := c.addressParams(ctx)
+	if !ok {
+		return
+	}
+
+	after := ctx.Query("after")
+	if after != "" && !n.ValidHash(after) {
+		c.logger.Info("query param: 'after' is not a valid SHA-256 hash")
+		ctx.JSON(http.StatusBadRequest, "query param: 'after' is not a valid SHA-256 hash")
+		return
+	}
+
+	resp, err := f(ctx.Request.Context(), n.ID, address, after)
+	if err != nil {
+		c.handleAddressErr(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// Validates path param 'id' against the network registry, responds with 400 Bad Request if invalid
+func (c *Controller) networkParam(ctx *gin.Context) (network.Network, bool) {
+	n, err := util.GetParamNetwork(ctx, c.networks, "id")
+	if err != nil {
+		c.logger.Info("invalid path param network 'id'", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, fmt.Sprintf("path param: network 'id' can only be one of '%s'", strings.Join(c.networks.IDs(), "', '")))
+		return network.Network{}, false
+	}
+
+	return n, true
+}
+
+// Validates path params 'id' & 'address', responds with 400 Bad Request if invalid
+func (c *Controller) addressParams(ctx *gin.Context) (network.Network, string, bool) {
+	n, ok := c.networkParam(ctx)
+	if !ok {
+		return network.Network{}, "", false
+	}
+
+	address := ctx.Param("address")
+	if address == "" {
+		c.logger.Info("path param 'address' missing")
+		ctx.JSON(http.StatusBadRequest, "path param: missing 'address'")
+		return network.Network{}, "", false
+	}
+
+	if !n.ValidAddress(address) {
+		c.logger.Info("path param: 'address' is not a valid address of the network", zap.String("address", address), zap.String("network", n.ID))
+		ctx.JSON(http.StatusBadRequest, "path param: 'address' is not a valid address of network '"+n.ID+"'")
+		return network.Network{}, "", false
+	}
+
+	return n, n.NormalAddress(address), true
+}
+
+func (c *Controller) handleAddressErr(ctx *gin.Context, err error) {
+	if c.handleContextErr(ctx, err) {
+		return
+	}
+
+	var cErr *sochain.ClientError
+	if errors.As(err, &cErr) {
+		switch cErr.Code() {
+		case http.StatusNotFound:
+			c.logger.Info("unable to fetch address", zap.Error(cErr))
+			ctx.JSON(http.StatusNotFound, "unable to find address")
+			return
+		case http.StatusBadRequest:
+			c.logger.Info("unable to fetch address", zap.Error(cErr))
+			ctx.JSON(http.StatusBadRequest, "bad request for given address")
+			return
+		case http.StatusNotImplemented:
+			c.logger.Info("unable to fetch address", zap.Error(cErr))
+			ctx.JSON(http.StatusNotImplemented, "address lookups are not supported by the upstream")
+			return
+		}
+	}
+
+	c.logger.Info("unable to fetch address", zap.Error(err))
+	ctx.JSON(http.StatusInternalServerError, "unable to fetch address")
+}
+
+// Hex encoded transactions of up to 4 MB, 2 hex chars per transaction byte
+const maxTxHexSize = 8000000
+
+type BroadcastRequest struct {
+	TxHex string `json:"tx_hex"`
+}
+
+// Responses of transactions rejected by the upstream node, per reject reason
+var rejectStatus = map[sochain.RejectReason]int{
+	sochain.RejectInvalid:         http.StatusBadRequest,
+	sochain.RejectInsufficientFee: http.StatusPaymentRequired,
+	sochain.RejectAlreadyKnown:    http.StatusConflict,
+	sochain.RejectMissingInputs:   http.StatusPreconditionFailed,
+	sochain.RejectNonStandard:     http.StatusUnprocessableEntity,
+}
+
+// errBodyTooLarge reports whether err is returned by a http.MaxBytesReader past its limit
+func errBodyTooLarge(err error) bool {
+	var mErr *http.MaxBytesError
+	return errors.As(err, &mErr)
+}
+
+// Broadcasts a signed raw transaction, returns its txid
+func (c *Controller) HandlePostTransaction(ctx *gin.Context) {
+	n, ok := c.networkParam(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxTxHexSize+1024)
+
+	var body BroadcastRequest
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		if errBodyTooLarge(err) {
+			c.logger.Info("broadcast request body too large", zap.Error(err))
+			ctx.JSON(http.StatusRequestEntityTooLarge, fmt.Sprintf("body: exceeds %d bytes", maxTxHexSize+1024))
+			return
+		}
+
+		c.logger.Info("invalid broadcast request body", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, "body: expected JSON object with field 'tx_hex'")
+		return
+	}
+
+	if body.TxHex == "" {
+		c.logger.Info("body field 'tx_hex' missing")
+		ctx.JSON(http.StatusBadRequest, "body: missing 'tx_hex'")
+		return
+	}
+
+	if _, err := hex.DecodeString(body.TxHex); err != nil {
+		c.logger.Info("body field 'tx_hex' is not valid hex", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, "body: 'tx_hex' is not valid hex")
+		return
+	}
+
+	if _, err := rawtx.DecodeString(body.TxHex); err != nil {
+		c.logger.Info("body field 'tx_hex' does not decode as transaction", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, "body: 'tx_hex' does not decode as transaction")
+		return
+	}
+
+	tx, err := c.client.BroadcastTransaction(ctx.Request.Context(), n.ID, body.TxHex)
+	if err != nil {
+		if c.handleContextErr(ctx, err) {
+			return
+		}
+
+		var bErr *sochain.BroadcastError
+		if errors.As(err, &bErr) {
+			status, ok := rejectStatus[bErr.Reason]
+			if !ok {
+				status = http.StatusBadRequest
 			}
+
+			c.logger.Info("transaction rejected", zap.String("reason", string(bErr.Reason)), zap.Error(bErr))
+			ctx.JSON(status, "transaction rejected: "+string(bErr.Reason)+": "+bErr.Message)
+			return
 		}
 
-		c.logger.Info("unable to fetch transaction", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, "unable to fetch transaction")
+		c.logger.Info("unable to broadcast transaction", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, "unable to broadcast transaction")
 		return
 	}
 
@@ -1,14 +1,27 @@
 package controller
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sochain-client/pkg/cache"
+	"sochain-client/pkg/failover"
+	"sochain-client/pkg/merkle"
+	"sochain-client/pkg/network"
+	"sochain-client/pkg/rpcnode"
 	"sochain-client/pkg/sochain"
 	mock_client "sochain-client/pkg/sochain/mock"
-	"reflect"
+	"sochain-client/pkg/watcher"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -29,6 +42,7 @@ func TestHandleGetBlock(t *testing.T) {
 		gotPathNetworkID       string
 		gotHeightQuery         string
 		gotBlockhashQuery      string
+		gotCtx                 context.Context
 		mock                   func(m *mock_client.MockConnector)
 		wantError              bool
 		wantCode               int
@@ -54,7 +68,7 @@ func TestHandleGetBlock(t *testing.T) {
 			gotPathNetworkID:       "btc",
 			wantCode:               http.StatusBadRequest,
 			mock: func(m *mock_client.MockConnector) {
-				m.EXPECT().NetworkInfo("btc").Return(nil, errors.New("some"))
+				m.EXPECT().NetworkInfo(gomock.Any(), "btc").Return(nil, errors.New("some"))
 			},
 		},
 		{
@@ -69,9 +83,9 @@ func TestHandleGetBlock(t *testing.T) {
 						Blocks: 1,
 					},
 				}
-				m.EXPECT().NetworkInfo("btc").Return(&info, nil)
+				m.EXPECT().NetworkInfo(gomock.Any(), "btc").Return(&info, nil)
 
-				m.EXPECT().BlockHeight("btc", 1).Return(nil, errors.New("some"))
+				m.EXPECT().BlockHeight(gomock.Any(), "btc", 1).Return(nil, errors.New("some"))
 			},
 		},
 		{
@@ -86,9 +100,9 @@ func TestHandleGetBlock(t *testing.T) {
 						Blocks: 1,
 					},
 				}
-				m.EXPECT().NetworkInfo("btc").Return(&info, nil)
+				m.EXPECT().NetworkInfo(gomock.Any(), "btc").Return(&info, nil)
 
-				m.EXPECT().BlockHeight("btc", 1).Return(nil, *sochain.NewClientErr(errors.New("some"), http.StatusBadRequest))
+				m.EXPECT().BlockHeight(gomock.Any(), "btc", 1).Return(nil, sochain.NewClientErr(errors.New("some"), http.StatusBadRequest))
 			},
 		},
 		{
@@ -103,9 +117,9 @@ func TestHandleGetBlock(t *testing.T) {
 						Blocks: 1,
 					},
 				}
-				m.EXPECT().NetworkInfo("btc").Return(&info, nil)
+				m.EXPECT().NetworkInfo(gomock.Any(), "btc").Return(&info, nil)
 
-				m.EXPECT().BlockHeight("btc", 1).Return(nil, *sochain.NewClientErr(errors.New("some"), http.StatusNotFound))
+				m.EXPECT().BlockHeight(gomock.Any(), "btc", 1).Return(nil, sochain.NewClientErr(errors.New("some"), http.StatusNotFound))
 			},
 		},
 		{
@@ -120,7 +134,7 @@ func TestHandleGetBlock(t *testing.T) {
 						Blocks: 1,
 					},
 				}
-				m.EXPECT().NetworkInfo("btc").Return(&info, nil)
+				m.EXPECT().NetworkInfo(gomock.Any(), "btc").Return(&info, nil)
 
 				block := sochain.Block{
 					Data: sochain.BlockData{
@@ -134,22 +148,22 @@ func TestHandleGetBlock(t *testing.T) {
 						Txs: []string{"1", "2"},
 					},
 				}
-				m.EXPECT().BlockHeight("btc", 1).Return(&block, nil)
+				m.EXPECT().BlockHeight(gomock.Any(), "btc", 1).Return(&block, nil)
 
-				m.EXPECT().Transaction("btc", "1").Return(&sochain.Transaction{
+				m.EXPECT().Transaction(gomock.Any(), "btc", "1").Return(&sochain.Transaction{
 					Data: sochain.TransactionData{
 						Txid:      "1",
-						Fee:       "1",
-						SentValue: "1",
+						Fee:       100000000,
+						SentValue: 100000000,
 						Time:      unixTime,
 					},
 				}, nil)
 
-				m.EXPECT().Transaction("btc", "2").Return(&sochain.Transaction{
+				m.EXPECT().Transaction(gomock.Any(), "btc", "2").Return(&sochain.Transaction{
 					Data: sochain.TransactionData{
 						Txid:      "2",
-						Fee:       "1",
-						SentValue: "1",
+						Fee:       100000000,
+						SentValue: 100000000,
 						Time:      unixTime,
 					},
 				}, nil)
@@ -161,18 +175,19 @@ func TestHandleGetBlock(t *testing.T) {
 				Size:         1,
 				PreviousHash: "1",
 				NextHash:     "1",
+				MerkleStatus: sochain.MerkleUnverified,
 				Transactions: sochain.TransactionResponses{
 					{
 						TxID:      "1",
-						Fee:       "1",
+						Fee:       100000000,
 						Timestamp: timeRFC3339,
-						Value:     "1",
+						Value:     100000000,
 					},
 					{
 						TxID:      "2",
-						Fee:       "1",
+						Fee:       100000000,
 						Timestamp: timeRFC3339,
-						Value:     "1",
+						Value:     100000000,
 					},
 				},
 			},
@@ -189,7 +204,7 @@ func TestHandleGetBlock(t *testing.T) {
 						Blocks: 1,
 					},
 				}
-				m.EXPECT().NetworkInfo("btc").Return(&info, nil)
+				m.EXPECT().NetworkInfo(gomock.Any(), "btc").Return(&info, nil)
 
 				block := sochain.Block{
 					Data: sochain.BlockData{
@@ -203,28 +218,28 @@ func TestHandleGetBlock(t *testing.T) {
 						Txs: []string{"1", "2", "3", "4"},
 					},
 				}
-				m.EXPECT().BlockHeight("btc", 1).Return(&block, nil)
+				m.EXPECT().BlockHeight(gomock.Any(), "btc", 1).Return(&block, nil)
 
-				m.EXPECT().Transaction("btc", "1").Return(&sochain.Transaction{
+				m.EXPECT().Transaction(gomock.Any(), "btc", "1").Return(&sochain.Transaction{
 					Data: sochain.TransactionData{
 						Txid:      "1",
-						Fee:       "1",
-						SentValue: "1",
+						Fee:       100000000,
+						SentValue: 100000000,
 						Time:      unixTime,
 					},
 				}, nil)
 
-				m.EXPECT().Transaction("btc", "2").Return(&sochain.Transaction{
+				m.EXPECT().Transaction(gomock.Any(), "btc", "2").Return(&sochain.Transaction{
 					Data: sochain.TransactionData{
 						Txid:      "2",
-						Fee:       "1",
-						SentValue: "1",
+						Fee:       100000000,
+						SentValue: 100000000,
 						Time:      unixTime,
 					},
 				}, nil)
 
-				m.EXPECT().Transaction("btc", "3").Return(nil, errors.New("some"))
-				m.EXPECT().Transaction("btc", "4").Return(nil, sochain.NewClientErr(errors.New("some"), http.StatusInternalServerError))
+				m.EXPECT().Transaction(gomock.Any(), "btc", "3").Return(nil, errors.New("some"))
+				m.EXPECT().Transaction(gomock.Any(), "btc", "4").Return(nil, sochain.NewClientErr(errors.New("some"), http.StatusInternalServerError))
 			},
 
 			want: &sochain.BlockResponse{
@@ -233,18 +248,19 @@ func TestHandleGetBlock(t *testing.T) {
 				Size:         1,
 				PreviousHash: "1",
 				NextHash:     "1",
+				MerkleStatus: sochain.MerkleUnverified,
 				Transactions: sochain.TransactionResponses{
 					{
 						TxID:      "1",
-						Fee:       "1",
+						Fee:       100000000,
 						Timestamp: timeRFC3339,
-						Value:     "1",
+						Value:     100000000,
 					},
 					{
 						TxID:      "2",
-						Fee:       "1",
+						Fee:       100000000,
 						Timestamp: timeRFC3339,
-						Value:     "1",
+						Value:     100000000,
 					},
 				},
 			},
@@ -282,7 +298,7 @@ func TestHandleGetBlock(t *testing.T) {
 			gotHeightQuery:         "1",
 			wantCode:               http.StatusBadRequest,
 			mock: func(m *mock_client.MockConnector) {
-				m.EXPECT().BlockHeight("btc", 1).Return(nil, *sochain.NewClientErr(errors.New("some"), http.StatusBadRequest))
+				m.EXPECT().BlockHeight(gomock.Any(), "btc", 1).Return(nil, sochain.NewClientErr(errors.New("some"), http.StatusBadRequest))
 			},
 		},
 		{
@@ -293,7 +309,7 @@ func TestHandleGetBlock(t *testing.T) {
 			gotHeightQuery:         "1",
 			wantCode:               http.StatusNotFound,
 			mock: func(m *mock_client.MockConnector) {
-				m.EXPECT().BlockHeight("btc", 1).Return(nil, *sochain.NewClientErr(errors.New("some"), http.StatusNotFound))
+				m.EXPECT().BlockHeight(gomock.Any(), "btc", 1).Return(nil, sochain.NewClientErr(errors.New("some"), http.StatusNotFound))
 			},
 		},
 		{
@@ -304,7 +320,7 @@ func TestHandleGetBlock(t *testing.T) {
 			gotHeightQuery:         "1",
 			wantCode:               http.StatusInternalServerError,
 			mock: func(m *mock_client.MockConnector) {
-				m.EXPECT().BlockHeight("btc", 1).Return(nil, errors.New("some"))
+				m.EXPECT().BlockHeight(gomock.Any(), "btc", 1).Return(nil, errors.New("some"))
 			},
 		},
 		{
@@ -327,22 +343,22 @@ func TestHandleGetBlock(t *testing.T) {
 						Txs: []string{"1", "2"},
 					},
 				}
-				m.EXPECT().BlockHeight("btc", 1).Return(&block, nil)
+				m.EXPECT().BlockHeight(gomock.Any(), "btc", 1).Return(&block, nil)
 
-				m.EXPECT().Transaction("btc", "1").Return(&sochain.Transaction{
+				m.EXPECT().Transaction(gomock.Any(), "btc", "1").Return(&sochain.Transaction{
 					Data: sochain.TransactionData{
 						Txid:      "1",
-						Fee:       "1",
-						SentValue: "1",
+						Fee:       100000000,
+						SentValue: 100000000,
 						Time:      unixTime,
 					},
 				}, nil)
 
-				m.EXPECT().Transaction("btc", "2").Return(&sochain.Transaction{
+				m.EXPECT().Transaction(gomock.Any(), "btc", "2").Return(&sochain.Transaction{
 					Data: sochain.TransactionData{
 						Txid:      "2",
-						Fee:       "1",
-						SentValue: "1",
+						Fee:       100000000,
+						SentValue: 100000000,
 						Time:      unixTime,
 					},
 				}, nil)
@@ -354,18 +370,19 @@ func TestHandleGetBlock(t *testing.T) {
 				Size:         1,
 				PreviousHash: "1",
 				NextHash:     "1",
+				MerkleStatus: sochain.MerkleUnverified,
 				Transactions: sochain.TransactionResponses{
 					{
 						TxID:      "1",
-						Fee:       "1",
+						Fee:       100000000,
 						Timestamp: timeRFC3339,
-						Value:     "1",
+						Value:     100000000,
 					},
 					{
 						TxID:      "2",
-						Fee:       "1",
+						Fee:       100000000,
 						Timestamp: timeRFC3339,
-						Value:     "1",
+						Value:     100000000,
 					},
 				},
 			},
@@ -390,28 +407,28 @@ func TestHandleGetBlock(t *testing.T) {
 						Txs: []string{"1", "2", "3", "4"},
 					},
 				}
-				m.EXPECT().BlockHeight("btc", 1).Return(&block, nil)
+				m.EXPECT().BlockHeight(gomock.Any(), "btc", 1).Return(&block, nil)
 
-				m.EXPECT().Transaction("btc", "1").Return(&sochain.Transaction{
+				m.EXPECT().Transaction(gomock.Any(), "btc", "1").Return(&sochain.Transaction{
 					Data: sochain.TransactionData{
 						Txid:      "1",
-						Fee:       "1",
-						SentValue: "1",
+						Fee:       100000000,
+						SentValue: 100000000,
 						Time:      unixTime,
 					},
 				}, nil)
 
-				m.EXPECT().Transaction("btc", "2").Return(&sochain.Transaction{
+				m.EXPECT().Transaction(gomock.Any(), "btc", "2").Return(&sochain.Transaction{
 					Data: sochain.TransactionData{
 						Txid:      "2",
-						Fee:       "1",
-						SentValue: "1",
+						Fee:       100000000,
+						SentValue: 100000000,
 						Time:      unixTime,
 					},
 				}, nil)
 
-				m.EXPECT().Transaction("btc", "3").Return(nil, errors.New("some"))
-				m.EXPECT().Transaction("btc", "4").Return(nil, sochain.NewClientErr(errors.New("some"), http.StatusInternalServerError))
+				m.EXPECT().Transaction(gomock.Any(), "btc", "3").Return(nil, errors.New("some"))
+				m.EXPECT().Transaction(gomock.Any(), "btc", "4").Return(nil, sochain.NewClientErr(errors.New("some"), http.StatusInternalServerError))
 			},
 
 			want: &sochain.BlockResponse{
@@ -420,18 +437,19 @@ func TestHandleGetBlock(t *testing.T) {
 				Size:         1,
 				PreviousHash: "1",
 				NextHash:     "1",
+				MerkleStatus: sochain.MerkleUnverified,
 				Transactions: sochain.TransactionResponses{
 					{
 						TxID:      "1",
-						Fee:       "1",
+						Fee:       100000000,
 						Timestamp: timeRFC3339,
-						Value:     "1",
+						Value:     100000000,
 					},
 					{
 						TxID:      "2",
-						Fee:       "1",
+						Fee:       100000000,
 						Timestamp: timeRFC3339,
-						Value:     "1",
+						Value:     100000000,
 					},
 				},
 			},
@@ -477,7 +495,7 @@ func TestHandleGetBlock(t *testing.T) {
 			gotBlockhashQuery:      "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876",
 			wantCode:               http.StatusBadRequest,
 			mock: func(m *mock_client.MockConnector) {
-				m.EXPECT().BlockHash("btc", "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876").Return(nil, *sochain.NewClientErr(errors.New("some"), http.StatusBadRequest))
+				m.EXPECT().BlockHash(gomock.Any(), "btc", "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876").Return(nil, sochain.NewClientErr(errors.New("some"), http.StatusBadRequest))
 			},
 		},
 		{
@@ -488,7 +506,7 @@ func TestHandleGetBlock(t *testing.T) {
 			gotBlockhashQuery:      "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876",
 			wantCode:               http.StatusNotFound,
 			mock: func(m *mock_client.MockConnector) {
-				m.EXPECT().BlockHash("btc", "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876").Return(nil, *sochain.NewClientErr(errors.New("some"), http.StatusNotFound))
+				m.EXPECT().BlockHash(gomock.Any(), "btc", "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876").Return(nil, sochain.NewClientErr(errors.New("some"), http.StatusNotFound))
 			},
 		},
 		{
@@ -499,7 +517,7 @@ func TestHandleGetBlock(t *testing.T) {
 			gotBlockhashQuery:      "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876",
 			wantCode:               http.StatusInternalServerError,
 			mock: func(m *mock_client.MockConnector) {
-				m.EXPECT().BlockHash("btc", "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876").Return(nil, errors.New("some"))
+				m.EXPECT().BlockHash(gomock.Any(), "btc", "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876").Return(nil, errors.New("some"))
 			},
 		},
 		{
@@ -522,22 +540,22 @@ func TestHandleGetBlock(t *testing.T) {
 						Txs: []string{"1", "2"},
 					},
 				}
-				m.EXPECT().BlockHash("btc", "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876").Return(&block, nil)
+				m.EXPECT().BlockHash(gomock.Any(), "btc", "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876").Return(&block, nil)
 
-				m.EXPECT().Transaction("btc", "1").Return(&sochain.Transaction{
+				m.EXPECT().Transaction(gomock.Any(), "btc", "1").Return(&sochain.Transaction{
 					Data: sochain.TransactionData{
 						Txid:      "1",
-						Fee:       "1",
-						SentValue: "1",
+						Fee:       100000000,
+						SentValue: 100000000,
 						Time:      unixTime,
 					},
 				}, nil)
 
-				m.EXPECT().Transaction("btc", "2").Return(&sochain.Transaction{
+				m.EXPECT().Transaction(gomock.Any(), "btc", "2").Return(&sochain.Transaction{
 					Data: sochain.TransactionData{
 						Txid:      "2",
-						Fee:       "1",
-						SentValue: "1",
+						Fee:       100000000,
+						SentValue: 100000000,
 						Time:      unixTime,
 					},
 				}, nil)
@@ -549,18 +567,19 @@ func TestHandleGetBlock(t *testing.T) {
 				Size:         1,
 				PreviousHash: "1",
 				NextHash:     "1",
+				MerkleStatus: sochain.MerkleUnverified,
 				Transactions: sochain.TransactionResponses{
 					{
 						TxID:      "1",
-						Fee:       "1",
+						Fee:       100000000,
 						Timestamp: timeRFC3339,
-						Value:     "1",
+						Value:     100000000,
 					},
 					{
 						TxID:      "2",
-						Fee:       "1",
+						Fee:       100000000,
 						Timestamp: timeRFC3339,
-						Value:     "1",
+						Value:     100000000,
 					},
 				},
 			},
@@ -585,28 +604,28 @@ func TestHandleGetBlock(t *testing.T) {
 						Txs: []string{"1", "2", "3", "4"},
 					},
 				}
-				m.EXPECT().BlockHash("btc", "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876").Return(&block, nil)
+				m.EXPECT().BlockHash(gomock.Any(), "btc", "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876").Return(&block, nil)
 
-				m.EXPECT().Transaction("btc", "1").Return(&sochain.Transaction{
+				m.EXPECT().Transaction(gomock.Any(), "btc", "1").Return(&sochain.Transaction{
 					Data: sochain.TransactionData{
 						Txid:      "1",
-						Fee:       "1",
-						SentValue: "1",
+						Fee:       100000000,
+						SentValue: 100000000,
 						Time:      unixTime,
 					},
 				}, nil)
 
-				m.EXPECT().Transaction("btc", "2").Return(&sochain.Transaction{
+				m.EXPECT().Transaction(gomock.Any(), "btc", "2").Return(&sochain.Transaction{
 					Data: sochain.TransactionData{
 						Txid:      "2",
-						Fee:       "1",
-						SentValue: "1",
+						Fee:       100000000,
+						SentValue: 100000000,
 						Time:      unixTime,
 					},
 				}, nil)
 
-				m.EXPECT().Transaction("btc", "3").Return(nil, errors.New("some"))
-				m.EXPECT().Transaction("btc", "4").Return(nil, sochain.NewClientErr(errors.New("some"), http.StatusInternalServerError))
+				m.EXPECT().Transaction(gomock.Any(), "btc", "3").Return(nil, errors.New("some"))
+				m.EXPECT().Transaction(gomock.Any(), "btc", "4").Return(nil, sochain.NewClientErr(errors.New("some"), http.StatusInternalServerError))
 			},
 
 			want: &sochain.BlockResponse{
@@ -615,22 +634,103 @@ func TestHandleGetBlock(t *testing.T) {
 				Size:         1,
 				PreviousHash: "1",
 				NextHash:     "1",
+				MerkleStatus: sochain.MerkleUnverified,
 				Transactions: sochain.TransactionResponses{
 					{
 						TxID:      "1",
-						Fee:       "1",
+						Fee:       100000000,
 						Timestamp: timeRFC3339,
-						Value:     "1",
+						Value:     100000000,
 					},
 					{
 						TxID:      "2",
-						Fee:       "1",
+						Fee:       100000000,
 						Timestamp: timeRFC3339,
-						Value:     "1",
+						Value:     100000000,
 					},
 				},
 			},
 		},
+		//request context cancelled or timed out
+		{
+			title:                  "Error: networkinfo request cancelled",
+			wantError:              true,
+			gotPathNetworkIDExists: true,
+			gotPathNetworkID:       "btc",
+			gotCtx:                 canceledContext(),
+			wantCode:               sochain.StatusClientClosedRequest,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().NetworkInfo(gomock.Any(), "btc").Return(nil, context.Canceled)
+			},
+		},
+		{
+			title:                  "Error: blockheight request deadline exceeded",
+			wantError:              true,
+			gotPathNetworkIDExists: true,
+			gotPathNetworkID:       "btc",
+			gotHeightQuery:         "1",
+			gotCtx:                 expiredContext(),
+			wantCode:               http.StatusGatewayTimeout,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().BlockHeight(gomock.Any(), "btc", 1).Return(nil, &url.Error{Op: "Get", URL: "test", Err: context.DeadlineExceeded})
+			},
+		},
+		{
+			title:                  "Error: blockhash request cancelled",
+			wantError:              true,
+			gotPathNetworkIDExists: true,
+			gotPathNetworkID:       "btc",
+			gotBlockhashQuery:      "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876",
+			gotCtx:                 canceledContext(),
+			wantCode:               sochain.StatusClientClosedRequest,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().BlockHash(gomock.Any(), "btc", "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876").Return(nil, context.Canceled)
+			},
+		},
+		{
+			title:                  "Error: transaction fetching cancelled",
+			wantError:              true,
+			gotPathNetworkIDExists: true,
+			gotPathNetworkID:       "btc",
+			gotHeightQuery:         "1",
+			gotCtx:                 canceledContext(),
+			wantCode:               sochain.StatusClientClosedRequest,
+			mock: func(m *mock_client.MockConnector) {
+				block := sochain.Block{
+					Data: sochain.BlockData{
+						BlockNo: 1,
+						Txs:     []string{"1", "2"},
+					},
+				}
+				m.EXPECT().BlockHeight(gomock.Any(), "btc", 1).Return(&block, nil)
+
+				// fetches aren't started once the request is cancelled
+				m.EXPECT().Transaction(gomock.Any(), "btc", "1").DoAndReturn(func(ctx context.Context, networkID, txHash string) (*sochain.Transaction, error) {
+					return nil, ctx.Err()
+				}).AnyTimes()
+				m.EXPECT().Transaction(gomock.Any(), "btc", "2").DoAndReturn(func(ctx context.Context, networkID, txHash string) (*sochain.Transaction, error) {
+					return nil, ctx.Err()
+				}).AnyTimes()
+			},
+		},
+		{
+			title:                  "Error: transactions batch failure",
+			wantError:              true,
+			gotPathNetworkIDExists: true,
+			gotPathNetworkID:       "btc",
+			gotHeightQuery:         "1",
+			wantCode:               http.StatusInternalServerError,
+			mock: func(m *mock_client.MockConnector) {
+				txs := make([]string, 12)
+				for i := range txs {
+					txs[i] = strconv.Itoa(i)
+				}
+				m.EXPECT().BlockHeight(gomock.Any(), "btc", 1).Return(&sochain.Block{Data: sochain.BlockData{BlockNo: 1, Txs: txs}}, nil)
+
+				// only the first maxTxPerBlock transactions are fetched
+				m.EXPECT().Transactions(gomock.Any(), "btc", txs[:maxTxPerBlock]).Return(nil, errors.New("some"))
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -643,6 +743,7 @@ func TestHandleGetBlock(t *testing.T) {
 			if tt.mock != nil {
 				tt.mock(mockConn)
 			}
+			fanOut(mockConn)
 
 			handler := func(w http.ResponseWriter, r *http.Request) *gin.Engine {
 				gin.SetMode(gin.TestMode)
@@ -665,7 +766,11 @@ func TestHandleGetBlock(t *testing.T) {
 				c.Request.URL.RawPath = path
 				assert.Nil(t, err)
 
-				controller := NewController(zap.NewNop(), mockConn)
+				if tt.gotCtx != nil {
+					c.Request = c.Request.WithContext(tt.gotCtx)
+				}
+
+				controller := NewController(zap.NewNop(), mockConn, network.DefaultRegistry())
 				controller.HandleGetBlock(c)
 
 				return ginEngine
@@ -690,10 +795,214 @@ func TestHandleGetBlock(t *testing.T) {
 	}
 }
 
+// fanOut serves batches of m by the expected calls of m.Transaction
+func fanOut(m *mock_client.MockConnector) {
+	m.EXPECT().Transactions(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(func(ctx context.Context, networkID string, hashes []string) ([]sochain.TxResult, error) {
+		return sochain.FetchTransactions(ctx, hashes, 0, func(ctx context.Context, hash string) (*sochain.Transaction, error) {
+			return m.Transaction(ctx, networkID, hash)
+		}), nil
+	})
+}
+
+func TestHandleGetBlock_TxFetchObserver(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	var inFlight int
+	txids := []string{"1", "2", "3"}
+
+	mockConn := mock_client.NewMockConnector(mCtrl)
+	mockConn.EXPECT().BlockHeight(gomock.Any(), "btc", 1).Return(&sochain.Block{Data: sochain.BlockData{BlockNo: 1, Txs: txids}}, nil)
+	mockConn.EXPECT().Transactions(gomock.Any(), "btc", txids).DoAndReturn(func(ctx context.Context, networkID string, hashes []string) ([]sochain.TxResult, error) {
+		// the whole batch is in flight
+		assert.Equal(t, 3, inFlight)
+		return make([]sochain.TxResult, len(hashes)), errors.New("some")
+	})
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "btc"}}
+	c.Request = httptest.NewRequest("GET", "/network/btc?height=1", nil)
+
+	observer := func(delta int) { inFlight += delta }
+	NewController(zap.NewNop(), mockConn, network.DefaultRegistry(), WithTxFetchObserver(observer)).HandleGetBlock(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, 0, inFlight)
+}
+
+func TestHandleGetBlocks(t *testing.T) {
+
+	blocks := make([]*sochain.Block, 3)
+	for i := range blocks {
+		blocks[i] = &sochain.Block{Data: sochain.BlockData{BlockNo: 100 + i, Blockhash: fmt.Sprintf("%064x", 100+i)}}
+		if i > 0 {
+			blocks[i].Data.PreviousBlockhash = blocks[i-1].Data.Blockhash
+		}
+	}
+	serve := func(m *mock_client.MockConnector) {
+		m.EXPECT().BlockHeight(gomock.Any(), "btc", gomock.Any()).AnyTimes().DoAndReturn(func(_ context.Context, _ string, height int) (*sochain.Block, error) {
+			if height < 100 || height > 102 {
+				return nil, sochain.NewClientErr(errors.New("some"), http.StatusNotFound)
+			}
+			return blocks[height-100], nil
+		})
+	}
+
+	tests := []struct {
+		title     string
+		gotQuery  string
+		mock      func(m *mock_client.MockConnector)
+		wantError bool
+		wantCode  int
+		want      []int
+	}{
+		{
+			title:     "Error: query param from missing",
+			gotQuery:  "to=100",
+			wantError: true,
+			wantCode:  http.StatusBadRequest,
+		},
+		{
+			title:     "Error: query param to invalid hash",
+			gotQuery:  "from=100&to=abc",
+			wantError: true,
+			wantCode:  http.StatusBadRequest,
+		},
+		{
+			title:     "Error: range too large",
+			gotQuery:  "from=0&to=100",
+			wantError: true,
+			wantCode:  http.StatusBadRequest,
+		},
+		{
+			title:     "Error: block not found",
+			gotQuery:  "from=101&to=103",
+			wantError: true,
+			wantCode:  http.StatusNotFound,
+			mock:      serve,
+		},
+		{
+			title:     "Error: blocks don't link",
+			gotQuery:  "from=100&to=102",
+			wantError: true,
+			wantCode:  http.StatusBadGateway,
+			mock: func(m *mock_client.MockConnector) {
+				unlinked := *blocks[1]
+				unlinked.Data.PreviousBlockhash = blocks[2].Data.Blockhash
+				m.EXPECT().BlockHeight(gomock.Any(), "btc", 100).Return(blocks[0], nil)
+				m.EXPECT().BlockHeight(gomock.Any(), "btc", 101).Return(&unlinked, nil)
+				m.EXPECT().BlockHeight(gomock.Any(), "btc", 102).Return(blocks[2], nil).AnyTimes()
+			},
+		},
+		{
+			title:    "Success forward",
+			gotQuery: "from=100&to=102",
+			wantCode: http.StatusOK,
+			mock:     serve,
+			want:     []int{100, 101, 102},
+		},
+		{
+			title:    "Success backward by hash",
+			gotQuery: "from=" + blocks[2].Data.Blockhash + "&to=101",
+			wantCode: http.StatusOK,
+			mock: func(m *mock_client.MockConnector) {
+				serve(m)
+				m.EXPECT().BlockHash(gomock.Any(), "btc", blocks[2].Data.Blockhash).Return(blocks[2], nil)
+			},
+			want: []int{102, 101},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			mCtrl := gomock.NewController(t)
+			defer mCtrl.Finish()
+
+			mockConn := mock_client.NewMockConnector(mCtrl)
+			if tt.mock != nil {
+				tt.mock(mockConn)
+			}
+
+			gin.SetMode(gin.TestMode)
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+			c.Params = gin.Params{{Key: "id", Value: "btc"}}
+			c.Request = httptest.NewRequest("GET", "http://localhost:8080/network/btc/blocks?"+tt.gotQuery, nil)
+
+			NewController(zap.NewNop(), mockConn, network.DefaultRegistry()).HandleGetBlocks(c)
+
+			assert.Equal(t, tt.wantCode, httpRecorder.Code)
+
+			if !tt.wantError {
+				var response []sochain.BlockSummaryResponse
+				assert.Nil(t, json.Unmarshal(httpRecorder.Body.Bytes(), &response))
+
+				var got []int
+				for _, b := range response {
+					got = append(got, b.Blocknumber)
+					assert.Equal(t, blocks[b.Blocknumber-100].Data.Blockhash, b.Blockhash)
+				}
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHandleStreamBlocks(t *testing.T) {
+
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	var tip int64 = 100
+	mockConn := mock_client.NewMockConnector(mCtrl)
+	mockConn.EXPECT().NetworkInfo(gomock.Any(), "btc").AnyTimes().DoAndReturn(func(_ context.Context, _ string) (*sochain.NetworkInfo, error) {
+		return &sochain.NetworkInfo{Data: sochain.NetworkData{Blocks: int(atomic.LoadInt64(&tip))}}, nil
+	})
+	mockConn.EXPECT().BlockHeight(gomock.Any(), "btc", 101).Return(&sochain.Block{Data: sochain.BlockData{BlockNo: 101, Time: 1231455600}}, nil)
+
+	w := watcher.NewWatcher(mockConn, watcher.WithInterval(time.Millisecond))
+	defer w.Close()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/network/:id/blocks/stream", NewController(zap.NewNop(), mockConn, network.DefaultRegistry(), WithWatcher(w)).HandleStreamBlocks)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/network/xyz/blocks/stream")
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Get(srv.URL + "/network/btc/blocks/stream")
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// the tip advances once the watcher polled it
+	time.Sleep(20 * time.Millisecond)
+	atomic.StoreInt64(&tip, 101)
+
+	lines := bufio.NewScanner(resp.Body)
+	assert.True(t, lines.Scan())
+	assert.Equal(t, "event:block", lines.Text())
+	assert.True(t, lines.Scan())
+
+	var block sochain.BlockResponse
+	assert.Nil(t, json.Unmarshal([]byte(strings.TrimPrefix(lines.Text(), "data:")), &block))
+	assert.Equal(t, 101, block.Blocknumber)
+	assert.Equal(t, time.Unix(1231455600, 0).Format(time.RFC3339), block.Timestamp)
+}
+
 func TestHandleGetTransaction(t *testing.T) {
 
 	unixTime := 1231455600
 	timeRFC3339 := time.Unix(int64(unixTime), 0).Format(time.RFC3339)
+	genesisTxid := "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	genesisTxHex := "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff4d04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73ffffffff0100f2052a01000000434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac00000000"
 
 	tests := []struct {
 		title                  string
@@ -703,6 +1012,8 @@ func TestHandleGetTransaction(t *testing.T) {
 		gotPathTxHash          string
 		gotHeightQuery         string
 		gotBlockhashQuery      string
+		gotDecodeQuery         string
+		gotCtx                 context.Context
 		mock                   func(m *mock_client.MockConnector)
 		wantError              bool
 		wantCode               int
@@ -765,7 +1076,19 @@ func TestHandleGetTransaction(t *testing.T) {
 			gotPathTxHash:          "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876",
 			wantCode:               http.StatusNotFound,
 			mock: func(m *mock_client.MockConnector) {
-				m.EXPECT().Transaction("btc", "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876").Return(nil, *sochain.NewClientErr(errors.New("some"), http.StatusNotFound))
+				m.EXPECT().Transaction(gomock.Any(), "btc", "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876").Return(nil, sochain.NewClientErr(errors.New("some"), http.StatusNotFound))
+			},
+		},
+		{
+			title:                  "Error: tx not found after retries",
+			wantError:              true,
+			gotPathNetworkIDExists: true,
+			gotPathNetworkID:       "btc",
+			gotPathTxHashExists:    true,
+			gotPathTxHash:          "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876",
+			wantCode:               http.StatusNotFound,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().Transaction(gomock.Any(), "btc", "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876").Return(nil, &sochain.RetryError{Attempts: 2, Err: sochain.NewClientErr(errors.New("some"), http.StatusNotFound)})
 			},
 		},
 		{
@@ -777,7 +1100,7 @@ func TestHandleGetTransaction(t *testing.T) {
 			gotPathTxHash:          "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876",
 			wantCode:               http.StatusBadRequest,
 			mock: func(m *mock_client.MockConnector) {
-				m.EXPECT().Transaction("btc", "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876").Return(nil, *sochain.NewClientErr(errors.New("some"), http.StatusBadRequest))
+				m.EXPECT().Transaction(gomock.Any(), "btc", "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876").Return(nil, sochain.NewClientErr(errors.New("some"), http.StatusBadRequest))
 			},
 		},
 		{
@@ -789,7 +1112,7 @@ func TestHandleGetTransaction(t *testing.T) {
 			gotPathTxHash:          "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876",
 			wantCode:               http.StatusInternalServerError,
 			mock: func(m *mock_client.MockConnector) {
-				m.EXPECT().Transaction("btc", "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876").Return(nil, errors.New("some"))
+				m.EXPECT().Transaction(gomock.Any(), "btc", "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876").Return(nil, errors.New("some"))
 			},
 		},
 		{
@@ -806,18 +1129,158 @@ func TestHandleGetTransaction(t *testing.T) {
 					Data: sochain.TransactionData{
 						Txid:      "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876",
 						Time:      unixTime,
-						Fee:       "1",
-						SentValue: "1",
+						Fee:       100000000,
+						SentValue: 100000000,
+					},
+				}
+
+				m.EXPECT().Transaction(gomock.Any(), "btc", "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876").Return(&tx, nil)
+			},
+			want: &sochain.TransactionResponse{
+				TxID:      "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876",
+				Timestamp: timeRFC3339,
+				Fee:       100000000,
+				Value:     100000000,
+			},
+		},
+		{
+			title:                  "Success: testnet, network id upper case",
+			wantError:              false,
+			gotPathNetworkIDExists: true,
+			gotPathNetworkID:       "BTCTEST",
+			gotPathTxHashExists:    true,
+			gotPathTxHash:          "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876",
+			wantCode:               http.StatusOK,
+			mock: func(m *mock_client.MockConnector) {
+				tx := sochain.Transaction{
+					Data: sochain.TransactionData{
+						Txid:      "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876",
+						Time:      unixTime,
+						Fee:       100000000,
+						SentValue: 100000000,
 					},
 				}
 
-				m.EXPECT().Transaction("btc", "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876").Return(&tx, nil)
+				m.EXPECT().Transaction(gomock.Any(), "btctest", "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876").Return(&tx, nil)
 			},
 			want: &sochain.TransactionResponse{
 				TxID:      "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876",
 				Timestamp: timeRFC3339,
-				Fee:       "1",
-				Value:     "1",
+				Fee:       100000000,
+				Value:     100000000,
+			},
+		},
+		{
+			title:                  "Success: decoded",
+			wantError:              false,
+			gotPathNetworkIDExists: true,
+			gotPathNetworkID:       "btc",
+			gotPathTxHashExists:    true,
+			gotPathTxHash:          genesisTxid,
+			gotDecodeQuery:         "true",
+			wantCode:               http.StatusOK,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().Transaction(gomock.Any(), "btc", genesisTxid).Return(&sochain.Transaction{
+					Data: sochain.TransactionData{
+						Txid:  genesisTxid,
+						Time:  unixTime,
+						TxHex: genesisTxHex,
+					},
+				}, nil)
+			},
+			want: &sochain.TransactionResponse{
+				TxID:      genesisTxid,
+				Timestamp: timeRFC3339,
+				Decoded: &sochain.DecodedTxResponse{
+					Txid:    genesisTxid,
+					Wtxid:   genesisTxid,
+					Version: 1,
+					Inputs: []sochain.DecodedInputResponse{{
+						PrevTxid:  "0000000000000000000000000000000000000000000000000000000000000000",
+						PrevIndex: 0xffffffff,
+						ScriptSig: "04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73",
+						Sequence:  0xffffffff,
+					}},
+					Outputs: []sochain.DecodedOutputResponse{{
+						Value:        5000000000,
+						ScriptPubKey: "4104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac",
+					}},
+				},
+			},
+		},
+		{
+			title:                  "Error: query param decode invalid",
+			wantError:              true,
+			gotPathNetworkIDExists: true,
+			gotPathNetworkID:       "btc",
+			gotPathTxHashExists:    true,
+			gotPathTxHash:          genesisTxid,
+			gotDecodeQuery:         "maybe",
+			wantCode:               http.StatusBadRequest,
+		},
+		{
+			title:                  "Error: tx_hex undecodable",
+			wantError:              true,
+			gotPathNetworkIDExists: true,
+			gotPathNetworkID:       "btc",
+			gotPathTxHashExists:    true,
+			gotPathTxHash:          genesisTxid,
+			gotDecodeQuery:         "1",
+			wantCode:               http.StatusInternalServerError,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().Transaction(gomock.Any(), "btc", genesisTxid).Return(&sochain.Transaction{
+					Data: sochain.TransactionData{Txid: genesisTxid, TxHex: "00"},
+				}, nil)
+			},
+		},
+		{
+			title:                  "Error: tampered transaction",
+			wantError:              true,
+			gotPathNetworkIDExists: true,
+			gotPathNetworkID:       "btc",
+			gotPathTxHashExists:    true,
+			gotPathTxHash:          genesisTxid,
+			wantCode:               http.StatusBadGateway,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().Transaction(gomock.Any(), "btc", genesisTxid).Return(nil, sochain.VerifyTx(genesisTxid, sochain.TransactionData{Txid: genesisTxid, TxHex: "00"}))
+			},
+		},
+		{
+			title:                  "Error: providers disagree",
+			wantError:              true,
+			gotPathNetworkIDExists: true,
+			gotPathNetworkID:       "btc",
+			gotPathTxHashExists:    true,
+			gotPathTxHash:          genesisTxid,
+			wantCode:               http.StatusBadGateway,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().Transaction(gomock.Any(), "btc", genesisTxid).Return(nil, &failover.MismatchError{Method: "Transaction", Field: "sent_value"})
+			},
+		},
+		{
+			title:                  "Error: request cancelled",
+			wantError:              true,
+			gotPathNetworkIDExists: true,
+			gotPathNetworkID:       "btc",
+			gotPathTxHashExists:    true,
+			gotPathTxHash:          "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876",
+			gotCtx:                 canceledContext(),
+			wantCode:               sochain.StatusClientClosedRequest,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().Transaction(gomock.Any(), "btc", "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876").Return(nil, &url.Error{Op: "Get", URL: "test", Err: context.Canceled})
+			},
+		},
+		{
+			title:                  "Error: request deadline exceeded",
+			wantError:              true,
+			gotPathNetworkIDExists: true,
+			gotPathNetworkID:       "btc",
+			gotPathTxHashExists:    true,
+			gotPathTxHash:          "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876",
+			gotCtx:                 expiredContext(),
+			wantCode:               http.StatusGatewayTimeout,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().Transaction(gomock.Any(), "btc", "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876").Return(nil, context.DeadlineExceeded)
 			},
 		},
 	}
@@ -832,6 +1295,7 @@ func TestHandleGetTransaction(t *testing.T) {
 			if tt.mock != nil {
 				tt.mock(mockConn)
 			}
+			fanOut(mockConn)
 
 			handler := func(w http.ResponseWriter, r *http.Request) *gin.Engine {
 				gin.SetMode(gin.TestMode)
@@ -853,12 +1317,20 @@ func TestHandleGetTransaction(t *testing.T) {
 					path = path + "?blockhash=" + tt.gotBlockhashQuery
 				}
 
+				if tt.gotDecodeQuery != "" {
+					path = path + "?decode=" + tt.gotDecodeQuery
+				}
+
 				var err error
 				c.Request, err = http.NewRequest("GET", path, r.Body)
 				c.Request.URL.RawPath = path
 				assert.Nil(t, err)
 
-				controller := NewController(zap.NewNop(), mockConn)
+				if tt.gotCtx != nil {
+					c.Request = c.Request.WithContext(tt.gotCtx)
+				}
+
+				controller := NewController(zap.NewNop(), mockConn, network.DefaultRegistry())
 				controller.HandleGetTransaction(c)
 
 				return ginEngine
@@ -882,3 +1354,772 @@ func TestHandleGetTransaction(t *testing.T) {
 		})
 	}
 }
+
+func canceledContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
+
+func expiredContext() context.Context {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Unix(0, 0))
+	cancel()
+	return ctx
+}
+
+func TestHandleGetOutputSpender(t *testing.T) {
+
+	unixTime := 1231455600
+	timeRFC3339 := time.Unix(int64(unixTime), 0).Format(time.RFC3339)
+	txHash := "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876"
+	spenderHash := "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	spent := &sochain.SpentBy{Txid: spenderHash, InputNo: 3}
+
+	tests := []struct {
+		title     string
+		gotN      string
+		mock      func(m *mock_client.MockConnector)
+		wantError bool
+		wantCode  int
+		want      *sochain.SpenderResponse
+	}{
+		{
+			title:     "Error: path param n invalid",
+			gotN:      "-1",
+			wantError: true,
+			wantCode:  http.StatusBadRequest,
+		},
+		{
+			title:     "Error: transaction or output not found",
+			gotN:      "2",
+			wantError: true,
+			wantCode:  http.StatusNotFound,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().OutputSpender(gomock.Any(), "btc", txHash, 2).Return(nil, sochain.NewClientErr(errors.New("some"), http.StatusNotFound))
+			},
+		},
+		{
+			title:     "Error: output unspent",
+			gotN:      "1",
+			wantError: true,
+			wantCode:  http.StatusNotFound,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().OutputSpender(gomock.Any(), "btc", txHash, 1).Return(nil, nil)
+			},
+		},
+		{
+			title:     "Error: spends unknown to the provider",
+			gotN:      "0",
+			wantError: true,
+			wantCode:  http.StatusNotImplemented,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().OutputSpender(gomock.Any(), "btc", txHash, 0).Return(nil, sochain.NewClientErr(errors.New("some"), http.StatusNotImplemented))
+			},
+		},
+		{
+			title:     "Error: deadline exceeded",
+			gotN:      "0",
+			wantError: true,
+			wantCode:  http.StatusGatewayTimeout,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().OutputSpender(gomock.Any(), "btc", txHash, 0).Return(nil, context.DeadlineExceeded)
+			},
+		},
+		{
+			title:     "Error: spender unavailable",
+			gotN:      "0",
+			wantError: true,
+			wantCode:  http.StatusInternalServerError,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().OutputSpender(gomock.Any(), "btc", txHash, 0).Return(spent, nil)
+				m.EXPECT().Transaction(gomock.Any(), "btc", spenderHash).Return(nil, errors.New("some"))
+			},
+		},
+		{
+			title:    "Success",
+			gotN:     "0",
+			wantCode: http.StatusOK,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().OutputSpender(gomock.Any(), "btc", txHash, 0).Return(spent, nil)
+				m.EXPECT().Transaction(gomock.Any(), "btc", spenderHash).Return(&sochain.Transaction{
+					Data: sochain.TransactionData{
+						Txid:      spenderHash,
+						Time:      unixTime,
+						Fee:       1000,
+						SentValue: 100000000,
+					},
+				}, nil)
+			},
+			want: &sochain.SpenderResponse{
+				TransactionResponse: sochain.TransactionResponse{
+					TxID:      spenderHash,
+					Timestamp: timeRFC3339,
+					Fee:       1000,
+					Value:     100000000,
+				},
+				InputNo: 3,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			mCtrl := gomock.NewController(t)
+			defer mCtrl.Finish()
+
+			mockConn := mock_client.NewMockConnector(mCtrl)
+			if tt.mock != nil {
+				tt.mock(mockConn)
+			}
+
+			gin.SetMode(gin.TestMode)
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+			c.Params = gin.Params{{Key: "id", Value: "btc"}, {Key: "txhash", Value: txHash}, {Key: "n", Value: tt.gotN}}
+			c.Request = httptest.NewRequest("GET", "http://localhost:8080/network/btc/tx/"+txHash+"/outputs/"+tt.gotN+"/spender", nil)
+
+			NewController(zap.NewNop(), mockConn, network.DefaultRegistry()).HandleGetOutputSpender(c)
+
+			assert.Equal(t, tt.wantCode, httpRecorder.Code)
+
+			if !tt.wantError {
+				var response sochain.SpenderResponse
+				assert.Nil(t, json.Unmarshal(httpRecorder.Body.Bytes(), &response))
+				assert.True(t, reflect.DeepEqual(response, *tt.want))
+			}
+		})
+	}
+}
+
+func TestHandleGetOutputSpender_SpentAfterCached(t *testing.T) {
+
+	txHash := "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876"
+	spenderHash := "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+	mockConn := mock_client.NewMockConnector(mCtrl)
+
+	// final & unspent when cached, spent by the time its spender is asked for
+	unspent := &sochain.Transaction{Data: sochain.TransactionData{Txid: txHash, Confirmations: 100, Outputs: sochain.Outputs{{OutputNo: 0}}}}
+	mockConn.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(unspent, nil)
+	mockConn.EXPECT().OutputSpender(gomock.Any(), "btc", txHash, 0).Return(&sochain.SpentBy{Txid: spenderHash, InputNo: 1}, nil)
+	mockConn.EXPECT().Transaction(gomock.Any(), "btc", spenderHash).Return(&sochain.Transaction{Data: sochain.TransactionData{Txid: spenderHash}}, nil)
+
+	cached := cache.NewCache(mockConn)
+	_, err := cached.Transaction(context.Background(), "btc", txHash)
+	assert.Nil(t, err)
+
+	gin.SetMode(gin.TestMode)
+	httpRecorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(httpRecorder)
+	c.Params = gin.Params{{Key: "id", Value: "btc"}, {Key: "txhash", Value: txHash}, {Key: "n", Value: "0"}}
+	c.Request = httptest.NewRequest("GET", "http://localhost:8080/network/btc/tx/"+txHash+"/outputs/0/spender", nil)
+
+	NewController(zap.NewNop(), cached, network.DefaultRegistry()).HandleGetOutputSpender(c)
+
+	assert.Equal(t, http.StatusOK, httpRecorder.Code)
+
+	var response sochain.SpenderResponse
+	assert.Nil(t, json.Unmarshal(httpRecorder.Body.Bytes(), &response))
+	assert.Equal(t, spenderHash, response.TxID)
+	assert.Equal(t, 1, response.InputNo)
+}
+
+// Nodes don't index spending inputs, their outputs must not be reported unspent
+func TestHandleGetOutputSpender_SpendsUnknown(t *testing.T) {
+
+	txHash := "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected node call %s", r.URL.Path)
+	}))
+	defer srv.Close()
+	node := rpcnode.NewNode(rpcnode.WithEndpoint("btc", rpcnode.Endpoint{URL: srv.URL}))
+
+	gin.SetMode(gin.TestMode)
+	httpRecorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(httpRecorder)
+	c.Params = gin.Params{{Key: "id", Value: "btc"}, {Key: "txhash", Value: txHash}, {Key: "n", Value: "0"}}
+	c.Request = httptest.NewRequest("GET", "http://localhost:8080/network/btc/tx/"+txHash+"/outputs/0/spender", nil)
+
+	NewController(zap.NewNop(), node, network.DefaultRegistry()).HandleGetOutputSpender(c)
+
+	assert.Equal(t, http.StatusNotImplemented, httpRecorder.Code)
+}
+
+func TestHandleGetTransactionProof(t *testing.T) {
+
+	txHash := "6359f0868171b1d194cbee1af2f16ea598ae8fad666d9b012c8ed2b79a236ec4"
+	blockHash := "000000000003ba27aa200b1cecaad478d2b00432346c3f1f3986da1afd33e506"
+	version, bits, nonce := int32(1), uint32(0x1b04864c), uint32(274148111)
+
+	tx := &sochain.Transaction{Data: sochain.TransactionData{Txid: txHash, Blockhash: blockHash, BlockNo: 100000}}
+	block := &sochain.Block{
+		Data: sochain.BlockData{
+			Blockhash:         blockHash,
+			BlockNo:           100000,
+			Time:              1293623863,
+			Merkleroot:        "f3e94742aca4b5ef85488dc37c06c3282295ffec960994b2c0d5ac2a25a95766",
+			PreviousBlockhash: "000000000002d01c1fccc21636b607dfd930d31d01c3a62104612a1719011250",
+			Txs: []string{
+				"8c14f0db3df150123e6f3dbbf30f8b955a8249b62ac1d1ff16284aefa3d06d87",
+				"fff2525b8931402dd09222c50775608f75787bd2b87e56995a7bdd30f79702c4",
+				txHash,
+				"e9a66845e05d5abc0ad04ec80f774a7e585c6e8db975962d069a522137b80c1d",
+			},
+			Version: &version,
+			Bits:    &bits,
+			Nonce:   &nonce,
+		},
+	}
+	reordered := *block
+	reordered.Data.Txs = []string{block.Data.Txs[1], block.Data.Txs[0], block.Data.Txs[2], block.Data.Txs[3]}
+	headerless := *block
+	headerless.Data.Version, headerless.Data.Bits, headerless.Data.Nonce = nil, nil, nil
+
+	tests := []struct {
+		title     string
+		mock      func(m *mock_client.MockConnector)
+		wantError bool
+		wantCode  int
+	}{
+		{
+			title:     "Error: transaction not found",
+			wantError: true,
+			wantCode:  http.StatusNotFound,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(nil, sochain.NewClientErr(errors.New("some"), http.StatusNotFound))
+			},
+		},
+		{
+			title:     "Error: transaction unconfirmed",
+			wantError: true,
+			wantCode:  http.StatusNotFound,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(&sochain.Transaction{Data: sochain.TransactionData{Txid: txHash}}, nil)
+			},
+		},
+		{
+			title:     "Error: block unavailable",
+			wantError: true,
+			wantCode:  http.StatusInternalServerError,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(tx, nil)
+				m.EXPECT().BlockHash(gomock.Any(), "btc", blockHash).Return(nil, errors.New("some"))
+			},
+		},
+		{
+			title:     "Error: block not found",
+			wantError: true,
+			wantCode:  http.StatusNotFound,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(tx, nil)
+				m.EXPECT().BlockHash(gomock.Any(), "btc", blockHash).Return(nil, sochain.NewClientErr(errors.New("some"), http.StatusNotFound))
+			},
+		},
+		{
+			title:     "Error: block timed out",
+			wantError: true,
+			wantCode:  http.StatusGatewayTimeout,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(tx, nil)
+				m.EXPECT().BlockHash(gomock.Any(), "btc", blockHash).Return(nil, context.DeadlineExceeded)
+			},
+		},
+		{
+			title:     "Error: header unavailable",
+			wantError: true,
+			wantCode:  http.StatusNotImplemented,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(tx, nil)
+				m.EXPECT().BlockHash(gomock.Any(), "btc", blockHash).Return(&headerless, nil)
+			},
+		},
+		{
+			title:     "Error: merkle root mismatch",
+			wantError: true,
+			wantCode:  http.StatusBadGateway,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(tx, nil)
+				m.EXPECT().BlockHash(gomock.Any(), "btc", blockHash).Return(&reordered, nil)
+			},
+		},
+		{
+			title:    "Success",
+			wantCode: http.StatusOK,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(tx, nil)
+				m.EXPECT().BlockHash(gomock.Any(), "btc", blockHash).Return(block, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			mCtrl := gomock.NewController(t)
+			defer mCtrl.Finish()
+
+			mockConn := mock_client.NewMockConnector(mCtrl)
+			if tt.mock != nil {
+				tt.mock(mockConn)
+			}
+
+			gin.SetMode(gin.TestMode)
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+			c.Params = gin.Params{{Key: "id", Value: "btc"}, {Key: "txhash", Value: txHash}}
+			c.Request = httptest.NewRequest("GET", "http://localhost:8080/network/btc/tx/"+txHash+"/proof", nil)
+
+			NewController(zap.NewNop(), mockConn, network.DefaultRegistry()).HandleGetTransactionProof(c)
+
+			assert.Equal(t, tt.wantCode, httpRecorder.Code)
+
+			if !tt.wantError {
+				var response sochain.ProofResponse
+				assert.Nil(t, json.Unmarshal(httpRecorder.Body.Bytes(), &response))
+				assert.Equal(t, 2, response.TxIndex)
+				assert.Equal(t, 4, response.TxCount)
+				assert.Nil(t, merkle.VerifyBranch(txHash, response.TxIndex, response.Branch, response.Merkleroot))
+
+				hash, err := merkle.VerifyProof(response.Hex, txHash)
+				assert.Nil(t, err)
+				assert.Equal(t, blockHash, hash)
+			}
+		})
+	}
+}
+
+func TestHandleGetAddressBalance(t *testing.T) {
+
+	address := "1BoatSLRHtKNngkdXEeobR76b53LETtpyT"
+
+	tests := []struct {
+		title                  string
+		gotPathNetworkIDExists bool
+		gotPathAddressExists   bool
+		gotPathNetworkID       string
+		gotPathAddress         string
+		mock                   func(m *mock_client.MockConnector)
+		wantError              bool
+		wantCode               int
+		want                   *sochain.AddressBalanceResponse
+	}{
+		{
+			title:                  "Error: path param networkID invalid",
+			wantError:              true,
+			gotPathNetworkIDExists: true,
+			gotPathNetworkID:       "eth",
+			wantCode:               http.StatusBadRequest,
+		},
+		{
+			title:                  "Error: path param address missing",
+			wantError:              true,
+			gotPathNetworkIDExists: true,
+			gotPathNetworkID:       "btc",
+			wantCode:               http.StatusBadRequest,
+		},
+		{
+			title:                  "Error: address invalid characters",
+			wantError:              true,
+			gotPathNetworkIDExists: true,
+			gotPathNetworkID:       "btc",
+			gotPathAddressExists:   true,
+			gotPathAddress:         "1BoatSLRHtKNngkdXEeobR76b53LETtpy&",
+			wantCode:               http.StatusBadRequest,
+		},
+		{
+			title:                  "Error: address not found",
+			wantError:              true,
+			gotPathNetworkIDExists: true,
+			gotPathNetworkID:       "btc",
+			gotPathAddressExists:   true,
+			gotPathAddress:         address,
+			wantCode:               http.StatusNotFound,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().AddressBalance(gomock.Any(), "btc", address).Return(nil, sochain.NewClientErr(errors.New("some"), http.StatusNotFound))
+			},
+		},
+		{
+			title:                  "Error: address lookups unsupported",
+			wantError:              true,
+			gotPathNetworkIDExists: true,
+			gotPathNetworkID:       "btc",
+			gotPathAddressExists:   true,
+			gotPathAddress:         address,
+			wantCode:               http.StatusNotImplemented,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().AddressBalance(gomock.Any(), "btc", address).Return(nil, sochain.NewClientErr(errors.New("some"), http.StatusNotImplemented))
+			},
+		},
+		{
+			title:                  "Error: some error",
+			wantError:              true,
+			gotPathNetworkIDExists: true,
+			gotPathNetworkID:       "btc",
+			gotPathAddressExists:   true,
+			gotPathAddress:         address,
+			wantCode:               http.StatusInternalServerError,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().AddressBalance(gomock.Any(), "btc", address).Return(nil, errors.New("some"))
+			},
+		},
+		{
+			title:                  "Success",
+			wantError:              false,
+			gotPathNetworkIDExists: true,
+			gotPathNetworkID:       "btc",
+			gotPathAddressExists:   true,
+			gotPathAddress:         address,
+			wantCode:               http.StatusOK,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().AddressBalance(gomock.Any(), "btc", address).Return(&sochain.AddressBalance{
+					Data: sochain.AddressBalanceData{
+						Address:            address,
+						ConfirmedBalance:   100000000,
+						UnconfirmedBalance: 0,
+					},
+				}, nil)
+			},
+			want: &sochain.AddressBalanceResponse{
+				Address:            address,
+				ConfirmedBalance:   100000000,
+				UnconfirmedBalance: 0,
+			},
+		},
+		{
+			title:                  "Success: upper case bech32 address is passed in lower case",
+			wantError:              false,
+			gotPathNetworkIDExists: true,
+			gotPathNetworkID:       "btc",
+			gotPathAddressExists:   true,
+			gotPathAddress:         "BC1QAR0SRRR7XFKVY5L643LYDNW9RE59GTZZWF5MDQ",
+			wantCode:               http.StatusOK,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().AddressBalance(gomock.Any(), "btc", "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq").Return(&sochain.AddressBalance{
+					Data: sochain.AddressBalanceData{
+						Address: "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq",
+					},
+				}, nil)
+			},
+			want: &sochain.AddressBalanceResponse{
+				Address: "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq",
+			},
+		},
+		{
+			title:                  "Error: mixed case bech32 address",
+			wantError:              true,
+			gotPathNetworkIDExists: true,
+			gotPathNetworkID:       "btc",
+			gotPathAddressExists:   true,
+			gotPathAddress:         "BC1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq",
+			wantCode:               http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			path := "http://localhost:8080/network/:id/address/:address"
+			mCtrl := gomock.NewController(t)
+			defer mCtrl.Finish()
+
+			mockConn := mock_client.NewMockConnector(mCtrl)
+			if tt.mock != nil {
+				tt.mock(mockConn)
+			}
+
+			gin.SetMode(gin.TestMode)
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+
+			if tt.gotPathNetworkIDExists {
+				c.Params = append(c.Params, gin.Param{Key: "id", Value: tt.gotPathNetworkID})
+			}
+
+			if tt.gotPathAddressExists {
+				c.Params = append(c.Params, gin.Param{Key: "address", Value: tt.gotPathAddress})
+			}
+
+			c.Request = httptest.NewRequest("GET", path, nil)
+
+			controller := NewController(zap.NewNop(), mockConn, network.DefaultRegistry())
+			controller.HandleGetAddressBalance(c)
+
+			assert.Equal(t, tt.wantCode, httpRecorder.Code)
+
+			if !tt.wantError {
+				var response sochain.AddressBalanceResponse
+				assert.Nil(t, json.Unmarshal(httpRecorder.Body.Bytes(), &response))
+				assert.True(t, reflect.DeepEqual(response, *tt.want))
+			}
+		})
+	}
+}
+
+func TestHandleGetAddressTransactions(t *testing.T) {
+
+	unixTime := 1231455600
+	timeRFC3339 := time.Unix(int64(unixTime), 0).Format(time.RFC3339)
+	address := "ltc1qg82tgtnwk5yxd3gxq2mk2hy6y2q8qvgfz7pcsu"
+	after := "eb6f76a4390f4e3cdcf8d2a73fc99d401965abca0372f350bf9317a34a1aa876"
+	zero, one := 0, 1
+
+	tests := []struct {
+		title     string
+		handler   func(c *Controller) gin.HandlerFunc
+		gotAfter  string
+		gotCtx    context.Context
+		mock      func(m *mock_client.MockConnector)
+		wantError bool
+		wantCode  int
+		want      *sochain.AddressTxsResponse
+	}{
+		{
+			title:     "Error: query param after invalid",
+			handler:   func(c *Controller) gin.HandlerFunc { return c.HandleGetReceivedTransactions },
+			gotAfter:  "noHash",
+			wantError: true,
+			wantCode:  http.StatusBadRequest,
+		},
+		{
+			title:     "Error: received bad request",
+			handler:   func(c *Controller) gin.HandlerFunc { return c.HandleGetReceivedTransactions },
+			wantError: true,
+			wantCode:  http.StatusBadRequest,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().ReceivedTransactions(gomock.Any(), "ltc", address, "").Return(nil, sochain.NewClientErr(errors.New("some"), http.StatusBadRequest))
+			},
+		},
+		{
+			title:     "Error: spent request cancelled",
+			handler:   func(c *Controller) gin.HandlerFunc { return c.HandleGetSpentTransactions },
+			gotCtx:    canceledContext(),
+			wantError: true,
+			wantCode:  sochain.StatusClientClosedRequest,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().SpentTransactions(gomock.Any(), "ltc", address, "").Return(nil, context.Canceled)
+			},
+		},
+		{
+			title:    "Success: received",
+			handler:  func(c *Controller) gin.HandlerFunc { return c.HandleGetReceivedTransactions },
+			gotAfter: after,
+			wantCode: http.StatusOK,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().ReceivedTransactions(gomock.Any(), "ltc", address, after).Return(&sochain.ReceivedTxs{
+					Data: sochain.ReceivedTxsData{
+						Address: address,
+						Txs:     []sochain.ReceivedTx{{Txid: "1", OutputNo: 1, Value: 100000000, Confirmations: 1, Time: unixTime}},
+					},
+				}, nil)
+			},
+			want: &sochain.AddressTxsResponse{
+				Address: address,
+				Transactions: sochain.AddressTxResponses{
+					{TxID: "1", OutputNo: &one, Value: 100000000, Confirmations: 1, Timestamp: timeRFC3339},
+				},
+			},
+		},
+		{
+			title:    "Success: spent",
+			handler:  func(c *Controller) gin.HandlerFunc { return c.HandleGetSpentTransactions },
+			wantCode: http.StatusOK,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().SpentTransactions(gomock.Any(), "ltc", address, "").Return(&sochain.SpentTxs{
+					Data: sochain.SpentTxsData{
+						Address: address,
+						Txs:     []sochain.SpentTx{{Txid: "1", InputNo: 0, Value: 100000000, Confirmations: 1, Time: unixTime}},
+					},
+				}, nil)
+			},
+			want: &sochain.AddressTxsResponse{
+				Address: address,
+				Transactions: sochain.AddressTxResponses{
+					{TxID: "1", InputNo: &zero, Value: 100000000, Confirmations: 1, Timestamp: timeRFC3339},
+				},
+			},
+		},
+		{
+			title:    "Success: unspent",
+			handler:  func(c *Controller) gin.HandlerFunc { return c.HandleGetUnspentOutputs },
+			wantCode: http.StatusOK,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().UnspentOutputs(gomock.Any(), "ltc", address, "").Return(&sochain.UnspentOutputs{
+					Data: sochain.UnspentOutputsData{
+						Address: address,
+						Txs:     []sochain.UnspentOutput{{Txid: "1", OutputNo: 1, Value: 100000000, Confirmations: 1, Time: unixTime}},
+					},
+				}, nil)
+			},
+			want: &sochain.AddressTxsResponse{
+				Address: address,
+				Transactions: sochain.AddressTxResponses{
+					{TxID: "1", OutputNo: &one, Value: 100000000, Confirmations: 1, Timestamp: timeRFC3339},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			path := "http://localhost:8080/network/ltc/address/" + address
+			if tt.gotAfter != "" {
+				path = path + "?after=" + tt.gotAfter
+			}
+
+			mCtrl := gomock.NewController(t)
+			defer mCtrl.Finish()
+
+			mockConn := mock_client.NewMockConnector(mCtrl)
+			if tt.mock != nil {
+				tt.mock(mockConn)
+			}
+
+			gin.SetMode(gin.TestMode)
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+			c.Params = gin.Params{{Key: "id", Value: "ltc"}, {Key: "address", Value: address}}
+
+			c.Request = httptest.NewRequest("GET", path, nil)
+			if tt.gotCtx != nil {
+				c.Request = c.Request.WithContext(tt.gotCtx)
+			}
+
+			tt.handler(NewController(zap.NewNop(), mockConn, network.DefaultRegistry()))(c)
+
+			assert.Equal(t, tt.wantCode, httpRecorder.Code)
+
+			if !tt.wantError {
+				var response sochain.AddressTxsResponse
+				assert.Nil(t, json.Unmarshal(httpRecorder.Body.Bytes(), &response))
+				assert.True(t, reflect.DeepEqual(response, *tt.want))
+			}
+		})
+	}
+}
+
+func TestHandlePostTransaction(t *testing.T) {
+
+	// coinbase transaction of the BTC genesis block
+	txHex := "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff4d04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73ffffffff0100f2052a01000000434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac00000000"
+	txid := "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+	tests := []struct {
+		title     string
+		gotBody   string
+		mock      func(m *mock_client.MockConnector)
+		wantError bool
+		wantCode  int
+		want      *sochain.BroadcastResponse
+	}{
+		{
+			title:     "Error: body no JSON",
+			gotBody:   txHex,
+			wantError: true,
+			wantCode:  http.StatusBadRequest,
+		},
+		{
+			title:     "Error: body too large",
+			gotBody:   `{"tx_hex":"` + strings.Repeat("00", maxTxHexSize) + `"}`,
+			wantError: true,
+			wantCode:  http.StatusRequestEntityTooLarge,
+		},
+		{
+			title:     "Error: tx_hex missing",
+			gotBody:   `{}`,
+			wantError: true,
+			wantCode:  http.StatusBadRequest,
+		},
+		{
+			title:     "Error: tx_hex invalid hex",
+			gotBody:   `{"tx_hex":"0100zz"}`,
+			wantError: true,
+			wantCode:  http.StatusBadRequest,
+		},
+		{
+			title:     "Error: tx_hex no transaction",
+			gotBody:   `{"tx_hex":"01000000"}`,
+			wantError: true,
+			wantCode:  http.StatusBadRequest,
+		},
+		{
+			title:     "Error: rejected already known",
+			gotBody:   `{"tx_hex":"` + txHex + `"}`,
+			wantError: true,
+			wantCode:  http.StatusConflict,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().BroadcastTransaction(gomock.Any(), "btc", txHex).Return(nil, sochain.NewBroadcastErr("Transaction already in block chain"))
+			},
+		},
+		{
+			title:     "Error: rejected missing inputs",
+			gotBody:   `{"tx_hex":"` + txHex + `"}`,
+			wantError: true,
+			wantCode:  http.StatusPreconditionFailed,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().BroadcastTransaction(gomock.Any(), "btc", txHex).Return(nil, sochain.NewBroadcastErr("bad-txns-inputs-missingorspent"))
+			},
+		},
+		{
+			title:     "Error: rejected insufficient fee",
+			gotBody:   `{"tx_hex":"` + txHex + `"}`,
+			wantError: true,
+			wantCode:  http.StatusPaymentRequired,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().BroadcastTransaction(gomock.Any(), "btc", txHex).Return(nil, sochain.NewBroadcastErr("min relay fee not met"))
+			},
+		},
+		{
+			title:     "Error: some error",
+			gotBody:   `{"tx_hex":"` + txHex + `"}`,
+			wantError: true,
+			wantCode:  http.StatusInternalServerError,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().BroadcastTransaction(gomock.Any(), "btc", txHex).Return(nil, errors.New("some"))
+			},
+		},
+		{
+			title:    "Success",
+			gotBody:  `{"tx_hex":"` + txHex + `"}`,
+			wantCode: http.StatusOK,
+			mock: func(m *mock_client.MockConnector) {
+				m.EXPECT().BroadcastTransaction(gomock.Any(), "btc", txHex).Return(&sochain.BroadcastTx{
+					Data: sochain.BroadcastTxData{Network: "BTC", Txid: txid},
+				}, nil)
+			},
+			want: &sochain.BroadcastResponse{
+				Network: "BTC",
+				TxID:    txid,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			mCtrl := gomock.NewController(t)
+			defer mCtrl.Finish()
+
+			mockConn := mock_client.NewMockConnector(mCtrl)
+			if tt.mock != nil {
+				tt.mock(mockConn)
+			}
+
+			gin.SetMode(gin.TestMode)
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+			c.Params = gin.Params{{Key: "id", Value: "btc"}}
+			c.Request = httptest.NewRequest("POST", "http://localhost:8080/network/btc/tx", strings.NewReader(tt.gotBody))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			NewController(zap.NewNop(), mockConn, network.DefaultRegistry()).HandlePostTransaction(c)
+
+			assert.Equal(t, tt.wantCode, httpRecorder.Code)
+
+			if !tt.wantError {
+				var response sochain.BroadcastResponse
+				assert.Nil(t, json.Unmarshal(httpRecorder.Body.Bytes(), &response))
+				assert.True(t, reflect.DeepEqual(response, *tt.want))
+			}
+		})
+	}
+}
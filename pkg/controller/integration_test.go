@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sochain-client/pkg/httpreplay"
+	"sochain-client/pkg/network"
+	"sochain-client/pkg/sochain"
+	"sochain-client/pkg/sochain/sochaintest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// TestIntegration serves requests by the sochain client replaying testdata/replay/TestIntegration.json, HTTPREPLAY=record
+// replaces the hand-written fixture with one recorded from the sochain API. Only data which doesn't change after
+// confirmation is checked, so that any recording passes
+func TestIntegration(t *testing.T) {
+	r := newIntegrationRouter(httpreplay.ForTest(t, filepath.Join("testdata", "replay", t.Name()+".json")))
+
+	tests := []integrationTest{
+		{
+			title:    "blocks",
+			method:   "GET",
+			path:     "/network/btc/blocks?from=100000&to=100000",
+			wantCode: http.StatusOK,
+		},
+		{
+			title:    "transaction decoded",
+			method:   "GET",
+			path:     "/network/btc/tx/" + sochaintest.TxHash + "?decode=true",
+			wantCode: http.StatusOK,
+			want:     map[string]interface{}{"txid": sochaintest.TxHash, "time": "2009-01-03T18:15:05Z"},
+		},
+		{
+			title:    "transaction not found",
+			method:   "GET",
+			path:     "/network/btc/tx/" + sochaintest.MissingTxHash,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			title:    "address balance",
+			method:   "GET",
+			path:     "/network/btc/address/" + sochaintest.Address,
+			wantCode: http.StatusOK,
+			want:     map[string]interface{}{"address": sochaintest.Address},
+		},
+		{
+			title:    "unspent outputs",
+			method:   "GET",
+			path:     "/network/btc/address/" + sochaintest.Address + "/unspent",
+			wantCode: http.StatusOK,
+			want:     map[string]interface{}{"address": sochaintest.Address},
+		},
+		{
+			title:    "unspent outputs after",
+			method:   "GET",
+			path:     "/network/btc/address/" + sochaintest.Address + "/unspent?after=" + sochaintest.DonationTxHash,
+			wantCode: http.StatusOK,
+		},
+	}
+
+	runIntegration(t, r, tests)
+}
+
+// TestIntegration_Broadcast replays testdata/replay/TestIntegration_Broadcast.json, written by hand & never recorded,
+// recording would send the transaction
+func TestIntegration_Broadcast(t *testing.T) {
+	r := newIntegrationRouter(httpreplay.ReplayForTest(t, filepath.Join("testdata", "replay", t.Name()+".json")))
+
+	broadcast := `{"tx_hex":"` + sochaintest.BroadcastTxHex + `"}`
+
+	tests := []integrationTest{
+		{
+			title:    "broadcast",
+			method:   "POST",
+			path:     "/network/btc/tx",
+			body:     broadcast,
+			wantCode: http.StatusOK,
+			want:     map[string]interface{}{"network": "BTC", "txid": sochaintest.BroadcastTxid},
+		},
+		{
+			title:    "broadcast rejected",
+			method:   "POST",
+			path:     "/network/btctest/tx",
+			body:     broadcast,
+			wantCode: http.StatusConflict,
+		},
+	}
+
+	runIntegration(t, r, tests)
+}
+
+type integrationTest struct {
+	title    string
+	method   string
+	path     string
+	body     string
+	wantCode int
+	// fields of the JSON response
+	want map[string]interface{}
+}
+
+// newIntegrationRouter routes to a controller of the sochain client sending requests by tr
+func newIntegrationRouter(tr http.RoundTripper) *gin.Engine {
+	client := sochain.NewSochain(sochain.WithTransport(tr))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	c := NewController(zap.NewNop(), client, network.DefaultRegistry())
+	r.GET("/network/:id/blocks", c.HandleGetBlocks)
+	r.GET("/network/:id/tx/:txhash", c.HandleGetTransaction)
+	r.POST("/network/:id/tx", c.HandlePostTransaction)
+	r.GET("/network/:id/address/:address", c.HandleGetAddressBalance)
+	r.GET("/network/:id/address/:address/unspent", c.HandleGetUnspentOutputs)
+
+	return r
+}
+
+func runIntegration(t *testing.T, r *gin.Engine, tests []integrationTest) {
+	t.Helper()
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code, w.Body.String())
+
+			if len(tt.want) > 0 {
+				var got map[string]interface{}
+				assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &got))
+				for k, v := range tt.want {
+					assert.Equal(t, v, got[k], k)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,595 @@
+// Package esplora implements sochain.Connector on top of the Esplora REST API served by Blockstream, mempool.space &
+// self-hosted electrs instances
+package esplora
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sochain-client/pkg/network"
+	"sochain-client/pkg/sochain"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Esplora returns confirmed address transactions in pages of 25
+const pageSize = 25
+
+// DefaultBaseURLs are the public Esplora instances per network id
+func DefaultBaseURLs() map[string]string {
+	return map[string]string{
+		"btc":     "https://blockstream.info/api",
+		"btctest": "https://blockstream.info/testnet/api",
+		"ltc":     "https://litecoinspace.org/api",
+	}
+}
+
+type Esplora struct {
+	Client    *http.Client
+	baseURLs  map[string]string
+	timeout   time.Duration
+	userAgent string
+	networks  *network.Registry
+	// parallel requests of Transactions
+	concurrency int
+}
+
+func NewEsplora(opts ...Option) sochain.Connector {
+	e := &Esplora{
+		Client:      &http.Client{},
+		baseURLs:    DefaultBaseURLs(),
+		networks:    network.DefaultRegistry(),
+		concurrency: sochain.DefaultConcurrency,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	if e.timeout > 0 {
+		client := *e.Client
+		client.Timeout = e.timeout
+		e.Client = &client
+	}
+
+	return e
+}
+
+// NetworkInfo reports the chain tip & mempool size, Esplora serves no market data
+func (c *Esplora) NetworkInfo(ctx context.Context, networkID string) (*sochain.NetworkInfo, error) {
+
+	n, baseURL, err := c.lookup(networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	tip, err := c.tipHeight(ctx, baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var mempool mempoolInfo
+	if err := c.getJSON(ctx, baseURL+"/mempool", "mempool", &mempool); err != nil {
+		return nil, err
+	}
+
+	return &sochain.NetworkInfo{
+		Status: "success",
+		Data: sochain.NetworkData{
+			Name:           n.Name,
+			Acronym:        n.SochainID,
+			Network:        n.SochainID,
+			Blocks:         tip,
+			UnconfirmedTxs: mempool.Count,
+		},
+	}, nil
+}
+
+func (c *Esplora) BlockHeight(ctx context.Context, networkID string, height int) (*sochain.Block, error) {
+
+	n, baseURL, err := c.lookup(networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := c.get(ctx, fmt.Sprintf("%s/block-height/%d", baseURL, height), fmt.Sprintf("height '%d'", height))
+	if err != nil {
+		return nil, err
+	}
+
+	return c.block(ctx, n, baseURL, strings.TrimSpace(string(hash)))
+}
+
+func (c *Esplora) BlockHash(ctx context.Context, networkID, blockHash string) (*sochain.Block, error) {
+
+	n, baseURL, err := c.lookup(networkID)
+	if err != nil {
+		return nil, err
+	}
+	if !n.ValidHash(blockHash) {
+		return nil, sochain.NewValidationErr(sochain.ErrInvalidHash, "blockhash", fmt.Errorf("invalid blockhash '%s' of network '%s'", blockHash, n.ID))
+	}
+
+	return c.block(ctx, n, baseURL, blockHash)
+}
+
+func (c *Esplora) Transaction(ctx context.Context, networkID, txHash string) (*sochain.Transaction, error) {
+
+	n, baseURL, err := c.lookup(networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.transaction(ctx, n, baseURL, txHash, c.newTip(baseURL))
+}
+
+// Transactions fetches the tip height once for the whole batch
+func (c *Esplora) Transactions(ctx context.Context, networkID string, hashes []string) ([]sochain.TxResult, error) {
+
+	n, baseURL, err := c.lookup(networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	tip := c.newTip(baseURL)
+	return sochain.FetchTransactions(ctx, hashes, c.concurrency, func(ctx context.Context, hash string) (*sochain.Transaction, error) {
+		return c.transaction(ctx, n, baseURL, hash, tip)
+	}), nil
+}
+
+func (c *Esplora) OutputSpender(ctx context.Context, networkID, txHash string, outputNo int) (*sochain.SpentBy, error) {
+
+	n, baseURL, err := c.lookup(networkID)
+	if err != nil {
+		return nil, err
+	}
+	if !n.ValidHash(txHash) {
+		return nil, sochain.NewValidationErr(sochain.ErrInvalidHash, "txhash", fmt.Errorf("invalid txhash '%s' of network '%s'", txHash, n.ID))
+	}
+
+	// outspend/:vout doesn't check the output exists, outspends lists one entry per output of known transactions
+	var outspends []outspend
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/tx/%s/outspends", baseURL, txHash), fmt.Sprintf("txhash '%s'", txHash), &outspends); err != nil {
+		return nil, err
+	}
+	if outputNo < 0 || outputNo >= len(outspends) {
+		return nil, sochain.NewClientErr(fmt.Errorf("output %d of txhash '%s' not found", outputNo, txHash), http.StatusNotFound)
+	}
+
+	return outspends[outputNo].spentBy(), nil
+}
+
+func (c *Esplora) AddressBalance(ctx context.Context, networkID, address string) (*sochain.AddressBalance, error) {
+
+	n, baseURL, err := c.lookup(networkID)
+	if err != nil {
+		return nil, err
+	}
+	if !n.ValidAddress(address) {
+		return nil, invalidAddressErr(n, address)
+	}
+
+	var info addressInfo
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/address/%s", baseURL, address), fmt.Sprintf("address '%s'", address), &info); err != nil {
+		return nil, err
+	}
+
+	return &sochain.AddressBalance{
+		Status: "success",
+		Data: sochain.AddressBalanceData{
+			Network:            n.SochainID,
+			Address:            address,
+			ConfirmedBalance:   sochain.Amount(info.ChainStats.balance()),
+			UnconfirmedBalance: sochain.Amount(info.MempoolStats.balance()),
+		},
+	}, nil
+}
+
+// ReceivedTransactions lists the confirmed outputs paying to address, newest first
+func (c *Esplora) ReceivedTransactions(ctx context.Context, networkID, address, afterTxid string) (*sochain.ReceivedTxs, error) {
+
+	n, baseURL, err := c.lookup(networkID)
+	if err != nil {
+		return nil, err
+	}
+	if !n.ValidAddress(address) {
+		return nil, invalidAddressErr(n, address)
+	}
+
+	received := make([]sochain.ReceivedTx, 0)
+	err = c.addressTxs(ctx, baseURL, address, afterTxid, func(t tx, tip int) int {
+		count := 0
+		for i, out := range t.Vout {
+			if out.ScriptpubkeyAddress != address {
+				continue
+			}
+
+			received = append(received, sochain.ReceivedTx{
+				Txid:          t.Txid,
+				OutputNo:      i,
+				ScriptAsm:     out.ScriptpubkeyAsm,
+				ScriptHex:     out.Scriptpubkey,
+				Value:         sochain.Amount(out.Value),
+				Confirmations: confirmations(t.Status.Confirmed, t.Status.BlockHeight, tip),
+				Time:          t.Status.BlockTime,
+			})
+			count++
+		}
+		return count
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &sochain.ReceivedTxs{
+		Status: "success",
+		Data:   sochain.ReceivedTxsData{Network: n.SochainID, Address: address, Txs: received},
+	}, nil
+}
+
+// SpentTransactions lists the confirmed inputs spending outputs of address, newest first
+func (c *Esplora) SpentTransactions(ctx context.Context, networkID, address, afterTxid string) (*sochain.SpentTxs, error) {
+
+	n, baseURL, err := c.lookup(networkID)
+	if err != nil {
+		return nil, err
+	}
+	if !n.ValidAddress(address) {
+		return nil, invalidAddressErr(n, address)
+	}
+
+	spent := make([]sochain.SpentTx, 0)
+	err = c.addressTxs(ctx, baseURL, address, afterTxid, func(t tx, tip int) int {
+		count := 0
+		for i, in := range t.Vin {
+			if in.Prevout == nil || in.Prevout.ScriptpubkeyAddress != address {
+				continue
+			}
+
+			spent = append(spent, sochain.SpentTx{
+				Txid:          t.Txid,
+				InputNo:       i,
+				Value:         sochain.Amount(in.Prevout.Value),
+				Confirmations: confirmations(t.Status.Confirmed, t.Status.BlockHeight, tip),
+				Time:          t.Status.BlockTime,
+			})
+			count++
+		}
+		return count
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &sochain.SpentTxs{
+		Status: "success",
+		Data:   sochain.SpentTxsData{Network: n.SochainID, Address: address, Txs: spent},
+	}, nil
+}
+
+// UnspentOutputs lists the outputs of address in the order of Esplora, starting after the outputs of afterTxid.
+// Esplora omits the scripts of unspent outputs
+func (c *Esplora) UnspentOutputs(ctx context.Context, networkID, address, afterTxid string) (*sochain.UnspentOutputs, error) {
+
+	n, baseURL, err := c.lookup(networkID)
+	if err != nil {
+		return nil, err
+	}
+	if !n.ValidAddress(address) {
+		return nil, invalidAddressErr(n, address)
+	}
+
+	var utxos []utxo
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/address/%s/utxo", baseURL, address), fmt.Sprintf("address '%s'", address), &utxos); err != nil {
+		return nil, err
+	}
+
+	tip, err := c.tipHeight(ctx, baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]sochain.UnspentOutput, 0)
+	skip := afterTxid != ""
+	for i, u := range utxos {
+		if skip {
+			// continue after the last output of afterTxid
+			skip = u.Txid != afterTxid || (i+1 < len(utxos) && utxos[i+1].Txid == afterTxid)
+			continue
+		}
+		if len(outputs) >= sochain.AddressTxPageSize {
+			break
+		}
+
+		outputs = append(outputs, sochain.UnspentOutput{
+			Txid:          u.Txid,
+			OutputNo:      u.Vout,
+			Value:         sochain.Amount(u.Value),
+			Confirmations: confirmations(u.Status.Confirmed, u.Status.BlockHeight, tip),
+			Time:          u.Status.BlockTime,
+		})
+	}
+
+	return &sochain.UnspentOutputs{
+		Status: "success",
+		Data:   sochain.UnspentOutputsData{Network: n.SochainID, Address: address, Txs: outputs},
+	}, nil
+}
+
+// BroadcastTransaction pushes a signed raw transaction to the network. Broadcasts are never retried
+func (c *Esplora) BroadcastTransaction(ctx context.Context, networkID, txHex string) (*sochain.BroadcastTx, error) {
+
+	n, baseURL, err := c.lookup(networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, baseURL+"/tx", strings.NewReader(txHex))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "text/plain")
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return nil, sochain.NewBroadcastErr(rejectMessage(body))
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, sochain.NewClientErr(fmt.Errorf("esplora response statuscode %d, broadcast network '%s'", resp.StatusCode, n.SochainID), resp.StatusCode)
+	}
+
+	return &sochain.BroadcastTx{
+		Status: "success",
+		Data:   sochain.BroadcastTxData{Network: n.SochainID, Txid: strings.TrimSpace(string(body))},
+	}, nil
+}
+
+// rejectMessage extracts the node message of a rejected broadcast, e.g.
+// sendrawtransaction RPC error: {"code":-26,"message":"min relay fee not met"}
+func rejectMessage(body []byte) string {
+	msg := strings.TrimSpace(string(body))
+
+	var rpcErr struct {
+		Message string `json:"message"`
+	}
+	if i := strings.Index(msg, "{"); i >= 0 {
+		if err := json.Unmarshal([]byte(msg[i:]), &rpcErr); err == nil && rpcErr.Message != "" {
+			return rpcErr.Message
+		}
+	}
+
+	return msg
+}
+
+// lookup resolves networkID in the network registry & its Esplora url. Unknown, disabled & networks without url
+// are returned as *sochain.ClientError matching sochain.ErrInvalidNetwork
+func (c *Esplora) lookup(networkID string) (network.Network, string, error) {
+	n, err := c.networks.Lookup(networkID)
+	if err != nil {
+		return network.Network{}, "", sochain.NewValidationErr(sochain.ErrInvalidNetwork, "network", err)
+	}
+
+	baseURL, ok := c.baseURLs[n.ID]
+	if !ok {
+		return network.Network{}, "", sochain.NewValidationErr(sochain.ErrInvalidNetwork, "network", fmt.Errorf("network '%s' is not served by esplora", n.ID))
+	}
+
+	return n, baseURL, nil
+}
+
+func invalidAddressErr(n network.Network, address string) error {
+	return sochain.NewValidationErr(sochain.ErrInvalidAddress, "address", fmt.Errorf("invalid address '%s' of network '%s'", address, n.ID))
+}
+
+// block assembles a block of its header, txids & chain status
+func (c *Esplora) block(ctx context.Context, n network.Network, baseURL, hash string) (*sochain.Block, error) {
+
+	subject := fmt.Sprintf("blockhash '%s'", hash)
+
+	var b block
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/block/%s", baseURL, hash), subject, &b); err != nil {
+		return nil, err
+	}
+
+	txids := make([]string, 0, b.TxCount)
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/block/%s/txids", baseURL, hash), subject, &txids); err != nil {
+		return nil, err
+	}
+
+	var status blockStatus
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/block/%s/status", baseURL, hash), subject, &status); err != nil {
+		return nil, err
+	}
+
+	tip, err := c.tipHeight(ctx, baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	version, bits, nonce := int32(b.Version), b.Bits, b.Nonce
+	return &sochain.Block{
+		Status: "success",
+		Data: sochain.BlockData{
+			Network:           n.SochainID,
+			Blockhash:         b.ID,
+			BlockNo:           b.Height,
+			MiningDifficulty:  strconv.FormatFloat(b.Difficulty, 'f', -1, 64),
+			Time:              b.Timestamp,
+			Confirmations:     confirmations(status.InBestChain, b.Height, tip),
+			IsOrphan:          !status.InBestChain,
+			Txs:               txids,
+			Merkleroot:        b.MerkleRoot,
+			PreviousBlockhash: b.PreviousBlockhash,
+			NextBlockhash:     status.NextBest,
+			Size:              b.Size,
+			Version:           &version,
+			Bits:              &bits,
+			Nonce:             &nonce,
+		},
+	}, nil
+}
+
+// addressTxs pages through the confirmed transactions of address after afterTxid. collect returns the number of
+// entries it took of a transaction, paging stops once a page of sochain.AddressTxPageSize entries is collected
+func (c *Esplora) addressTxs(ctx context.Context, baseURL, address, afterTxid string, collect func(t tx, tip int) int) error {
+
+	tip, err := c.tipHeight(ctx, baseURL)
+	if err != nil {
+		return err
+	}
+
+	collected := 0
+	for {
+		url := fmt.Sprintf("%s/address/%s/txs/chain", baseURL, address)
+		if afterTxid != "" {
+			url += "/" + afterTxid
+		}
+
+		var txs []tx
+		if err := c.getJSON(ctx, url, fmt.Sprintf("address '%s'", address), &txs); err != nil {
+			return err
+		}
+
+		for _, t := range txs {
+			collected += collect(t, tip)
+			if collected >= sochain.AddressTxPageSize {
+				return nil
+			}
+		}
+
+		if len(txs) < pageSize {
+			return nil
+		}
+		afterTxid = txs[len(txs)-1].Txid
+	}
+}
+
+// transaction assembles a transaction of its details & raw hex. Spending inputs of the outputs are left to
+// OutputSpender, they would cost another request per transaction
+func (c *Esplora) transaction(ctx context.Context, n network.Network, baseURL, txHash string, tip *sharedTip) (*sochain.Transaction, error) {
+
+	if !n.ValidHash(txHash) {
+		return nil, sochain.NewValidationErr(sochain.ErrInvalidHash, "txhash", fmt.Errorf("invalid txhash '%s' of network '%s'", txHash, n.ID))
+	}
+
+	subject := fmt.Sprintf("txhash '%s'", txHash)
+
+	var t tx
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/tx/%s", baseURL, txHash), subject, &t); err != nil {
+		return nil, err
+	}
+
+	txHex, err := c.get(ctx, fmt.Sprintf("%s/tx/%s/hex", baseURL, txHash), subject)
+	if err != nil {
+		return nil, err
+	}
+
+	height := 0
+	if t.Status.Confirmed {
+		if height, err = tip.height(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	tr := t.transaction(n.SochainID, height, strings.TrimSpace(string(txHex)))
+	return &tr, nil
+}
+
+// sharedTip is the tip height of an Esplora instance, fetched once by the first request needing it
+type sharedTip struct {
+	once  sync.Once
+	fetch func(ctx context.Context) (int, error)
+	value int
+	err   error
+}
+
+// newTip shares one tip height request among the requests of a call, e.g. the transactions of a batch
+func (c *Esplora) newTip(baseURL string) *sharedTip {
+	return &sharedTip{fetch: func(ctx context.Context) (int, error) {
+		return c.tipHeight(ctx, baseURL)
+	}}
+}
+
+func (t *sharedTip) height(ctx context.Context) (int, error) {
+	t.once.Do(func() {
+		t.value, t.err = t.fetch(ctx)
+	})
+
+	return t.value, t.err
+}
+
+func (c *Esplora) tipHeight(ctx context.Context, baseURL string) (int, error) {
+	body, err := c.get(ctx, baseURL+"/blocks/tip/height", "tip height")
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(strings.TrimSpace(string(body)))
+}
+
+func (c *Esplora) getJSON(ctx context.Context, url, subject string, v interface{}) error {
+	body, err := c.get(ctx, url, subject)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+// get requests url bound to ctx & returns the body. Non 200 responses are returned as *sochain.ClientError carrying
+// the plain text reason of Esplora, subject describes the requested resource
+func (c *Esplora) get(ctx context.Context, url, subject string) ([]byte, error) {
+
+	req, err := c.newRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		cErr := sochain.NewClientErr(fmt.Errorf("esplora response statuscode %d, %s", resp.StatusCode, subject), resp.StatusCode)
+		cErr.Message = strings.TrimSpace(string(body))
+		return nil, cErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+func (c *Esplora) newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	return req, nil
+}
@@ -0,0 +1,163 @@
+package esplora
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sochain-client/pkg/sochain"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NetworkNotServed(t *testing.T) {
+	e := NewEsplora()
+
+	_, err := e.NetworkInfo(context.Background(), "doge")
+	assert.ErrorIs(t, err, sochain.ErrInvalidNetwork)
+}
+
+func Test_RejectMessage(t *testing.T) {
+	assert.Equal(t, "min relay fee not met", rejectMessage([]byte(`sendrawtransaction RPC error: {"code":-26,"message":"min relay fee not met"}`)))
+	assert.Equal(t, "Invalid hex string", rejectMessage([]byte("Invalid hex string\n")))
+}
+
+func Test_ReceivedTransactions_Pages(t *testing.T) {
+	const address = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+
+	// 5 pages of 25 transactions each paying address once
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/blocks/tip/height" {
+			w.Write([]byte("1000"))
+			return
+		}
+
+		requests = append(requests, r.URL.Path)
+		page := len(requests) - 1
+
+		txs := make([]string, pageSize)
+		for i := range txs {
+			txs[i] = fmt.Sprintf(`{"txid":"%064d","vout":[{"scriptpubkey_address":"%s","value":1000}],"status":{"confirmed":true,"block_height":900}}`, page*pageSize+i, address)
+		}
+		w.Write([]byte("[" + strings.Join(txs, ",") + "]"))
+	}))
+	defer srv.Close()
+
+	e := NewEsplora(WithBaseURL("btc", srv.URL))
+	got, err := e.ReceivedTransactions(context.Background(), "btc", address, "")
+	assert.Nil(t, err)
+
+	assert.Len(t, got.Data.Txs, sochain.AddressTxPageSize)
+	assert.Equal(t, []string{
+		"/address/" + address + "/txs/chain",
+		fmt.Sprintf("/address/%s/txs/chain/%064d", address, 24),
+		fmt.Sprintf("/address/%s/txs/chain/%064d", address, 49),
+		fmt.Sprintf("/address/%s/txs/chain/%064d", address, 74),
+	}, requests)
+	assert.Equal(t, 101, got.Data.Txs[0].Confirmations)
+	assert.Equal(t, fmt.Sprintf("%064d", 99), got.Response().After)
+}
+
+func Test_UnspentOutputs_After(t *testing.T) {
+	const address = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/blocks/tip/height" {
+			w.Write([]byte("1000"))
+			return
+		}
+
+		w.Write([]byte(`[{"txid":"a","vout":0,"value":1},{"txid":"b","vout":0,"value":2},{"txid":"b","vout":1,"value":3},{"txid":"c","vout":0,"value":4}]`))
+	}))
+	defer srv.Close()
+
+	e := NewEsplora(WithBaseURL("btc", srv.URL))
+	got, err := e.UnspentOutputs(context.Background(), "btc", address, "b")
+	assert.Nil(t, err)
+
+	if assert.Len(t, got.Data.Txs, 1) {
+		assert.Equal(t, "c", got.Data.Txs[0].Txid)
+		assert.Equal(t, sochain.Amount(4), got.Data.Txs[0].Value)
+	}
+}
+
+func Test_Transaction_Inputs(t *testing.T) {
+	t1 := tx{
+		Txid: "b",
+		Vin:  []vin{{Txid: "a", Vout: 1, Prevout: &vout{Value: 3}}},
+		Vout: []vout{{Value: 1}, {Value: 1}},
+	}
+
+	got := t1.transaction("BTC", 0, "")
+
+	assert.Equal(t, &sochain.ReceivedFrom{Txid: "a", OutputNo: 1}, got.Data.Inputs[0].ReceivedFrom)
+	assert.Equal(t, sochain.Amount(2), got.Data.SentValue)
+	assert.Nil(t, got.Data.Outputs[0].Spent)
+}
+
+func Test_OutputSpender(t *testing.T) {
+	const txHash = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/tx/"+txHash+"/outspends", r.URL.Path)
+		w.Write([]byte(`[{"spent":true,"txid":"c","vin":2},{"spent":false}]`))
+	}))
+	defer srv.Close()
+
+	e := NewEsplora(WithBaseURL("btc", srv.URL))
+
+	got, err := e.OutputSpender(context.Background(), "btc", txHash, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, &sochain.SpentBy{Txid: "c", InputNo: 2}, got)
+
+	got, err = e.OutputSpender(context.Background(), "btc", txHash, 1)
+	assert.Nil(t, err)
+	assert.Nil(t, got)
+
+	_, err = e.OutputSpender(context.Background(), "btc", txHash, 2)
+	assert.ErrorIs(t, err, sochain.ErrNotFound)
+}
+
+func Test_Transactions_OneTipRequest(t *testing.T) {
+	hashes := make([]string, 20)
+	for i := range hashes {
+		hashes[i] = fmt.Sprintf("%064d", i)
+	}
+
+	var mu sync.Mutex
+	requests := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		kind := r.URL.Path
+		if strings.HasPrefix(kind, "/tx/") {
+			kind = "/tx/" + strings.TrimPrefix(kind[len("/tx/")+64:], "/")
+		}
+		mu.Lock()
+		requests[kind]++
+		mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/blocks/tip/height":
+			w.Write([]byte("1000"))
+		case strings.HasSuffix(r.URL.Path, "/hex"):
+			w.Write([]byte("00"))
+		default:
+			w.Write([]byte(`{"txid":"a","status":{"confirmed":true,"block_height":991}}`))
+		}
+	}))
+	defer srv.Close()
+
+	e := NewEsplora(WithBaseURL("btc", srv.URL))
+	got, err := e.Transactions(context.Background(), "btc", hashes)
+	assert.Nil(t, err)
+
+	for _, r := range got {
+		if assert.Nil(t, r.Err) {
+			assert.Equal(t, 10, r.Tx.Data.Confirmations)
+		}
+	}
+	assert.Equal(t, map[string]int{"/blocks/tip/height": 1, "/tx/": 20, "/tx/hex": 20}, requests)
+}
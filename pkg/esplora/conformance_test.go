@@ -0,0 +1,35 @@
+package esplora_test
+
+import (
+	"net/http"
+	"sochain-client/pkg/esplora"
+	"sochain-client/pkg/sochain/sochaintest"
+	"testing"
+)
+
+func Test_Conformance(t *testing.T) {
+	srv := sochaintest.NewServer(t, map[string]sochaintest.Fixture{
+		"GET /btc/blocks/tip/height":                                                           {File: "testdata/tip_height.txt"},
+		"GET /btc/mempool":                                                                     {File: "testdata/mempool.json"},
+		"GET /btc/block-height/100000":                                                         {File: "testdata/block_height_100000.txt"},
+		"GET /btc/block/" + sochaintest.BlockHash:                                              {File: "testdata/block_100000.json"},
+		"GET /btc/block/" + sochaintest.BlockHash + "/txids":                                   {File: "testdata/block_100000_txids.json"},
+		"GET /btc/block/" + sochaintest.BlockHash + "/status":                                  {File: "testdata/block_100000_status.json"},
+		"GET /btc/tx/" + sochaintest.TxHash:                                                    {File: "testdata/tx_genesis.json"},
+		"GET /btc/tx/" + sochaintest.TxHash + "/hex":                                           {File: "testdata/tx_genesis_hex.txt"},
+		"GET /btc/tx/" + sochaintest.TxHash + "/outspends":                                     {File: "testdata/tx_genesis_outspends.json"},
+		"GET /btc/tx/" + sochaintest.MissingTxHash:                                             {Status: http.StatusNotFound, File: "testdata/tx_missing.txt"},
+		"GET /btc/address/" + sochaintest.Address:                                              {File: "testdata/address.json"},
+		"GET /btc/address/" + sochaintest.Address + "/txs/chain":                               {File: "testdata/address_txs_chain.json"},
+		"GET /btc/address/" + sochaintest.Address + "/txs/chain/" + sochaintest.DonationTxHash: {File: "testdata/address_txs_chain_after.json"},
+		"GET /btc/address/" + sochaintest.Address + "/utxo":                                    {File: "testdata/address_utxo.json"},
+		"POST /btc/tx":     {File: "testdata/broadcast_btc.txt"},
+		"POST /btctest/tx": {Status: http.StatusBadRequest, File: "testdata/broadcast_btctest.txt"},
+	})
+
+	sochaintest.Run(t, esplora.NewEsplora(
+		esplora.WithBaseURL("btc", srv.URL+"/btc"),
+		esplora.WithBaseURL("btctest", srv.URL+"/btctest"),
+		esplora.WithHTTPClient(srv.Client()),
+	))
+}
@@ -0,0 +1,53 @@
+package esplora
+
+import (
+	"net/http"
+	"sochain-client/pkg/network"
+	"strings"
+	"time"
+)
+
+// Option configures an Esplora client created by NewEsplora
+type Option func(*Esplora)
+
+// WithBaseURL sets the Esplora API url of networkID, e.g. "https://mempool.space/api" for btc
+func WithBaseURL(networkID, baseURL string) Option {
+	return func(e *Esplora) {
+		e.baseURLs[strings.ToLower(networkID)] = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithHTTPClient replaces the underlying http client. WithTimeout applies to a copy, c itself is never modified
+func WithHTTPClient(c *http.Client) Option {
+	return func(e *Esplora) {
+		e.Client = c
+	}
+}
+
+// WithTimeout limits the time of a single request including reading the response body
+func WithTimeout(d time.Duration) Option {
+	return func(e *Esplora) {
+		e.timeout = d
+	}
+}
+
+// WithUserAgent sets the User-Agent header of all requests
+func WithUserAgent(userAgent string) Option {
+	return func(e *Esplora) {
+		e.userAgent = userAgent
+	}
+}
+
+// WithNetworks validates network ids against r, defaults to network.DefaultRegistry
+func WithNetworks(r *network.Registry) Option {
+	return func(e *Esplora) {
+		e.networks = r
+	}
+}
+
+// WithConcurrency limits the parallel requests of Transactions to n, default sochain.DefaultConcurrency
+func WithConcurrency(n int) Option {
+	return func(e *Esplora) {
+		e.concurrency = n
+	}
+}
@@ -0,0 +1,195 @@
+package esplora
+
+import "sochain-client/pkg/sochain"
+
+type block struct {
+	ID                string  `json:"id"`
+	Height            int     `json:"height"`
+	Version           int     `json:"version"`
+	Timestamp         int     `json:"timestamp"`
+	TxCount           int     `json:"tx_count"`
+	Size              int     `json:"size"`
+	Weight            int     `json:"weight"`
+	MerkleRoot        string  `json:"merkle_root"`
+	PreviousBlockhash string  `json:"previousblockhash"`
+	Nonce             uint32  `json:"nonce"`
+	Bits              uint32  `json:"bits"`
+	Difficulty        float64 `json:"difficulty"`
+}
+
+type blockStatus struct {
+	InBestChain bool   `json:"in_best_chain"`
+	Height      int    `json:"height"`
+	NextBest    string `json:"next_best"`
+}
+
+type tx struct {
+	Txid     string   `json:"txid"`
+	Version  int      `json:"version"`
+	Locktime int      `json:"locktime"`
+	Vin      []vin    `json:"vin"`
+	Vout     []vout   `json:"vout"`
+	Size     int      `json:"size"`
+	Weight   int      `json:"weight"`
+	Fee      int64    `json:"fee"`
+	Status   txStatus `json:"status"`
+}
+
+type txStatus struct {
+	Confirmed   bool   `json:"confirmed"`
+	BlockHeight int    `json:"block_height"`
+	BlockHash   string `json:"block_hash"`
+	BlockTime   int    `json:"block_time"`
+}
+
+type vin struct {
+	Txid         string   `json:"txid"`
+	Vout         int      `json:"vout"`
+	Prevout      *vout    `json:"prevout"`
+	Scriptsig    string   `json:"scriptsig"`
+	ScriptsigAsm string   `json:"scriptsig_asm"`
+	Witness      []string `json:"witness"`
+	IsCoinbase   bool     `json:"is_coinbase"`
+	Sequence     uint32   `json:"sequence"`
+}
+
+type vout struct {
+	Scriptpubkey        string `json:"scriptpubkey"`
+	ScriptpubkeyAsm     string `json:"scriptpubkey_asm"`
+	ScriptpubkeyType    string `json:"scriptpubkey_type"`
+	ScriptpubkeyAddress string `json:"scriptpubkey_address"`
+	Value               int64  `json:"value"`
+}
+
+// outspend is the spending input of an output, Txid & Vin are only set if Spent
+type outspend struct {
+	Spent bool   `json:"spent"`
+	Txid  string `json:"txid"`
+	Vin   int    `json:"vin"`
+}
+
+// spentBy returns the spending input, nil while unspent
+func (o outspend) spentBy() *sochain.SpentBy {
+	if !o.Spent {
+		return nil
+	}
+
+	return &sochain.SpentBy{Txid: o.Txid, InputNo: o.Vin}
+}
+
+type addressInfo struct {
+	Address      string       `json:"address"`
+	ChainStats   addressStats `json:"chain_stats"`
+	MempoolStats addressStats `json:"mempool_stats"`
+}
+
+type addressStats struct {
+	FundedTxoCount int   `json:"funded_txo_count"`
+	FundedTxoSum   int64 `json:"funded_txo_sum"`
+	SpentTxoCount  int   `json:"spent_txo_count"`
+	SpentTxoSum    int64 `json:"spent_txo_sum"`
+	TxCount        int   `json:"tx_count"`
+}
+
+func (s addressStats) balance() int64 {
+	return s.FundedTxoSum - s.SpentTxoSum
+}
+
+type utxo struct {
+	Txid   string   `json:"txid"`
+	Vout   int      `json:"vout"`
+	Status txStatus `json:"status"`
+	Value  int64    `json:"value"`
+}
+
+type mempoolInfo struct {
+	Count int `json:"count"`
+}
+
+// Sochain names of the Esplora script types
+var scriptTypes = map[string]string{
+	"p2pk":      "pubkey",
+	"p2pkh":     "pubkeyhash",
+	"p2sh":      "scripthash",
+	"v0_p2wpkh": "witness_v0_keyhash",
+	"v0_p2wsh":  "witness_v0_scripthash",
+	"v1_p2tr":   "witness_v1_taproot",
+	"op_return": "nulldata",
+	"multisig":  "multisig",
+}
+
+func scriptType(t string) string {
+	if s, ok := scriptTypes[t]; ok {
+		return s
+	}
+
+	return "nonstandard"
+}
+
+// confirmations of a transaction or block at height, tip is the height of the best block
+func confirmations(confirmed bool, height, tip int) int {
+	if !confirmed || tip < height {
+		return 0
+	}
+
+	return tip - height + 1
+}
+
+// transaction converts t into a sochain transaction, the spending inputs of its outputs are unknown
+func (t tx) transaction(networkID string, tip int, txHex string) sochain.Transaction {
+	inputs := make(sochain.Inputs, len(t.Vin))
+	for i, in := range t.Vin {
+		input := sochain.Input{
+			InputNo:   i,
+			Value:     sochain.Amount(0),
+			ScriptAsm: in.ScriptsigAsm,
+			ScriptHex: in.Scriptsig,
+			Witness:   in.Witness,
+		}
+		if in.IsCoinbase {
+			input.Address = "coinbase"
+		} else {
+			input.ReceivedFrom = &sochain.ReceivedFrom{Txid: in.Txid, OutputNo: in.Vout}
+		}
+		if in.Prevout != nil {
+			input.Address = in.Prevout.ScriptpubkeyAddress
+			input.Value = sochain.Amount(in.Prevout.Value)
+		}
+		inputs[i] = input
+	}
+
+	var sent int64
+	outputs := make(sochain.Outputs, len(t.Vout))
+	for i, out := range t.Vout {
+		sent += out.Value
+		outputs[i] = sochain.Output{
+			OutputNo:  i,
+			Address:   out.ScriptpubkeyAddress,
+			Value:     sochain.Amount(out.Value),
+			Type:      scriptType(out.ScriptpubkeyType),
+			ScriptAsm: out.ScriptpubkeyAsm,
+			ScriptHex: out.Scriptpubkey,
+		}
+	}
+
+	return sochain.Transaction{
+		Status: "success",
+		Data: sochain.TransactionData{
+			Network:       networkID,
+			Txid:          t.Txid,
+			Blockhash:     t.Status.BlockHash,
+			BlockNo:       t.Status.BlockHeight,
+			Confirmations: confirmations(t.Status.Confirmed, t.Status.BlockHeight, tip),
+			Time:          t.Status.BlockTime,
+			Size:          t.Size,
+			Vsize:         (t.Weight + 3) / 4,
+			Version:       t.Version,
+			Locktime:      t.Locktime,
+			SentValue:     sochain.Amount(sent),
+			Fee:           sochain.Amount(t.Fee),
+			Inputs:        inputs,
+			Outputs:       outputs,
+			TxHex:         txHex,
+		},
+	}
+}
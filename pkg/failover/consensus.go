@@ -0,0 +1,192 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sochain-client/pkg/sochain"
+	"strconv"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// MismatchError is returned in consensus mode if two providers disagree on a resource, it matches
+// sochain.ErrNoConsensus
+type MismatchError struct {
+	Method    string
+	Providers [2]string
+	Field     string
+	Values    [2]string
+}
+
+func (m *MismatchError) Error() string {
+	return fmt.Sprintf("providers '%s' and '%s' disagree on %s of %s: '%s' != '%s'",
+		m.Providers[0], m.Providers[1], m.Field, m.Method, m.Values[0], m.Values[1])
+}
+
+func (m *MismatchError) Is(target error) bool {
+	return target == sochain.ErrNoConsensus
+}
+
+// compareFunc returns the first differing field of a & b and its values, an empty field if a & b agree
+type compareFunc func(a, b interface{}) (field, va, vb string)
+
+// answer is the response of a provider to a consensus request
+type answer struct {
+	p   *provider
+	v   interface{}
+	err error
+}
+
+// agree calls the first two candidates concurrently & compares their answers. Failing providers are replaced by the
+// remaining candidates in order, the request fails with sochain.ErrNoConsensus unless two providers answered
+func (f *Failover) agree(ctx context.Context, method string, call func(c sochain.Connector) (interface{}, error), compare compareFunc) (interface{}, error) {
+
+	candidates := f.candidates()
+	first := candidates
+	if len(first) > 2 {
+		first = first[:2]
+	}
+
+	results := make([]answer, len(first))
+	var wg sync.WaitGroup
+	for i := range first {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i].p = first[i]
+			results[i].v, results[i].err = call(first[i].Connector)
+		}(i)
+	}
+	wg.Wait()
+
+	var answers []answer
+	var err error
+	add := func(r answer) {
+		if !f.report(ctx, method, r.p, r.err) {
+			answers = append(answers, r)
+			return
+		}
+		// keep the most meaningful error, an unsupported request says little about the resource
+		if err == nil || !skipped(r.err) {
+			err = r.err
+		}
+	}
+	for _, r := range results {
+		add(r)
+	}
+	for _, p := range candidates[len(first):] {
+		if len(answers) == 2 || ctx.Err() != nil {
+			break
+		}
+		r := answer{p: p}
+		r.v, r.err = call(p.Connector)
+		add(r)
+	}
+	// a provider cut off by the caller didn't answer, there is nothing to compare
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	switch len(answers) {
+	case 0:
+		return nil, err
+	case 1:
+		if err == nil {
+			err = errors.New("no other provider available")
+		}
+		f.logger.Warn("single provider answered", zap.String("method", method), zap.String("provider", answers[0].p.Name), zap.Error(err))
+		return nil, fmt.Errorf("%w on %s: only provider '%s' answered: %v", sochain.ErrNoConsensus, method, answers[0].p.Name, err)
+	}
+
+	a, b := answers[0], answers[1]
+	if a.err != nil && b.err != nil {
+		return nil, a.err
+	}
+
+	m := &MismatchError{Method: method, Providers: [2]string{a.p.Name, b.p.Name}}
+	if a.err != nil || b.err != nil {
+		m.Field, m.Values = "existence", [2]string{existence(a.err), existence(b.err)}
+	} else {
+		m.Field, m.Values[0], m.Values[1] = compare(a.v, b.v)
+	}
+	if m.Field != "" {
+		f.logger.Error("providers disagree", zap.String("method", method), zap.Error(m))
+		return nil, m
+	}
+
+	return a.v, nil
+}
+
+func existence(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+
+	return "found"
+}
+
+func compareBlocks(a, b interface{}) (string, string, string) {
+	x, y := a.(*sochain.Block).Data, b.(*sochain.Block).Data
+
+	switch {
+	case x.Blockhash != y.Blockhash:
+		return "blockhash", x.Blockhash, y.Blockhash
+	case x.BlockNo != y.BlockNo:
+		return "block_no", strconv.Itoa(x.BlockNo), strconv.Itoa(y.BlockNo)
+	case x.Merkleroot != y.Merkleroot:
+		return "merkleroot", x.Merkleroot, y.Merkleroot
+	case len(x.Txs) != len(y.Txs):
+		return "txs", strconv.Itoa(len(x.Txs)), strconv.Itoa(len(y.Txs))
+	}
+
+	for i := range x.Txs {
+		if x.Txs[i] != y.Txs[i] {
+			return fmt.Sprintf("txs[%d]", i), x.Txs[i], y.Txs[i]
+		}
+	}
+
+	return "", "", ""
+}
+
+// compareTransactions compares the fields every provider agrees on once a transaction is known. The blockhash differs
+// while a transaction is confirmed & the fee is derived differently per provider, both are left out
+func compareTransactions(a, b interface{}) (string, string, string) {
+	x, y := a.(*sochain.Transaction).Data, b.(*sochain.Transaction).Data
+
+	switch {
+	case x.Txid != y.Txid:
+		return "txid", x.Txid, y.Txid
+	case x.SentValue != y.SentValue:
+		return "sent_value", x.SentValue.String(), y.SentValue.String()
+	case len(x.Inputs) != len(y.Inputs):
+		return "inputs", strconv.Itoa(len(x.Inputs)), strconv.Itoa(len(y.Inputs))
+	case len(x.Outputs) != len(y.Outputs):
+		return "outputs", strconv.Itoa(len(x.Outputs)), strconv.Itoa(len(y.Outputs))
+	}
+
+	for i := range x.Inputs {
+		if fx, fy := receivedFrom(x.Inputs[i].ReceivedFrom), receivedFrom(y.Inputs[i].ReceivedFrom); fx != fy {
+			return fmt.Sprintf("inputs[%d].received_from", i), fx, fy
+		}
+		if x.Inputs[i].Value != y.Inputs[i].Value {
+			return fmt.Sprintf("inputs[%d].value", i), x.Inputs[i].Value.String(), y.Inputs[i].Value.String()
+		}
+	}
+	for i := range x.Outputs {
+		if x.Outputs[i].Value != y.Outputs[i].Value {
+			return fmt.Sprintf("outputs[%d].value", i), x.Outputs[i].Value.String(), y.Outputs[i].Value.String()
+		}
+	}
+
+	return "", "", ""
+}
+
+func receivedFrom(r *sochain.ReceivedFrom) string {
+	if r == nil {
+		return "coinbase"
+	}
+
+	return fmt.Sprintf("%s:%d", r.Txid, r.OutputNo)
+}
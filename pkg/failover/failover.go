@@ -0,0 +1,363 @@
+// Package failover composes several sochain.Connector providers into one. Requests fail over to the next provider on
+// timeouts, 5xx responses & rate limiting, providers failing repeatedly are skipped for a cooldown window
+package failover
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sochain-client/pkg/sochain"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Provider is a named backend of a Failover connector
+type Provider struct {
+	Name      string
+	Connector sochain.Connector
+}
+
+// Health is the state of a provider
+type Health struct {
+	Name    string
+	Healthy bool
+	// consecutive failures since the last success or cooldown
+	Failures int
+	// end of the cooldown of an unhealthy provider
+	UnhealthyUntil time.Time
+}
+
+type provider struct {
+	Provider
+
+	mu             sync.Mutex
+	failures       int
+	unhealthyUntil time.Time
+}
+
+func (p *provider) available(now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return !now.Before(p.unhealthyUntil)
+}
+
+func (p *provider) success() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.failures = 0
+}
+
+// failure counts a failed request & reports whether it marked p unhealthy
+func (p *provider) failure(now time.Time, threshold int, cooldown time.Duration) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.failures++
+	if p.failures < threshold {
+		return false
+	}
+
+	p.failures = 0
+	p.unhealthyUntil = now.Add(cooldown)
+	return true
+}
+
+var _ sochain.Connector = (*Failover)(nil)
+
+type Failover struct {
+	providers []*provider
+	cooldown  time.Duration
+	threshold int
+	consensus bool
+	// parallel requests of Transactions
+	concurrency int
+	logger      *zap.Logger
+	now         func() time.Time
+}
+
+// NewFailover queries providers in the given order
+func NewFailover(providers []Provider, opts ...Option) *Failover {
+	f := &Failover{
+		cooldown:    30 * time.Second,
+		threshold:   3,
+		concurrency: sochain.DefaultConcurrency,
+		logger:      zap.NewNop(),
+		now:         time.Now,
+	}
+	for _, p := range providers {
+		f.providers = append(f.providers, &provider{Provider: p})
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f
+}
+
+// Health reports the state of all providers in order
+func (f *Failover) Health() []Health {
+	now := f.now()
+
+	r := make([]Health, len(f.providers))
+	for i, p := range f.providers {
+		p.mu.Lock()
+		r[i] = Health{
+			Name:           p.Name,
+			Healthy:        !now.Before(p.unhealthyUntil),
+			Failures:       p.failures,
+			UnhealthyUntil: p.unhealthyUntil,
+		}
+		p.mu.Unlock()
+	}
+
+	return r
+}
+
+func (f *Failover) NetworkInfo(ctx context.Context, networkID string) (*sochain.NetworkInfo, error) {
+	var info *sochain.NetworkInfo
+	err := f.do(ctx, "NetworkInfo", func(c sochain.Connector) (err error) {
+		info, err = c.NetworkInfo(ctx, networkID)
+		return err
+	})
+
+	return info, err
+}
+
+func (f *Failover) BlockHeight(ctx context.Context, networkID string, height int) (*sochain.Block, error) {
+	var b *sochain.Block
+	err := f.do(ctx, "BlockHeight", func(c sochain.Connector) (err error) {
+		b, err = c.BlockHeight(ctx, networkID, height)
+		return err
+	})
+
+	return b, err
+}
+
+func (f *Failover) BlockHash(ctx context.Context, networkID, blockHash string) (*sochain.Block, error) {
+	call := func(c sochain.Connector) (interface{}, error) {
+		return c.BlockHash(ctx, networkID, blockHash)
+	}
+
+	if f.consensus {
+		v, err := f.agree(ctx, "BlockHash", call, compareBlocks)
+		if err != nil {
+			return nil, err
+		}
+		return v.(*sochain.Block), nil
+	}
+
+	var b *sochain.Block
+	err := f.do(ctx, "BlockHash", func(c sochain.Connector) error {
+		v, err := call(c)
+		if err == nil {
+			b = v.(*sochain.Block)
+		}
+		return err
+	})
+
+	return b, err
+}
+
+func (f *Failover) Transaction(ctx context.Context, networkID, txHash string) (*sochain.Transaction, error) {
+	call := func(c sochain.Connector) (interface{}, error) {
+		return c.Transaction(ctx, networkID, txHash)
+	}
+
+	if f.consensus {
+		v, err := f.agree(ctx, "Transaction", call, compareTransactions)
+		if err != nil {
+			return nil, err
+		}
+		return v.(*sochain.Transaction), nil
+	}
+
+	var tx *sochain.Transaction
+	err := f.do(ctx, "Transaction", func(c sochain.Connector) error {
+		v, err := call(c)
+		if err == nil {
+			tx = v.(*sochain.Transaction)
+		}
+		return err
+	})
+
+	return tx, err
+}
+
+// Transactions fails over & checks consensus per hash
+func (f *Failover) Transactions(ctx context.Context, networkID string, hashes []string) ([]sochain.TxResult, error) {
+	return sochain.FetchTransactions(ctx, hashes, f.concurrency, func(ctx context.Context, hash string) (*sochain.Transaction, error) {
+		return f.Transaction(ctx, networkID, hash)
+	}), nil
+}
+
+func (f *Failover) AddressBalance(ctx context.Context, networkID, address string) (*sochain.AddressBalance, error) {
+	var b *sochain.AddressBalance
+	err := f.do(ctx, "AddressBalance", func(c sochain.Connector) (err error) {
+		b, err = c.AddressBalance(ctx, networkID, address)
+		return err
+	})
+
+	return b, err
+}
+
+func (f *Failover) ReceivedTransactions(ctx context.Context, networkID, address, afterTxid string) (*sochain.ReceivedTxs, error) {
+	var txs *sochain.ReceivedTxs
+	err := f.do(ctx, "ReceivedTransactions", func(c sochain.Connector) (err error) {
+		txs, err = c.ReceivedTransactions(ctx, networkID, address, afterTxid)
+		return err
+	})
+
+	return txs, err
+}
+
+func (f *Failover) OutputSpender(ctx context.Context, networkID, txHash string, outputNo int) (*sochain.SpentBy, error) {
+	var spent *sochain.SpentBy
+	err := f.do(ctx, "OutputSpender", func(c sochain.Connector) (err error) {
+		spent, err = c.OutputSpender(ctx, networkID, txHash, outputNo)
+		return err
+	})
+
+	return spent, err
+}
+
+func (f *Failover) SpentTransactions(ctx context.Context, networkID, address, afterTxid string) (*sochain.SpentTxs, error) {
+	var txs *sochain.SpentTxs
+	err := f.do(ctx, "SpentTransactions", func(c sochain.Connector) (err error) {
+		txs, err = c.SpentTransactions(ctx, networkID, address, afterTxid)
+		return err
+	})
+
+	return txs, err
+}
+
+func (f *Failover) UnspentOutputs(ctx context.Context, networkID, address, afterTxid string) (*sochain.UnspentOutputs, error) {
+	var outputs *sochain.UnspentOutputs
+	err := f.do(ctx, "UnspentOutputs", func(c sochain.Connector) (err error) {
+		outputs, err = c.UnspentOutputs(ctx, networkID, address, afterTxid)
+		return err
+	})
+
+	return outputs, err
+}
+
+// BroadcastTransaction fails over like every other request, rebroadcasting a transaction is harmless.
+// Transactions rejected by a provider are never passed on
+func (f *Failover) BroadcastTransaction(ctx context.Context, networkID, txHex string) (*sochain.BroadcastTx, error) {
+	var tx *sochain.BroadcastTx
+	err := f.do(ctx, "BroadcastTransaction", func(c sochain.Connector) (err error) {
+		tx, err = c.BroadcastTransaction(ctx, networkID, txHex)
+		return err
+	})
+
+	return tx, err
+}
+
+// candidates are the available providers in order, all providers if none is available
+func (f *Failover) candidates() []*provider {
+	now := f.now()
+
+	r := make([]*provider, 0, len(f.providers))
+	for _, p := range f.providers {
+		if p.available(now) {
+			r = append(r, p)
+		}
+	}
+	if len(r) == 0 {
+		return f.providers
+	}
+
+	return r
+}
+
+// do calls the candidates in order until one succeeds or fails with an error that isn't worth a failover
+func (f *Failover) do(ctx context.Context, method string, call func(c sochain.Connector) error) error {
+	return f.try(ctx, method, f.candidates(), call)
+}
+
+func (f *Failover) try(ctx context.Context, method string, providers []*provider, call func(c sochain.Connector) error) error {
+
+	var err error
+	for _, p := range providers {
+		pErr := call(p.Connector)
+		if !f.report(ctx, method, p, pErr) {
+			return pErr
+		}
+
+		// keep the most meaningful error, an unsupported request says little about the resource
+		if err == nil || !skipped(pErr) {
+			err = pErr
+		}
+	}
+
+	return err
+}
+
+// report tracks the health of p & reports whether the request fails over to the next provider
+func (f *Failover) report(ctx context.Context, method string, p *provider, err error) bool {
+	switch classify(ctx, err) {
+	case outcomeFailure:
+		f.logger.Warn("provider failed, failing over", zap.String("provider", p.Name), zap.String("method", method), zap.Error(err))
+		if p.failure(f.now(), f.threshold, f.cooldown) {
+			f.logger.Warn("provider unhealthy", zap.String("provider", p.Name), zap.Duration("cooldown", f.cooldown))
+		}
+		return true
+	case outcomeSkip:
+		return true
+	case outcomeCancelled:
+		return false
+	default:
+		p.success()
+		return false
+	}
+}
+
+type outcome int
+
+const (
+	// the provider answered, either with the resource or an error of the request
+	outcomeDone outcome = iota
+	// the provider failed, the request fails over
+	outcomeFailure
+	// the provider doesn't serve the request, it fails over without affecting the health
+	outcomeSkip
+	// the caller gave up, the request ends without affecting the health
+	outcomeCancelled
+)
+
+func classify(ctx context.Context, err error) outcome {
+	if err == nil {
+		return outcomeDone
+	}
+	if ctx.Err() != nil {
+		return outcomeCancelled
+	}
+
+	if skipped(err) {
+		return outcomeSkip
+	}
+
+	var bErr *sochain.BroadcastError
+	if errors.As(err, &bErr) {
+		return outcomeDone
+	}
+
+	var cErr *sochain.ClientError
+	if errors.As(err, &cErr) {
+		if cErr.Code() >= http.StatusInternalServerError || errors.Is(err, sochain.ErrRateLimited) {
+			return outcomeFailure
+		}
+		return outcomeDone
+	}
+
+	// network errors, timeouts of the provider & malformed responses
+	return outcomeFailure
+}
+
+func skipped(err error) bool {
+	return errors.Is(err, sochain.ErrUnsupported) || errors.Is(err, sochain.ErrInvalidNetwork)
+}
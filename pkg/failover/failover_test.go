@@ -0,0 +1,264 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sochain-client/pkg/sochain"
+	mock_client "sochain-client/pkg/sochain/mock"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const txHash = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+func newTestFailover(t *testing.T, opts ...Option) (*Failover, *mock_client.MockConnector, *mock_client.MockConnector) {
+	ctrl := gomock.NewController(t)
+	primary, secondary := mock_client.NewMockConnector(ctrl), mock_client.NewMockConnector(ctrl)
+
+	f := NewFailover([]Provider{{Name: "primary", Connector: primary}, {Name: "secondary", Connector: secondary}}, opts...)
+	return f, primary, secondary
+}
+
+func testTx(sentValue sochain.Amount) *sochain.Transaction {
+	return &sochain.Transaction{Data: sochain.TransactionData{
+		Txid:      txHash,
+		SentValue: sentValue,
+		Inputs:    sochain.Inputs{{}},
+		Outputs:   sochain.Outputs{{Value: sentValue}},
+	}}
+}
+
+// confirmedTx is testTx as seen by a provider which already knows its block & derives a fee
+func confirmedTx(sentValue sochain.Amount) *sochain.Transaction {
+	tx := testTx(sentValue)
+	tx.Data.Blockhash = "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+	tx.Data.Fee = 1000
+	return tx
+}
+
+// spendingTx is testTx with an input spending another output
+func spendingTx(sentValue sochain.Amount) *sochain.Transaction {
+	tx := testTx(sentValue)
+	tx.Data.Inputs[0].ReceivedFrom = &sochain.ReceivedFrom{Txid: txHash}
+	return tx
+}
+
+func Test_Failover(t *testing.T) {
+	tests := []struct {
+		title         string
+		primaryErr    error
+		wantSecondary bool
+		wantFailures  int
+	}{
+		{title: "5xx", primaryErr: sochain.NewClientErr(errors.New("some"), http.StatusServiceUnavailable), wantSecondary: true, wantFailures: 1},
+		{title: "rate limited", primaryErr: &sochain.RetryError{Attempts: 3, Err: sochain.NewClientErr(errors.New("some"), http.StatusTooManyRequests)}, wantSecondary: true, wantFailures: 1},
+		{title: "timeout", primaryErr: context.DeadlineExceeded, wantSecondary: true, wantFailures: 1},
+		{title: "unsupported", primaryErr: sochain.NewClientErr(errors.New("some"), http.StatusNotImplemented), wantSecondary: true},
+		{title: "network not served", primaryErr: sochain.NewValidationErr(sochain.ErrInvalidNetwork, "network", errors.New("some")), wantSecondary: true},
+		{title: "not found", primaryErr: sochain.NewClientErr(errors.New("some"), http.StatusNotFound)},
+		{title: "rejected", primaryErr: sochain.NewBroadcastErr("dust")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			f, primary, secondary := newTestFailover(t)
+
+			primary.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(nil, tt.primaryErr)
+			if tt.wantSecondary {
+				secondary.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(testTx(100000000), nil)
+			}
+
+			got, err := f.Transaction(context.Background(), "btc", txHash)
+			if tt.wantSecondary {
+				assert.Nil(t, err)
+				assert.Equal(t, testTx(100000000), got)
+			} else {
+				assert.Equal(t, tt.primaryErr, err)
+			}
+			assert.Equal(t, tt.wantFailures, f.Health()[0].Failures)
+		})
+	}
+}
+
+func Test_Failover_Transactions(t *testing.T) {
+	f, primary, secondary := newTestFailover(t, WithConcurrency(1))
+
+	missing := "0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098"
+	notFound := sochain.NewClientErr(errors.New("some"), http.StatusNotFound)
+
+	// every hash fails over on its own
+	primary.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(nil, sochain.NewClientErr(errors.New("some"), http.StatusServiceUnavailable))
+	secondary.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(testTx(100000000), nil)
+	primary.EXPECT().Transaction(gomock.Any(), "btc", missing).Return(nil, notFound)
+
+	got, err := f.Transactions(context.Background(), "btc", []string{txHash, missing})
+	assert.Nil(t, err)
+	assert.Equal(t, []sochain.TxResult{{Hash: txHash, Tx: testTx(100000000)}, {Hash: missing, Err: notFound}}, got)
+}
+
+func Test_Failover_AllFailed(t *testing.T) {
+	f, primary, secondary := newTestFailover(t)
+
+	primaryErr := sochain.NewClientErr(errors.New("some"), http.StatusBadGateway)
+	primary.EXPECT().NetworkInfo(gomock.Any(), "doge").Return(nil, primaryErr)
+	secondary.EXPECT().NetworkInfo(gomock.Any(), "doge").Return(nil, sochain.NewValidationErr(sochain.ErrInvalidNetwork, "network", errors.New("some")))
+
+	_, err := f.NetworkInfo(context.Background(), "doge")
+	assert.Equal(t, primaryErr, err)
+}
+
+func Test_Failover_ContextDone(t *testing.T) {
+	f, primary, secondary := newTestFailover(t)
+
+	primary.EXPECT().BlockHeight(gomock.Any(), "btc", 1).Return(nil, sochain.NewClientErr(errors.New("some"), http.StatusServiceUnavailable))
+	secondary.EXPECT().BlockHeight(gomock.Any(), "btc", 1).Return(&sochain.Block{}, nil)
+	_, err := f.BlockHeight(context.Background(), "btc", 1)
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	primary.EXPECT().BlockHeight(gomock.Any(), "btc", 1).Return(nil, context.Canceled)
+
+	_, err = f.BlockHeight(ctx, "btc", 1)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// a cancelled caller neither resets nor adds to the failures
+	assert.Equal(t, 1, f.Health()[0].Failures)
+}
+
+func Test_Failover_Cooldown(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	f, primary, secondary := newTestFailover(t, WithFailureThreshold(2), WithCooldown(time.Minute))
+	f.now = func() time.Time { return now }
+
+	unavailable := sochain.NewClientErr(errors.New("some"), http.StatusServiceUnavailable)
+	primary.EXPECT().AddressBalance(gomock.Any(), "btc", "address").Return(nil, unavailable).Times(2)
+	secondary.EXPECT().AddressBalance(gomock.Any(), "btc", "address").Return(&sochain.AddressBalance{}, nil).Times(3)
+
+	for i := 0; i < 3; i++ {
+		_, err := f.AddressBalance(context.Background(), "btc", "address")
+		assert.Nil(t, err)
+	}
+
+	h := f.Health()[0]
+	assert.False(t, h.Healthy)
+	assert.Equal(t, now.Add(time.Minute), h.UnhealthyUntil)
+
+	// back after the cooldown
+	now = now.Add(time.Minute)
+	primary.EXPECT().AddressBalance(gomock.Any(), "btc", "address").Return(&sochain.AddressBalance{}, nil)
+
+	_, err := f.AddressBalance(context.Background(), "btc", "address")
+	assert.Nil(t, err)
+	assert.True(t, f.Health()[0].Healthy)
+}
+
+func Test_Failover_AllUnhealthy(t *testing.T) {
+	f, primary, secondary := newTestFailover(t, WithFailureThreshold(1))
+
+	unavailable := sochain.NewClientErr(errors.New("some"), http.StatusServiceUnavailable)
+	primary.EXPECT().UnspentOutputs(gomock.Any(), "btc", "address", "").Return(nil, unavailable)
+	secondary.EXPECT().UnspentOutputs(gomock.Any(), "btc", "address", "").Return(nil, unavailable)
+
+	_, err := f.UnspentOutputs(context.Background(), "btc", "address", "")
+	assert.Equal(t, unavailable, err)
+
+	// unhealthy providers are still tried if none is left
+	primary.EXPECT().UnspentOutputs(gomock.Any(), "btc", "address", "").Return(&sochain.UnspentOutputs{}, nil)
+
+	_, err = f.UnspentOutputs(context.Background(), "btc", "address", "")
+	assert.Nil(t, err)
+}
+
+func Test_Consensus(t *testing.T) {
+	notFound := sochain.NewClientErr(errors.New("some"), http.StatusNotFound)
+	unavailable := sochain.NewClientErr(errors.New("some"), http.StatusServiceUnavailable)
+
+	tests := []struct {
+		title        string
+		primary      *sochain.Transaction
+		primaryErr   error
+		secondary    *sochain.Transaction
+		secondaryErr error
+		want         *sochain.Transaction
+		wantErr      error
+		wantMismatch string
+	}{
+		{title: "agree", primary: testTx(100000000), secondary: testTx(100000000), want: testTx(100000000)},
+		{title: "value mismatch", primary: testTx(100000000), secondary: testTx(110000000), wantMismatch: "sent_value"},
+		{title: "confirmation & fee differ", primary: testTx(100000000), secondary: confirmedTx(100000000), want: testTx(100000000)},
+		{title: "input mismatch", primary: testTx(100000000), secondary: spendingTx(100000000), wantMismatch: "inputs[0].received_from"},
+		{title: "existence mismatch", primary: testTx(100000000), secondaryErr: notFound, wantMismatch: "existence"},
+		{title: "both not found", primaryErr: notFound, secondaryErr: notFound, wantErr: notFound},
+		{title: "primary failed", primaryErr: unavailable, secondary: testTx(100000000), wantErr: sochain.ErrNoConsensus},
+		{title: "both failed", primaryErr: unavailable, secondaryErr: unavailable, wantErr: unavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			f, primary, secondary := newTestFailover(t, WithConsensus(true))
+
+			primary.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(tt.primary, tt.primaryErr)
+			secondary.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(tt.secondary, tt.secondaryErr)
+
+			got, err := f.Transaction(context.Background(), "btc", txHash)
+			if tt.wantMismatch != "" {
+				var m *MismatchError
+				if assert.True(t, errors.As(err, &m)) {
+					assert.Equal(t, tt.wantMismatch, m.Field)
+					assert.Equal(t, [2]string{"primary", "secondary"}, m.Providers)
+				}
+				assert.ErrorIs(t, err, sochain.ErrNoConsensus)
+				assert.Equal(t, http.StatusBadGateway, sochain.StatusCode(err))
+				return
+			}
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_Consensus_ThirdProvider(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	primary, secondary, third := mock_client.NewMockConnector(ctrl), mock_client.NewMockConnector(ctrl), mock_client.NewMockConnector(ctrl)
+	f := NewFailover([]Provider{{Name: "primary", Connector: primary}, {Name: "secondary", Connector: secondary}, {Name: "third", Connector: third}}, WithConsensus(true))
+
+	unavailable := sochain.NewClientErr(errors.New("some"), http.StatusServiceUnavailable)
+	primary.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(testTx(100000000), nil)
+	secondary.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(nil, unavailable)
+	third.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(testTx(100000000), nil)
+
+	got, err := f.Transaction(context.Background(), "btc", txHash)
+	assert.Nil(t, err)
+	assert.Equal(t, testTx(100000000), got)
+
+	// a single provider is no consensus
+	f = NewFailover([]Provider{{Name: "primary", Connector: primary}}, WithConsensus(true))
+	primary.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(testTx(100000000), nil)
+
+	_, err = f.Transaction(context.Background(), "btc", txHash)
+	assert.ErrorIs(t, err, sochain.ErrNoConsensus)
+}
+
+func Test_Consensus_Blocks(t *testing.T) {
+	f, primary, secondary := newTestFailover(t, WithConsensus(true))
+
+	hash := "000000000003ba27aa200b1cecaad478d2b00432346c3f1f3986da1afd33e506"
+	primary.EXPECT().BlockHash(gomock.Any(), "btc", hash).Return(&sochain.Block{Data: sochain.BlockData{Blockhash: hash, Txs: []string{"a", "b"}}}, nil)
+	secondary.EXPECT().BlockHash(gomock.Any(), "btc", hash).Return(&sochain.Block{Data: sochain.BlockData{Blockhash: hash, Txs: []string{"a", "c"}}}, nil)
+
+	_, err := f.BlockHash(context.Background(), "btc", hash)
+
+	var m *MismatchError
+	if assert.True(t, errors.As(err, &m)) {
+		assert.Equal(t, "txs[1]", m.Field)
+		assert.Equal(t, [2]string{"b", "c"}, m.Values)
+	}
+}
@@ -0,0 +1,46 @@
+package failover
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Option configures a Failover connector created by NewFailover
+type Option func(*Failover)
+
+// WithCooldown sets the time an unhealthy provider is skipped, default 30s
+func WithCooldown(d time.Duration) Option {
+	return func(f *Failover) {
+		f.cooldown = d
+	}
+}
+
+// WithFailureThreshold sets the number of consecutive failures marking a provider unhealthy, default 3
+func WithFailureThreshold(n int) Option {
+	return func(f *Failover) {
+		f.threshold = n
+	}
+}
+
+// WithConsensus queries two providers for BlockHash & Transaction and fails with *MismatchError if their answers differ.
+// Requests fewer than two providers answered fail with sochain.ErrNoConsensus
+func WithConsensus(enabled bool) Option {
+	return func(f *Failover) {
+		f.consensus = enabled
+	}
+}
+
+// WithConcurrency limits the parallel requests of Transactions to n, default sochain.DefaultConcurrency
+func WithConcurrency(n int) Option {
+	return func(f *Failover) {
+		f.concurrency = n
+	}
+}
+
+// WithLogger logs failovers, health changes & mismatches to l
+func WithLogger(l *zap.Logger) Option {
+	return func(f *Failover) {
+		f.logger = l
+	}
+}
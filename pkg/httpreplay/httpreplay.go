@@ -0,0 +1,270 @@
+// Package httpreplay records HTTP interactions into versioned fixture files & replays them, so that clients are tested
+// offline against real upstream responses. Recordings are scrubbed of secrets before they are written, replays are
+// strict: requests matching no recorded interaction fail
+package httpreplay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Version of the fixture format, fixtures of other versions have to be recorded again
+const Version = 1
+
+// ErrUnmatched is returned for replayed requests without a recorded interaction
+var ErrUnmatched = errors.New("no recorded interaction matches request")
+
+type Mode int
+
+const (
+	// Replay serves the recorded interactions of a fixture & never reaches the network
+	Replay Mode = iota
+	// Record sends requests upstream & records them into a fixture
+	Record
+)
+
+// Fixture is the content of a fixture file
+type Fixture struct {
+	Version int `json:"version"`
+	// time of the recording, nil for fixtures written by hand
+	Recorded *time.Time `json:"recorded,omitempty"`
+	// origin of a fixture written by hand
+	Note         string        `json:"note,omitempty"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request & its response
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body"`
+}
+
+var _ http.RoundTripper = (*Transport)(nil)
+
+// Transport records or replays the fixture at path
+type Transport struct {
+	path string
+	mode Mode
+	// upstream of recordings
+	next         http.RoundTripper
+	scrubHeaders map[string]bool
+	scrubQuery   map[string]bool
+	scrubbers    []func(*Interaction)
+
+	mu        sync.Mutex
+	fixture   Fixture
+	used      []bool
+	unmatched []string
+}
+
+// New records into path or replays path depending on mode. Replays fail if path is missing or of another Version
+func New(path string, mode Mode, opts ...Option) (*Transport, error) {
+	t := &Transport{
+		path:         path,
+		mode:         mode,
+		next:         http.DefaultTransport,
+		scrubHeaders: map[string]bool{},
+		scrubQuery:   map[string]bool{},
+		fixture:      Fixture{Version: Version},
+	}
+	WithScrubHeaders(defaultScrubHeaders...)(t)
+	WithScrubQuery(defaultScrubQuery...)(t)
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	if mode == Record {
+		return t, nil
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no fixture at %s, record it with %s=record: %w", path, EnvMode, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, &t.fixture); err != nil {
+		return nil, fmt.Errorf("unable to decode fixture %s: %w", path, err)
+	}
+	if t.fixture.Version != Version {
+		return nil, fmt.Errorf("fixture %s has version %d, want %d: record it again with %s=record", path, t.fixture.Version, Version, EnvMode)
+	}
+	t.used = make([]bool, len(t.fixture.Interactions))
+
+	return t, nil
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.mode == Record {
+		return t.record(req, body)
+	}
+	return t.replay(req, body)
+}
+
+// Save writes the recorded interactions to the fixture file, replays have nothing to save
+func (t *Transport) Save() error {
+	if t.mode != Record {
+		return nil
+	}
+
+	t.mu.Lock()
+	f := t.fixture
+	recorded := time.Now().UTC().Truncate(time.Second)
+	f.Recorded = &recorded
+	b, err := json.MarshalIndent(f, "", "  ")
+	t.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(t.path, append(b, '\n'), 0644)
+}
+
+// Unused returns the replayed interactions which were never requested
+func (t *Transport) Unused() []Interaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var unused []Interaction
+	for i, used := range t.used {
+		if !used {
+			unused = append(unused, t.fixture.Interactions[i])
+		}
+	}
+	return unused
+}
+
+// Unmatched returns the replayed requests without a recorded interaction, e.g. "GET https://sochain.com/api/v2/get_info/BTC"
+func (t *Transport) Unmatched() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]string(nil), t.unmatched...)
+}
+
+func (t *Transport) record(req *http.Request, body []byte) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	i := Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: req.Header.Clone(),
+			Body:   string(body),
+		},
+		Response: Response{
+			Status: resp.StatusCode,
+			Header: resp.Header.Clone(),
+			Body:   string(respBody),
+		},
+	}
+	t.scrub(&i)
+
+	t.mu.Lock()
+	t.fixture.Interactions = append(t.fixture.Interactions, i)
+	t.mu.Unlock()
+
+	return resp, nil
+}
+
+// replay serves the first unused interaction matching method, URL & body of req, scrubbed like recordings are
+func (t *Transport) replay(req *http.Request, body []byte) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	want := Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: req.Header.Clone(),
+			Body:   string(body),
+		},
+	}
+	t.scrub(&want)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for n, i := range t.fixture.Interactions {
+		if t.used[n] || i.Request.Method != want.Request.Method || i.Request.URL != want.Request.URL || i.Request.Body != want.Request.Body {
+			continue
+		}
+		t.used[n] = true
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.Response.Status, http.StatusText(i.Response.Status)),
+			StatusCode:    i.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        i.Response.Header.Clone(),
+			Body:          ioutil.NopCloser(strings.NewReader(i.Response.Body)),
+			ContentLength: int64(len(i.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	unmatched := req.Method + " " + want.Request.URL
+	t.unmatched = append(t.unmatched, unmatched)
+	return nil, fmt.Errorf("%w: %s", ErrUnmatched, unmatched)
+}
+
+// readBody reads the body of req & leaves it readable for the upstream
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	b, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	return b, nil
+}
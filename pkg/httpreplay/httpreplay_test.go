@@ -0,0 +1,175 @@
+package httpreplay
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newUpstream(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret-session")
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+		w.Write([]byte(`{"path":"` + r.URL.Path + `","body":"` + string(body) + `"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// do sends a request through rt, returning its status & body
+func do(t *testing.T, rt http.RoundTripper, method, url, body string) (int, string, error) {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	assert.Nil(t, err)
+	req.Header.Set("Authorization", "Bearer secret-token")
+
+	resp, err := (&http.Client{Transport: rt}).Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	return resp.StatusCode, string(b), nil
+}
+
+func Test_RecordReplay(t *testing.T) {
+	srv := newUpstream(t)
+	path := filepath.Join(t.TempDir(), "fixtures", "record.json")
+	withSecrets := strings.Replace(srv.URL, "http://", "http://user:secret-password@", 1)
+
+	rec, err := New(path, Record)
+	assert.Nil(t, err)
+
+	status, body, err := do(t, rec, "GET", withSecrets+"/info?network=btc&api_key=secret-key", "")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `{"path":"/info","body":""}`, body)
+
+	status, _, err = do(t, rec, "POST", srv.URL+"/send", "tx")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, status)
+	assert.Nil(t, rec.Save())
+
+	// secrets never reach the fixture
+	b, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	for _, secret := range []string{"secret-password", "secret-key", "secret-token", "secret-session"} {
+		assert.NotContains(t, string(b), secret)
+	}
+	assert.Contains(t, string(b), `"version": 1`)
+	assert.Contains(t, string(b), `"recorded": `)
+
+	// replays are served offline & match requests by their scrubbed URL
+	srv.Close()
+	rep, err := New(path, Replay)
+	assert.Nil(t, err)
+
+	status, body, err = do(t, rep, "GET", withSecrets+"/info?network=btc&api_key=other-key", "")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `{"path":"/info","body":""}`, body)
+
+	status, body, err = do(t, rep, "POST", srv.URL+"/send", "tx")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, `{"path":"/send","body":"tx"}`, body)
+
+	assert.Empty(t, rep.Unused())
+	assert.Empty(t, rep.Unmatched())
+}
+
+func Test_Replay_Strict(t *testing.T) {
+	srv := newUpstream(t)
+	path := filepath.Join(t.TempDir(), "strict.json")
+
+	rec, err := New(path, Record)
+	assert.Nil(t, err)
+	_, _, err = do(t, rec, "GET", srv.URL+"/a", "")
+	assert.Nil(t, err)
+	_, _, err = do(t, rec, "POST", srv.URL+"/b", "tx")
+	assert.Nil(t, err)
+	assert.Nil(t, rec.Save())
+
+	rep, err := New(path, Replay)
+	assert.Nil(t, err)
+
+	_, _, err = do(t, rep, "GET", srv.URL+"/a", "")
+	assert.Nil(t, err)
+	// every interaction is served once
+	_, _, err = do(t, rep, "GET", srv.URL+"/a", "")
+	assert.True(t, errors.Is(err, ErrUnmatched))
+	// bodies have to match
+	_, _, err = do(t, rep, "POST", srv.URL+"/b", "other")
+	assert.True(t, errors.Is(err, ErrUnmatched))
+
+	assert.Equal(t, []string{"GET " + srv.URL + "/a", "POST " + srv.URL + "/b"}, rep.Unmatched())
+	if assert.Len(t, rep.Unused(), 1) {
+		assert.Equal(t, srv.URL+"/b", rep.Unused()[0].Request.URL)
+	}
+}
+
+func Test_Scrubber(t *testing.T) {
+	srv := newUpstream(t)
+	path := filepath.Join(t.TempDir(), "scrubber.json")
+	scrubBody := WithScrubber(func(i *Interaction) {
+		i.Request.Body = strings.Replace(i.Request.Body, "secret-body", redacted, -1)
+		i.Response.Body = strings.Replace(i.Response.Body, "secret-body", redacted, -1)
+	})
+
+	rec, err := New(path, Record, scrubBody, WithScrubHeaders("Content-Type"))
+	assert.Nil(t, err)
+	_, _, err = do(t, rec, "POST", srv.URL+"/send", "secret-body")
+	assert.Nil(t, err)
+	assert.Nil(t, rec.Save())
+
+	b, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(b), "secret-body")
+	assert.NotContains(t, string(b), "application/json")
+
+	// replayed requests are scrubbed before they are matched
+	rep, err := New(path, Replay, scrubBody)
+	assert.Nil(t, err)
+	_, body, err := do(t, rep, "POST", srv.URL+"/send", "secret-body")
+	assert.Nil(t, err)
+	assert.Equal(t, `{"path":"/send","body":"REDACTED"}`, body)
+}
+
+func Test_Replay_Error_Fixture(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := New(filepath.Join(dir, "missing.json"), Replay)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	path := filepath.Join(dir, "old.json")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"version":0,"interactions":[]}`), 0644))
+	_, err = New(path, Replay)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "version 0")
+}
+
+func Test_ReplayForTest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fault.json")
+	fixture := `{"version":1,"note":"written by hand","interactions":[{"request":{"method":"GET","url":"http://upstream/a"},"response":{"status":500,"body":""}}]}`
+	assert.Nil(t, ioutil.WriteFile(path, []byte(fixture), 0644))
+
+	// fixtures written by hand are replayed even while recording
+	t.Setenv(EnvMode, "record")
+	status, _, err := do(t, ReplayForTest(t, path), "GET", "http://upstream/a", "")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusInternalServerError, status)
+}
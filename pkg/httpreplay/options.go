@@ -0,0 +1,39 @@
+package httpreplay
+
+import "net/http"
+
+// Option configures a Transport created by New
+type Option func(*Transport)
+
+// WithTransport sends recorded requests through rt, default http.DefaultTransport
+func WithTransport(rt http.RoundTripper) Option {
+	return func(t *Transport) {
+		t.next = rt
+	}
+}
+
+// WithScrubHeaders redacts the request & response headers called names in addition to Authorization, Cookie & alike
+func WithScrubHeaders(names ...string) Option {
+	return func(t *Transport) {
+		for _, name := range names {
+			t.scrubHeaders[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+}
+
+// WithScrubQuery redacts the query parameters called params in addition to api_key, token & alike
+func WithScrubQuery(params ...string) Option {
+	return func(t *Transport) {
+		for _, param := range params {
+			t.scrubQuery[param] = true
+		}
+	}
+}
+
+// WithScrubber redacts recorded interactions by f after the headers & query parameters, e.g. secrets in bodies.
+// Replayed requests pass f as well before they are matched, their response is empty then
+func WithScrubber(f func(i *Interaction)) Option {
+	return func(t *Transport) {
+		t.scrubbers = append(t.scrubbers, f)
+	}
+}
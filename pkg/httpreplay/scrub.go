@@ -0,0 +1,60 @@
+package httpreplay
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// replaces the values of scrubbed secrets
+const redacted = "REDACTED"
+
+var (
+	defaultScrubHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+	defaultScrubQuery   = []string{"api_key", "apikey", "key", "token", "access_token", "password"}
+)
+
+// scrub redacts the secrets of i before it is written
+func (t *Transport) scrub(i *Interaction) {
+	i.Request.URL = t.scrubURL(i.Request.URL)
+	t.scrubHeader(i.Request.Header)
+	t.scrubHeader(i.Response.Header)
+
+	for _, f := range t.scrubbers {
+		f(i)
+	}
+}
+
+func (t *Transport) scrubHeader(h http.Header) {
+	for name := range h {
+		if t.scrubHeaders[http.CanonicalHeaderKey(name)] {
+			h[name] = []string{redacted}
+		}
+	}
+}
+
+// scrubURL redacts the credentials & the secret query parameters of raw, replays match requests by their scrubbed URL
+func (t *Transport) scrubURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+
+	if u.User != nil {
+		u.User = url.User(redacted)
+	}
+
+	q := u.Query()
+	scrubbed := false
+	for param := range q {
+		if t.scrubQuery[param] {
+			q[param] = []string{redacted}
+			scrubbed = true
+		}
+	}
+	// keep the order of unscrubbed queries, Encode sorts them
+	if scrubbed {
+		u.RawQuery = q.Encode()
+	}
+
+	return u.String()
+}
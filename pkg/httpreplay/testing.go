@@ -0,0 +1,57 @@
+package httpreplay
+
+import (
+	"os"
+	"testing"
+)
+
+// EnvMode switches ForTest to record mode, e.g. HTTPREPLAY=record go test ./pkg/sochain
+const EnvMode = "HTTPREPLAY"
+
+// ForTest replays the fixture at path for t, with HTTPREPLAY=record the requests of t are sent upstream & saved to path
+// once t is done. Replayed requests matching no interaction & interactions never requested fail t
+func ForTest(t testing.TB, path string, opts ...Option) *Transport {
+	t.Helper()
+
+	mode := Replay
+	if os.Getenv(EnvMode) == "record" {
+		mode = Record
+	}
+
+	return forTest(t, path, mode, opts...)
+}
+
+// ReplayForTest replays the fixture at path for t like ForTest, even with HTTPREPLAY=record. It serves fixtures of
+// faults the upstream can't be made to produce, e.g. 5xx responses or truncated bodies, which are written by hand
+func ReplayForTest(t testing.TB, path string, opts ...Option) *Transport {
+	t.Helper()
+
+	return forTest(t, path, Replay, opts...)
+}
+
+func forTest(t testing.TB, path string, mode Mode, opts ...Option) *Transport {
+	t.Helper()
+
+	tr, err := New(path, mode, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if mode == Record {
+			if err := tr.Save(); err != nil {
+				t.Errorf("unable to save fixture: %s", err)
+			}
+			return
+		}
+
+		for _, r := range tr.Unmatched() {
+			t.Errorf("no recorded interaction for %s", r)
+		}
+		for _, i := range tr.Unused() {
+			t.Errorf("recorded interaction never requested: %s %s", i.Request.Method, i.Request.URL)
+		}
+	})
+
+	return tr
+}
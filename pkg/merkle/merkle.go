@@ -0,0 +1,110 @@
+// Package merkle computes the merkle root of a block's transactions the way Bitcoin does, hashing pairs of nodes by
+// double SHA-256 & pairing the last node of odd levels with itself. It builds & verifies inclusion proofs of
+// transactions, as merkle branches & as partial merkle trees in the format of Bitcoin Core's gettxoutproof
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNoTxs = errors.New("merkle: no transactions")
+	// ErrMutated is returned for txids with identical pairs of siblings. Duplicating the last transactions of a block
+	// keeps its merkle root (CVE-2012-2459), such lists are rejected like Bitcoin Core rejects mutated blocks
+	ErrMutated = errors.New("merkle: duplicate transactions")
+)
+
+// Root computes the merkle root of txids. Txids & root are hex encoded in RPC byte order, the reverse of the hashed bytes
+func Root(txids []string) (string, error) {
+	hashes, err := parseHashes(txids)
+	if err != nil {
+		return "", err
+	}
+
+	r, mutated := root(hashes)
+	if mutated {
+		return "", ErrMutated
+	}
+	return encodeHash(r), nil
+}
+
+// parseHashes decodes hex txids in RPC byte order into hashes in internal byte order
+func parseHashes(txids []string) ([][32]byte, error) {
+	if len(txids) == 0 {
+		return nil, ErrNoTxs
+	}
+
+	hashes := make([][32]byte, len(txids))
+	for i, txid := range txids {
+		h, err := parseHash(txid)
+		if err != nil {
+			return nil, err
+		}
+		hashes[i] = h
+	}
+
+	return hashes, nil
+}
+
+func parseHash(s string) ([32]byte, error) {
+	var h [32]byte
+
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != len(h) {
+		return h, fmt.Errorf("merkle: invalid hash '%s'", s)
+	}
+	for i := range b {
+		h[len(h)-1-i] = b[i]
+	}
+
+	return h, nil
+}
+
+func encodeHash(h [32]byte) string {
+	b := make([]byte, len(h))
+	for i := range h {
+		b[len(h)-1-i] = h[i]
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// root reduces level to its merkle root, level must not be empty & is overwritten. Mutated is true if any level
+// holds a pair of identical siblings, the check Verify applies to partial merkle trees
+func root(level [][32]byte) (r [32]byte, mutated bool) {
+	for len(level) > 1 {
+		for i := 0; i+1 < len(level); i += 2 {
+			if level[i] == level[i+1] {
+				mutated = true
+			}
+		}
+		level = nextLevel(level)
+	}
+
+	return level[0], mutated
+}
+
+// nextLevel hashes the pairs of level in place & returns the parent level
+func nextLevel(level [][32]byte) [][32]byte {
+	for i := 0; i < len(level); i += 2 {
+		right := level[i]
+		if i+1 < len(level) {
+			right = level[i+1]
+		}
+		level[i/2] = hashPair(level[i], right)
+	}
+
+	return level[:(len(level)+1)/2]
+}
+
+func hashPair(left, right [32]byte) [32]byte {
+	var b [64]byte
+	copy(b[:32], left[:])
+	copy(b[32:], right[:])
+
+	first := sha256.Sum256(b[:])
+	return sha256.Sum256(first[:])
+}
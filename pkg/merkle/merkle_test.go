@@ -0,0 +1,60 @@
+package merkle
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// transactions of BTC block 100000
+var block100000 = []string{
+	"8c14f0db3df150123e6f3dbbf30f8b955a8249b62ac1d1ff16284aefa3d06d87",
+	"fff2525b8931402dd09222c50775608f75787bd2b87e56995a7bdd30f79702c4",
+	"6359f0868171b1d194cbee1af2f16ea598ae8fad666d9b012c8ed2b79a236ec4",
+	"e9a66845e05d5abc0ad04ec80f774a7e585c6e8db975962d069a522137b80c1d",
+}
+
+const block100000Root = "f3e94742aca4b5ef85488dc37c06c3282295ffec960994b2c0d5ac2a25a95766"
+
+func Test_Root(t *testing.T) {
+	got, err := Root(block100000)
+	assert.Nil(t, err)
+	assert.Equal(t, block100000Root, got)
+
+	// the root of a single transaction is its txid
+	got, err = Root(block100000[:1])
+	assert.Nil(t, err)
+	assert.Equal(t, block100000[0], got)
+}
+
+func Test_Root_Odd(t *testing.T) {
+	odd, err := Root(block100000[:3])
+	assert.Nil(t, err)
+	assert.NotEqual(t, block100000Root, odd)
+}
+
+func Test_Root_Mutated(t *testing.T) {
+	// the duplicated last transaction of an odd list hashes to the root of the list (CVE-2012-2459)
+	_, err := Root(append(append([]string{}, block100000[:3]...), block100000[2]))
+	assert.True(t, errors.Is(err, ErrMutated), "got %v", err)
+
+	// duplicated pairs of upper levels
+	_, err = Root(append(append([]string{}, block100000...), block100000...))
+	assert.True(t, errors.Is(err, ErrMutated), "got %v", err)
+
+	// identical txids at positions not hashed together are no mutation
+	_, err = Root([]string{block100000[0], block100000[1], block100000[0]})
+	assert.Nil(t, err)
+}
+
+func Test_Root_Error(t *testing.T) {
+	_, err := Root(nil)
+	assert.True(t, errors.Is(err, ErrNoTxs))
+
+	_, err = Root([]string{"abc"})
+	assert.NotNil(t, err)
+
+	_, err = Root([]string{block100000[0][:62]})
+	assert.NotNil(t, err)
+}
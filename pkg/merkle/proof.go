@@ -0,0 +1,390 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrTxNotFound   = errors.New("merkle: transaction not in block")
+	ErrInvalidProof = errors.New("merkle: invalid proof")
+)
+
+// Index returns the position of txid in txids, ErrTxNotFound if it's missing
+func Index(txids []string, txid string) (int, error) {
+	for i, id := range txids {
+		if strings.EqualFold(id, txid) {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w, txid '%s'", ErrTxNotFound, txid)
+}
+
+// Branch returns the sibling hashes on the path from txids[index] up to the merkle root, starting at the leaves.
+// Hashes are hex encoded in RPC byte order
+func Branch(txids []string, index int) ([]string, error) {
+	level, err := parseHashes(txids)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(level) {
+		return nil, fmt.Errorf("%w, index %d of %d transactions", ErrTxNotFound, index, len(level))
+	}
+
+	var branch []string
+	for ; len(level) > 1; index /= 2 {
+		sibling := index ^ 1
+		if sibling >= len(level) {
+			sibling = index
+		}
+		branch = append(branch, encodeHash(level[sibling]))
+		level = nextLevel(level)
+	}
+
+	return branch, nil
+}
+
+// VerifyBranch checks that branch leads from txid at index to root, all hashes hex encoded in RPC byte order
+func VerifyBranch(txid string, index int, branch []string, root string) error {
+	h, err := parseHash(txid)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range branch {
+		sibling, err := parseHash(s)
+		if err != nil {
+			return err
+		}
+
+		if index%2 == 1 {
+			h = hashPair(sibling, h)
+		} else {
+			h = hashPair(h, sibling)
+		}
+		index /= 2
+	}
+
+	if index != 0 {
+		return fmt.Errorf("%w, index exceeds branch of %d hashes", ErrInvalidProof, len(branch))
+	}
+	if got := encodeHash(h); !strings.EqualFold(got, root) {
+		return fmt.Errorf("%w, branch leads to root '%s', expected '%s'", ErrInvalidProof, got, root)
+	}
+
+	return nil
+}
+
+// Header is the 80 byte header of a block, hashes are hex encoded in RPC byte order
+type Header struct {
+	Version           int32
+	PreviousBlockhash string
+	Merkleroot        string
+	Time              uint32
+	Bits              uint32
+	Nonce             uint32
+}
+
+// Encode serializes h
+func (h Header) Encode() ([]byte, error) {
+	prev, err := parseHash(h.PreviousBlockhash)
+	if err != nil {
+		return nil, err
+	}
+	root, err := parseHash(h.Merkleroot)
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 80)
+	binary.LittleEndian.PutUint32(b[0:], uint32(h.Version))
+	copy(b[4:], prev[:])
+	copy(b[36:], root[:])
+	binary.LittleEndian.PutUint32(b[68:], h.Time)
+	binary.LittleEndian.PutUint32(b[72:], h.Bits)
+	binary.LittleEndian.PutUint32(b[76:], h.Nonce)
+	return b, nil
+}
+
+// Hash returns the block hash of h
+func (h Header) Hash() (string, error) {
+	b, err := h.Encode()
+	if err != nil {
+		return "", err
+	}
+
+	first := sha256.Sum256(b)
+	return encodeHash(sha256.Sum256(first[:])), nil
+}
+
+func decodeHeader(b []byte) Header {
+	var prev, root [32]byte
+	copy(prev[:], b[4:36])
+	copy(root[:], b[36:68])
+
+	return Header{
+		Version:           int32(binary.LittleEndian.Uint32(b[0:])),
+		PreviousBlockhash: encodeHash(prev),
+		Merkleroot:        encodeHash(root),
+		Time:              binary.LittleEndian.Uint32(b[68:]),
+		Bits:              binary.LittleEndian.Uint32(b[72:]),
+		Nonce:             binary.LittleEndian.Uint32(b[76:]),
+	}
+}
+
+// Proof is a partial merkle tree proving transactions of a block, serialized like the result of Bitcoin Core's gettxoutproof
+type Proof struct {
+	Header  Header
+	TxCount uint32
+	// hashes in depth-first order, internal byte order
+	Hashes [][32]byte
+	// one bit per visited node, least significant bit first
+	Flags []byte
+}
+
+// NewProof builds the partial merkle tree of txids proving txids[index]
+func NewProof(header Header, txids []string, index int) (*Proof, error) {
+	leaves, err := parseHashes(txids)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(leaves) {
+		return nil, fmt.Errorf("%w, index %d of %d transactions", ErrTxNotFound, index, len(leaves))
+	}
+
+	t := partialTree{count: len(leaves), leaves: leaves}
+	var bits []bool
+	var hashes [][32]byte
+
+	var build func(height, pos int)
+	build = func(height, pos int) {
+		parentOfMatch := pos<<height <= index && index < (pos+1)<<height
+		bits = append(bits, parentOfMatch)
+		if height == 0 || !parentOfMatch {
+			hashes = append(hashes, t.hash(height, pos))
+			return
+		}
+
+		build(height-1, pos*2)
+		if pos*2+1 < t.width(height-1) {
+			build(height-1, pos*2+1)
+		}
+	}
+	build(t.height(), 0)
+
+	flags := make([]byte, (len(bits)+7)/8)
+	for i, bit := range bits {
+		if bit {
+			flags[i/8] |= 1 << (i % 8)
+		}
+	}
+
+	return &Proof{Header: header, TxCount: uint32(len(leaves)), Hashes: hashes, Flags: flags}, nil
+}
+
+// Encode serializes p as hex in the format of gettxoutproof
+func (p *Proof) Encode() (string, error) {
+	header, err := p.Header.Encode()
+	if err != nil {
+		return "", err
+	}
+
+	b := append(header, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(b[80:], p.TxCount)
+	b = appendVarInt(b, uint64(len(p.Hashes)))
+	for _, h := range p.Hashes {
+		b = append(b, h[:]...)
+	}
+	b = appendVarInt(b, uint64(len(p.Flags)))
+	b = append(b, p.Flags...)
+
+	return hex.EncodeToString(b), nil
+}
+
+// DecodeProof parses a hex encoded proof in the format of gettxoutproof
+func DecodeProof(s string) (*Proof, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	if len(b) < 84 {
+		return nil, fmt.Errorf("%w, truncated header", ErrInvalidProof)
+	}
+
+	p := &Proof{Header: decodeHeader(b[:80]), TxCount: binary.LittleEndian.Uint32(b[80:])}
+	b = b[84:]
+
+	n, b, err := readVarInt(b)
+	if err != nil || n > uint64(len(b)/32) {
+		return nil, fmt.Errorf("%w, truncated hashes", ErrInvalidProof)
+	}
+	p.Hashes = make([][32]byte, n)
+	for i := range p.Hashes {
+		copy(p.Hashes[i][:], b[:32])
+		b = b[32:]
+	}
+
+	n, b, err = readVarInt(b)
+	if err != nil || n != uint64(len(b)) {
+		return nil, fmt.Errorf("%w, invalid flags", ErrInvalidProof)
+	}
+	p.Flags = b
+
+	return p, nil
+}
+
+// Verify checks that the partial merkle tree of p leads to the merkle root of its header & returns the proven txids
+func (p *Proof) Verify() ([]string, error) {
+	if p.TxCount == 0 || len(p.Hashes) > int(p.TxCount) || len(p.Flags)*8 < len(p.Hashes) {
+		return nil, fmt.Errorf("%w, %d hashes & %d flag bytes of %d transactions", ErrInvalidProof, len(p.Hashes), len(p.Flags), p.TxCount)
+	}
+
+	t := partialTree{count: int(p.TxCount)}
+	var bitPos, hashPos int
+	var matched []string
+	var failed bool
+
+	var extract func(height, pos int) [32]byte
+	extract = func(height, pos int) [32]byte {
+		if bitPos >= len(p.Flags)*8 || failed {
+			failed = true
+			return [32]byte{}
+		}
+		parentOfMatch := p.Flags[bitPos/8]&(1<<(bitPos%8)) != 0
+		bitPos++
+
+		if height == 0 || !parentOfMatch {
+			if hashPos >= len(p.Hashes) {
+				failed = true
+				return [32]byte{}
+			}
+			h := p.Hashes[hashPos]
+			hashPos++
+			if height == 0 && parentOfMatch {
+				matched = append(matched, encodeHash(h))
+			}
+			return h
+		}
+
+		left := extract(height-1, pos*2)
+		right := left
+		if pos*2+1 < t.width(height-1) {
+			right = extract(height-1, pos*2+1)
+			// identical siblings allow to forge trees of a different transaction count (CVE-2012-2459)
+			if right == left {
+				failed = true
+			}
+		}
+		return hashPair(left, right)
+	}
+
+	root := extract(t.height(), 0)
+	if failed || hashPos != len(p.Hashes) || (bitPos+7)/8 != len(p.Flags) {
+		return nil, fmt.Errorf("%w, malformed partial merkle tree", ErrInvalidProof)
+	}
+	if got := encodeHash(root); !strings.EqualFold(got, p.Header.Merkleroot) {
+		return nil, fmt.Errorf("%w, tree leads to root '%s', expected '%s'", ErrInvalidProof, got, p.Header.Merkleroot)
+	}
+
+	return matched, nil
+}
+
+// VerifyProof checks that the hex encoded proof s proves txid & returns the hash of the block containing it
+func VerifyProof(s, txid string) (string, error) {
+	p, err := DecodeProof(s)
+	if err != nil {
+		return "", err
+	}
+
+	matched, err := p.Verify()
+	if err != nil {
+		return "", err
+	}
+	if _, err := Index(matched, txid); err != nil {
+		return "", err
+	}
+
+	return p.Header.Hash()
+}
+
+// partialTree computes the shape of the merkle tree of count transactions as Bitcoin Core's CPartialMerkleTree
+type partialTree struct {
+	count  int
+	leaves [][32]byte
+}
+
+// width is the number of nodes at height, leaves are at height 0
+func (t partialTree) width(height int) int {
+	return (t.count + (1 << height) - 1) >> height
+}
+
+func (t partialTree) height() int {
+	height := 0
+	for t.width(height) > 1 {
+		height++
+	}
+	return height
+}
+
+func (t partialTree) hash(height, pos int) [32]byte {
+	if height == 0 {
+		return t.leaves[pos]
+	}
+
+	left := t.hash(height-1, pos*2)
+	right := left
+	if pos*2+1 < t.width(height-1) {
+		right = t.hash(height-1, pos*2+1)
+	}
+	return hashPair(left, right)
+}
+
+func appendVarInt(b []byte, v uint64) []byte {
+	switch {
+	case v < 0xfd:
+		return append(b, byte(v))
+	case v <= 0xffff:
+		return append(b, 0xfd, byte(v), byte(v>>8))
+	case v <= 0xffffffff:
+		return append(b, 0xfe, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+	default:
+		b = append(b, 0xff)
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], v)
+		return append(b, buf[:]...)
+	}
+}
+
+func readVarInt(b []byte) (uint64, []byte, error) {
+	if len(b) == 0 {
+		return 0, nil, ErrInvalidProof
+	}
+
+	size := 1
+	switch b[0] {
+	case 0xfd:
+		size = 3
+	case 0xfe:
+		size = 5
+	case 0xff:
+		size = 9
+	}
+	if len(b) < size {
+		return 0, nil, ErrInvalidProof
+	}
+
+	switch size {
+	case 3:
+		return uint64(binary.LittleEndian.Uint16(b[1:])), b[3:], nil
+	case 5:
+		return uint64(binary.LittleEndian.Uint32(b[1:])), b[5:], nil
+	case 9:
+		return binary.LittleEndian.Uint64(b[1:]), b[9:], nil
+	}
+	return uint64(b[0]), b[1:], nil
+}
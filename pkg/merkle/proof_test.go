@@ -0,0 +1,146 @@
+package merkle
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// header of BTC block 100000
+var header100000 = Header{
+	Version:           1,
+	PreviousBlockhash: "000000000002d01c1fccc21636b607dfd930d31d01c3a62104612a1719011250",
+	Merkleroot:        block100000Root,
+	Time:              1293623863,
+	Bits:              0x1b04864c,
+	Nonce:             274148111,
+}
+
+const block100000Hash = "000000000003ba27aa200b1cecaad478d2b00432346c3f1f3986da1afd33e506"
+
+func Test_Header_Hash(t *testing.T) {
+	got, err := header100000.Hash()
+	assert.Nil(t, err)
+	assert.Equal(t, block100000Hash, got)
+
+	_, err = Header{PreviousBlockhash: "abc", Merkleroot: block100000Root}.Hash()
+	assert.NotNil(t, err)
+}
+
+func Test_Branch(t *testing.T) {
+	for _, n := range []int{1, 3, 4} {
+		txids := block100000[:n]
+		root, err := Root(txids)
+		assert.Nil(t, err)
+
+		for i, txid := range txids {
+			branch, err := Branch(txids, i)
+			assert.Nil(t, err)
+			assert.Nil(t, VerifyBranch(txid, i, branch, root), "%d of %d", i, n)
+
+			// the branch doesn't prove other positions, except for the duplicate of the last node of odd levels
+			if i^1 < n {
+				assert.True(t, errors.Is(VerifyBranch(txid, i^1, branch, root), ErrInvalidProof))
+			}
+		}
+	}
+
+	_, err := Branch(block100000, 4)
+	assert.True(t, errors.Is(err, ErrTxNotFound))
+}
+
+func Test_Branch_Block100000(t *testing.T) {
+	branch, err := Branch(block100000, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(branch))
+	assert.Equal(t, block100000[3], branch[0])
+
+	left, err := Root(block100000[:2])
+	assert.Nil(t, err)
+	assert.Equal(t, left, branch[1])
+
+	err = VerifyBranch(block100000[2], 2, branch, block100000[0])
+	assert.True(t, errors.Is(err, ErrInvalidProof))
+	err = VerifyBranch(block100000[2], 6, branch, block100000Root)
+	assert.True(t, errors.Is(err, ErrInvalidProof))
+}
+
+func Test_Proof(t *testing.T) {
+	p, err := NewProof(header100000, block100000, 0)
+	assert.Nil(t, err)
+	// root, left subtree & first leaf are parents of the match, the second leaf & right subtree are pruned
+	assert.Equal(t, []byte{0x07}, p.Flags)
+	assert.Equal(t, 3, len(p.Hashes))
+
+	s, err := p.Encode()
+	assert.Nil(t, err)
+	assert.Equal(t, 2*(80+4+1+3*32+1+1), len(s))
+	assert.True(t, strings.HasSuffix(s, "0107"))
+
+	hash, err := VerifyProof(s, block100000[0])
+	assert.Nil(t, err)
+	assert.Equal(t, block100000Hash, hash)
+
+	_, err = VerifyProof(s, block100000[1])
+	assert.True(t, errors.Is(err, ErrTxNotFound))
+}
+
+func Test_Proof_RoundTrip(t *testing.T) {
+	for _, n := range []int{1, 3, 4} {
+		txids := block100000[:n]
+		root, err := Root(txids)
+		assert.Nil(t, err)
+
+		header := header100000
+		header.Merkleroot = root
+		for i, txid := range txids {
+			p, err := NewProof(header, txids, i)
+			assert.Nil(t, err)
+
+			s, err := p.Encode()
+			assert.Nil(t, err)
+
+			decoded, err := DecodeProof(s)
+			assert.Nil(t, err)
+			assert.Equal(t, p, decoded)
+
+			matched, err := decoded.Verify()
+			assert.Nil(t, err)
+			assert.Equal(t, []string{txid}, matched, "%d of %d", i, n)
+		}
+	}
+}
+
+func Test_Proof_Error(t *testing.T) {
+	p, err := NewProof(header100000, block100000, 3)
+	assert.Nil(t, err)
+	s, err := p.Encode()
+	assert.Nil(t, err)
+
+	tests := []struct {
+		title string
+		proof string
+	}{
+		{title: "not hex", proof: "zz"},
+		{title: "truncated header", proof: s[:100]},
+		{title: "truncated hashes", proof: s[:200]},
+		{title: "missing flags", proof: s[:len(s)-4]},
+		{title: "trailing bytes", proof: s + "00"},
+		// the last hash belongs to the matched leaf
+		{title: "tampered hash", proof: s[:len(s)-8] + "00" + s[len(s)-6:]},
+		{title: "tampered root", proof: s[:72] + "00" + s[74:]},
+		{title: "tampered tx count", proof: s[:160] + "05" + s[162:]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			_, err := VerifyProof(tt.proof, block100000[3])
+			assert.True(t, errors.Is(err, ErrInvalidProof), err)
+		})
+	}
+
+	_, err = NewProof(header100000, block100000, -1)
+	assert.True(t, errors.Is(err, ErrTxNotFound))
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"sochain-client/pkg/cache"
+	"sochain-client/pkg/coalesce"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	cacheHits      = prometheus.NewDesc(namespace+"_cache_hits_total", "Cache lookups served from memory.", nil, nil)
+	cacheMisses    = prometheus.NewDesc(namespace+"_cache_misses_total", "Cache lookups passed to the provider.", nil, nil)
+	cacheEvictions = prometheus.NewDesc(namespace+"_cache_evictions_total", "Cache entries evicted to stay within the size limit.", nil, nil)
+	cacheEntries   = prometheus.NewDesc(namespace+"_cache_entries", "Entries currently cached.", nil, nil)
+
+	coalesceCalls     = prometheus.NewDesc(namespace+"_coalesce_calls_total", "Upstream calls started by the coalescer.", nil, nil)
+	coalesceCoalesced = prometheus.NewDesc(namespace+"_coalesce_coalesced_total", "Callers served by a call started by another caller.", nil, nil)
+	coalesceCancelled = prometheus.NewDesc(namespace+"_coalesce_cancelled_total", "Shared calls cancelled because all their callers were gone.", nil, nil)
+	coalesceInFlight  = prometheus.NewDesc(namespace+"_coalesce_in_flight", "Shared calls currently in flight.", nil, nil)
+)
+
+// RegisterCache exports the counters of c, the hit ratio is hits / (hits + misses)
+func (m *Metrics) RegisterCache(c *cache.Cache) {
+	m.reg.MustRegister(cacheCollector{c})
+}
+
+// RegisterCoalescer exports the counters of c
+func (m *Metrics) RegisterCoalescer(c *coalesce.Coalescer) {
+	m.reg.MustRegister(coalesceCollector{c})
+}
+
+// cacheCollector reads the counters kept by the cache on every scrape
+type cacheCollector struct {
+	c *cache.Cache
+}
+
+func (cc cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cacheHits
+	ch <- cacheMisses
+	ch <- cacheEvictions
+	ch <- cacheEntries
+}
+
+func (cc cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	s := cc.c.Stats()
+	ch <- prometheus.MustNewConstMetric(cacheHits, prometheus.CounterValue, float64(s.Hits))
+	ch <- prometheus.MustNewConstMetric(cacheMisses, prometheus.CounterValue, float64(s.Misses))
+	ch <- prometheus.MustNewConstMetric(cacheEvictions, prometheus.CounterValue, float64(s.Evictions))
+	ch <- prometheus.MustNewConstMetric(cacheEntries, prometheus.GaugeValue, float64(s.Entries))
+}
+
+// coalesceCollector reads the counters kept by the coalescer on every scrape
+type coalesceCollector struct {
+	c *coalesce.Coalescer
+}
+
+func (cc coalesceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- coalesceCalls
+	ch <- coalesceCoalesced
+	ch <- coalesceCancelled
+	ch <- coalesceInFlight
+}
+
+func (cc coalesceCollector) Collect(ch chan<- prometheus.Metric) {
+	s := cc.c.Stats()
+	ch <- prometheus.MustNewConstMetric(coalesceCalls, prometheus.CounterValue, float64(s.Calls))
+	ch <- prometheus.MustNewConstMetric(coalesceCoalesced, prometheus.CounterValue, float64(s.Coalesced))
+	ch <- prometheus.MustNewConstMetric(coalesceCancelled, prometheus.CounterValue, float64(s.Cancelled))
+	ch <- prometheus.MustNewConstMetric(coalesceInFlight, prometheus.GaugeValue, float64(s.InFlight))
+}
@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"context"
+	"sochain-client/pkg/sochain"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ sochain.Connector = (*Connector)(nil)
+
+// Connector counts & times every call of a provider
+type Connector struct {
+	next     sochain.Connector
+	provider string
+	m        *Metrics
+}
+
+// Connector instruments the calls of next, labelled by provider. A batch of Transactions counts as a single request
+func (m *Metrics) Connector(provider string, next sochain.Connector) *Connector {
+	return &Connector{
+		next:     next,
+		provider: provider,
+		m:        m,
+	}
+}
+
+func (c *Connector) NetworkInfo(ctx context.Context, networkID string) (*sochain.NetworkInfo, error) {
+	start := time.Now()
+	v, err := c.next.NetworkInfo(ctx, networkID)
+	c.observe("NetworkInfo", networkID, start, err)
+	return v, err
+}
+
+func (c *Connector) BlockHeight(ctx context.Context, networkID string, height int) (*sochain.Block, error) {
+	start := time.Now()
+	v, err := c.next.BlockHeight(ctx, networkID, height)
+	c.observe("BlockHeight", networkID, start, err)
+	return v, err
+}
+
+func (c *Connector) BlockHash(ctx context.Context, networkID, blockHash string) (*sochain.Block, error) {
+	start := time.Now()
+	v, err := c.next.BlockHash(ctx, networkID, blockHash)
+	c.observe("BlockHash", networkID, start, err)
+	return v, err
+}
+
+func (c *Connector) Transaction(ctx context.Context, networkID, txHash string) (*sochain.Transaction, error) {
+	start := time.Now()
+	v, err := c.next.Transaction(ctx, networkID, txHash)
+	c.observe("Transaction", networkID, start, err)
+	return v, err
+}
+
+func (c *Connector) Transactions(ctx context.Context, networkID string, hashes []string) ([]sochain.TxResult, error) {
+	start := time.Now()
+	v, err := c.next.Transactions(ctx, networkID, hashes)
+	c.observe("Transactions", networkID, start, err)
+	return v, err
+}
+
+func (c *Connector) AddressBalance(ctx context.Context, networkID, address string) (*sochain.AddressBalance, error) {
+	start := time.Now()
+	v, err := c.next.AddressBalance(ctx, networkID, address)
+	c.observe("AddressBalance", networkID, start, err)
+	return v, err
+}
+
+func (c *Connector) ReceivedTransactions(ctx context.Context, networkID, address, afterTxid string) (*sochain.ReceivedTxs, error) {
+	start := time.Now()
+	v, err := c.next.ReceivedTransactions(ctx, networkID, address, afterTxid)
+	c.observe("ReceivedTransactions", networkID, start, err)
+	return v, err
+}
+
+func (c *Connector) OutputSpender(ctx context.Context, networkID, txHash string, outputNo int) (*sochain.SpentBy, error) {
+	start := time.Now()
+	v, err := c.next.OutputSpender(ctx, networkID, txHash, outputNo)
+	c.observe("OutputSpender", networkID, start, err)
+	return v, err
+}
+
+func (c *Connector) SpentTransactions(ctx context.Context, networkID, address, afterTxid string) (*sochain.SpentTxs, error) {
+	start := time.Now()
+	v, err := c.next.SpentTransactions(ctx, networkID, address, afterTxid)
+	c.observe("SpentTransactions", networkID, start, err)
+	return v, err
+}
+
+func (c *Connector) UnspentOutputs(ctx context.Context, networkID, address, afterTxid string) (*sochain.UnspentOutputs, error) {
+	start := time.Now()
+	v, err := c.next.UnspentOutputs(ctx, networkID, address, afterTxid)
+	c.observe("UnspentOutputs", networkID, start, err)
+	return v, err
+}
+
+func (c *Connector) BroadcastTransaction(ctx context.Context, networkID, txHex string) (*sochain.BroadcastTx, error) {
+	start := time.Now()
+	v, err := c.next.BroadcastTransaction(ctx, networkID, txHex)
+	c.observe("BroadcastTransaction", networkID, start, err)
+	return v, err
+}
+
+func (c *Connector) observe(method, networkID string, start time.Time, err error) {
+	networkID = strings.ToLower(networkID)
+	c.m.upstreamRequests.WithLabelValues(c.provider, method, networkID, strconv.Itoa(sochain.StatusCode(err))).Inc()
+	c.m.upstreamDuration.WithLabelValues(c.provider, method, networkID).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,112 @@
+// Package metrics exports prometheus metrics of the service: upstream requests of every provider, retries & rate limit
+// waits of sochain, cache & coalescing counters and the latency & status of every HTTP route
+package metrics
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "sochain_client"
+
+// Metrics holds the collectors of the service, all of them registered by New
+type Metrics struct {
+	upstreamRequests  *prometheus.CounterVec
+	upstreamDuration  *prometheus.HistogramVec
+	retries           *prometheus.CounterVec
+	rateLimitWaits    *prometheus.CounterVec
+	rateLimitWaitTime *prometheus.CounterVec
+	httpRequests      *prometheus.CounterVec
+	httpDuration      *prometheus.HistogramVec
+	txFetches         prometheus.Gauge
+
+	reg prometheus.Registerer
+}
+
+// New creates the collectors of the service & registers them with reg
+func New(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		upstreamRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "upstream",
+			Name:      "requests_total",
+			Help:      "Upstream requests by provider, Connector method, network & status code.",
+		}, []string{"provider", "method", "network", "code"}),
+		upstreamDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "upstream",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of upstream requests by provider, Connector method & network.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"provider", "method", "network"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "upstream",
+			Name:      "retries_total",
+			Help:      "Retried upstream requests by network.",
+		}, []string{"network"}),
+		rateLimitWaits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "upstream",
+			Name:      "rate_limit_waits_total",
+			Help:      "Upstream requests passing the rate limiter by network.",
+		}, []string{"network"}),
+		rateLimitWaitTime: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "upstream",
+			Name:      "rate_limit_wait_seconds_total",
+			Help:      "Time upstream requests waited for the rate limiter by network.",
+		}, []string{"network"}),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Handled HTTP requests by route, method & status code.",
+		}, []string{"route", "method", "code"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of HTTP requests by route & method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+		txFetches: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "block_tx_fetches_in_flight",
+			Help:      "Transactions of blocks currently fetched for GET /network/:id.",
+		}),
+		reg: reg,
+	}
+
+	reg.MustRegister(
+		m.upstreamRequests,
+		m.upstreamDuration,
+		m.retries,
+		m.rateLimitWaits,
+		m.rateLimitWaitTime,
+		m.httpRequests,
+		m.httpDuration,
+		m.txFetches,
+	)
+
+	return m
+}
+
+// ObserveRetry counts a retried request of networkID, see sochain.WithRetryObserver
+func (m *Metrics) ObserveRetry(networkID string, attempt int, err error) {
+	m.retries.WithLabelValues(strings.ToLower(networkID)).Inc()
+}
+
+// ObserveTxFetches tracks the transactions of blocks in flight, see controller.WithTxFetchObserver
+func (m *Metrics) ObserveTxFetches(delta int) {
+	m.txFetches.Add(float64(delta))
+}
+
+// ObserveRateLimit counts a request of networkID which waited for the rate limiter, see sochain.WithRateLimitObserver
+func (m *Metrics) ObserveRateLimit(networkID string, wait time.Duration) {
+	networkID = strings.ToLower(networkID)
+	m.rateLimitWaits.WithLabelValues(networkID).Inc()
+	m.rateLimitWaitTime.WithLabelValues(networkID).Add(wait.Seconds())
+}
@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sochain-client/pkg/cache"
+	"sochain-client/pkg/coalesce"
+	"sochain-client/pkg/sochain"
+	mock_client "sochain-client/pkg/sochain/mock"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMetrics() (*Metrics, *prometheus.Registry) {
+	reg := prometheus.NewRegistry()
+	return New(reg), reg
+}
+
+func Test_Connector(t *testing.T) {
+	m, _ := newTestMetrics()
+	mock := mock_client.NewMockConnector(gomock.NewController(t))
+
+	mock.EXPECT().BlockHeight(gomock.Any(), "BTC", 1).Return(&sochain.Block{}, nil)
+	mock.EXPECT().BlockHeight(gomock.Any(), "btc", 2).Return(nil, sochain.NewClientErr(errors.New("not found"), http.StatusNotFound))
+	mock.EXPECT().Transactions(gomock.Any(), "ltc", []string{"a", "b"}).Return(make([]sochain.TxResult, 2), nil)
+
+	c := m.Connector("esplora", mock)
+	_, err := c.BlockHeight(context.Background(), "BTC", 1)
+	assert.Nil(t, err)
+	_, err = c.BlockHeight(context.Background(), "btc", 2)
+	assert.NotNil(t, err)
+	_, err = c.Transactions(context.Background(), "ltc", []string{"a", "b"})
+	assert.Nil(t, err)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.upstreamRequests.WithLabelValues("esplora", "BlockHeight", "btc", "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.upstreamRequests.WithLabelValues("esplora", "BlockHeight", "btc", "404")))
+	// a batch is a single request
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.upstreamRequests.WithLabelValues("esplora", "Transactions", "ltc", "200")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.upstreamDuration))
+}
+
+func Test_Observers(t *testing.T) {
+	m, _ := newTestMetrics()
+
+	m.ObserveRetry("BTC", 1, errors.New("some"))
+	m.ObserveRetry("btc", 2, errors.New("some"))
+	m.ObserveRateLimit("DOGE", 0)
+	m.ObserveRateLimit("DOGE", 1500*time.Millisecond)
+	m.ObserveTxFetches(10)
+	m.ObserveTxFetches(-3)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.retries.WithLabelValues("btc")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.rateLimitWaits.WithLabelValues("doge")))
+	assert.Equal(t, 1.5, testutil.ToFloat64(m.rateLimitWaitTime.WithLabelValues("doge")))
+	assert.Equal(t, float64(7), testutil.ToFloat64(m.txFetches))
+}
+
+func Test_Middleware(t *testing.T) {
+	m, _ := newTestMetrics()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(m.Middleware())
+	r.GET("/network/:id", func(ctx *gin.Context) {
+		ctx.Status(http.StatusTeapot)
+	})
+
+	for _, path := range []string{"/network/btc", "/network/ltc", "/nope"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	// labelled by route, not by path
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.httpRequests.WithLabelValues("/network/:id", "GET", "418")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.httpRequests.WithLabelValues("unmatched", "GET", "404")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.httpDuration))
+}
+
+func Test_Collectors(t *testing.T) {
+	m, reg := newTestMetrics()
+	mock := mock_client.NewMockConnector(gomock.NewController(t))
+
+	mock.EXPECT().NetworkInfo(gomock.Any(), "btc").Return(&sochain.NetworkInfo{}, nil)
+
+	c := cache.NewCache(mock)
+	m.RegisterCache(c)
+	m.RegisterCoalescer(coalesce.NewCoalescer(c))
+
+	for i := 0; i < 3; i++ {
+		_, err := c.NetworkInfo(context.Background(), "btc")
+		assert.Nil(t, err)
+	}
+
+	expected := `
+# HELP sochain_client_cache_entries Entries currently cached.
+# TYPE sochain_client_cache_entries gauge
+sochain_client_cache_entries 1
+# HELP sochain_client_cache_evictions_total Cache entries evicted to stay within the size limit.
+# TYPE sochain_client_cache_evictions_total counter
+sochain_client_cache_evictions_total 0
+# HELP sochain_client_cache_hits_total Cache lookups served from memory.
+# TYPE sochain_client_cache_hits_total counter
+sochain_client_cache_hits_total 2
+# HELP sochain_client_cache_misses_total Cache lookups passed to the provider.
+# TYPE sochain_client_cache_misses_total counter
+sochain_client_cache_misses_total 1
+# HELP sochain_client_coalesce_in_flight Shared calls currently in flight.
+# TYPE sochain_client_coalesce_in_flight gauge
+sochain_client_coalesce_in_flight 0
+`
+	assert.Nil(t, testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"sochain_client_cache_entries",
+		"sochain_client_cache_evictions_total",
+		"sochain_client_cache_hits_total",
+		"sochain_client_cache_misses_total",
+		"sochain_client_coalesce_in_flight",
+	))
+}
@@ -0,0 +1,181 @@
+// Package network describes the blockchain networks served by the application
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrUnknown  = errors.New("unknown network")
+	ErrDisabled = errors.New("network disabled")
+)
+
+// HashFormat describes how block & transaction hashes of a network are encoded
+type HashFormat string
+
+// Hex encoded SHA-256 hashes, used by BTC, LTC & DOGE for blocks & transactions
+const HashSHA256 HashFormat = "sha256"
+
+var hashSHA256Regex = regexp.MustCompile("^[A-Fa-f0-9]{64}$")
+
+// Valid reports whether hash is encoded in format f
+func (f HashFormat) Valid(hash string) bool {
+	switch f {
+	case HashSHA256:
+		return hashSHA256Regex.MatchString(hash)
+	}
+
+	return false
+}
+
+// Base58 & bech32 characters
+var addressRegex = regexp.MustCompile("^[A-Za-z0-9]{25,90}$")
+
+type Network struct {
+	// Case-insensitive id of the network in the API paths, e.g. 'btc'
+	ID string `json:"id"`
+	// Network identifier of the Sochain API, e.g. 'BTC'
+	SochainID string `json:"sochain_id"`
+	Name      string `json:"name"`
+	Testnet   bool   `json:"testnet"`
+	// Leading characters of valid addresses, e.g. '1', '3' & 'bc1' on BTC
+	AddressPrefixes []string   `json:"address_prefixes"`
+	HashFormat      HashFormat `json:"hash_format"`
+	// Confirmations after which blocks & transactions are considered final
+	Confirmations int  `json:"confirmations"`
+	Disabled      bool `json:"disabled"`
+}
+
+// ValidHash reports whether hash is a valid block or transaction hash of the network
+func (n Network) ValidHash(hash string) bool {
+	return n.HashFormat.Valid(hash)
+}
+
+// ValidAddress reports whether address consists of valid characters & starts with one of the address prefixes of the network.
+// Bech32 addresses may be all lower or all upper case, but not mixed
+func (n Network) ValidAddress(address string) bool {
+	if !addressRegex.MatchString(address) {
+		return false
+	}
+
+	for _, p := range n.AddressPrefixes {
+		if bech32Prefix(p) {
+			if strings.HasPrefix(strings.ToLower(address), strings.ToLower(p)) {
+				return address == strings.ToLower(address) || address == strings.ToUpper(address)
+			}
+			continue
+		}
+		if strings.HasPrefix(address, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NormalAddress returns address with bech32 addresses in lower case, the form upstream APIs expect
+func (n Network) NormalAddress(address string) string {
+	for _, p := range n.AddressPrefixes {
+		if bech32Prefix(p) && strings.HasPrefix(strings.ToLower(address), strings.ToLower(p)) {
+			return strings.ToLower(address)
+		}
+	}
+
+	return address
+}
+
+// bech32Prefix reports whether p is a bech32 human-readable part followed by the separator '1', e.g. 'bc1'
+func bech32Prefix(p string) bool {
+	return len(p) > 1 && strings.HasSuffix(p, "1")
+}
+
+// Registry holds the known networks keyed by their case-insensitive id
+type Registry struct {
+	networks map[string]Network
+}
+
+func NewRegistry(networks ...Network) *Registry {
+	r := &Registry{
+		networks: make(map[string]Network, len(networks)),
+	}
+	for _, n := range networks {
+		n.ID = strings.ToLower(n.ID)
+		r.networks[n.ID] = n
+	}
+
+	return r
+}
+
+// DefaultRegistry serves the mainnets & testnets of BTC, LTC & DOGE
+func DefaultRegistry() *Registry {
+	return NewRegistry(
+		Network{ID: "btc", SochainID: "BTC", Name: "Bitcoin", AddressPrefixes: []string{"1", "3", "bc1"}, HashFormat: HashSHA256, Confirmations: 6},
+		Network{ID: "ltc", SochainID: "LTC", Name: "Litecoin", AddressPrefixes: []string{"L", "M", "3", "ltc1"}, HashFormat: HashSHA256, Confirmations: 12},
+		Network{ID: "doge", SochainID: "DOGE", Name: "Dogecoin", AddressPrefixes: []string{"D", "A", "9"}, HashFormat: HashSHA256, Confirmations: 40},
+		Network{ID: "btctest", SochainID: "BTCTEST", Name: "Bitcoin Testnet", Testnet: true, AddressPrefixes: []string{"m", "n", "2", "tb1"}, HashFormat: HashSHA256, Confirmations: 6},
+		Network{ID: "ltctest", SochainID: "LTCTEST", Name: "Litecoin Testnet", Testnet: true, AddressPrefixes: []string{"m", "n", "2", "Q", "tltc1"}, HashFormat: HashSHA256, Confirmations: 12},
+		Network{ID: "dogetest", SochainID: "DOGETEST", Name: "Dogecoin Testnet", Testnet: true, AddressPrefixes: []string{"n", "2"}, HashFormat: HashSHA256, Confirmations: 40},
+	)
+}
+
+// LoadRegistry reads a JSON array of networks
+func LoadRegistry(r io.Reader) (*Registry, error) {
+	var networks []Network
+	if err := json.NewDecoder(r).Decode(&networks); err != nil {
+		return nil, fmt.Errorf("invalid network registry: %w", err)
+	}
+
+	for _, n := range networks {
+		if n.ID == "" || n.SochainID == "" {
+			return nil, fmt.Errorf("invalid network registry: network '%s' requires 'id' & 'sochain_id'", n.Name)
+		}
+		if n.HashFormat == "" {
+			return nil, fmt.Errorf("invalid network registry: network '%s' requires 'hash_format'", n.ID)
+		}
+	}
+
+	return NewRegistry(networks...), nil
+}
+
+// Lookup returns the enabled network of id, ignoring case
+func (r *Registry) Lookup(id string) (Network, error) {
+	n, ok := r.networks[strings.ToLower(id)]
+	if !ok {
+		return Network{}, fmt.Errorf("%w '%s'", ErrUnknown, id)
+	}
+	if n.Disabled {
+		return Network{}, fmt.Errorf("%w '%s'", ErrDisabled, id)
+	}
+
+	return n, nil
+}
+
+// Disable excludes the networks of ids from Lookup
+func (r *Registry) Disable(ids ...string) {
+	for _, id := range ids {
+		key := strings.ToLower(strings.TrimSpace(id))
+		if n, ok := r.networks[key]; ok {
+			n.Disabled = true
+			r.networks[key] = n
+		}
+	}
+}
+
+// IDs returns the sorted ids of all enabled networks
+func (r *Registry) IDs() []string {
+	ids := make([]string, 0, len(r.networks))
+	for id, n := range r.networks {
+		if !n.Disabled {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	return ids
+}
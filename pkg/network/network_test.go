@@ -0,0 +1,65 @@
+package network
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Registry_Lookup(t *testing.T) {
+	r := DefaultRegistry()
+
+	n, err := r.Lookup("BTCtest")
+	assert.Nil(t, err)
+	assert.Equal(t, "btctest", n.ID)
+	assert.Equal(t, "BTCTEST", n.SochainID)
+	assert.True(t, n.Testnet)
+
+	_, err = r.Lookup("eth")
+	assert.True(t, errors.Is(err, ErrUnknown))
+
+	r.Disable("DOGE")
+	_, err = r.Lookup("doge")
+	assert.True(t, errors.Is(err, ErrDisabled))
+	assert.Equal(t, []string{"btc", "btctest", "dogetest", "ltc", "ltctest"}, r.IDs())
+}
+
+func Test_Network_Validation(t *testing.T) {
+	r := DefaultRegistry()
+	btc, _ := r.Lookup("btc")
+	ltc, _ := r.Lookup("ltc")
+
+	assert.True(t, btc.ValidHash("00000000000000000008fa3759141044ae3db1e6ec222e114651354f58d5cc42"))
+	assert.False(t, btc.ValidHash("00000000000000000008fa3759141044ae3db1e6ec222e114651354f58d5cc4"))
+	assert.False(t, btc.ValidHash("00000000000000000008fa3759141044ae3db1e6ec222e114651354f58d5cc4&"))
+
+	assert.True(t, btc.ValidAddress("1BoatSLRHtKNngkdXEeobR76b53LETtpyT"))
+	assert.True(t, btc.ValidAddress("bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"))
+	assert.False(t, btc.ValidAddress("ltc1qg82tgtnwk5yxd3gxq2mk2hy6y2q8qvgfz7pcsu"))
+	assert.True(t, ltc.ValidAddress("ltc1qg82tgtnwk5yxd3gxq2mk2hy6y2q8qvgfz7pcsu"))
+	assert.False(t, ltc.ValidAddress("ltc1q&"))
+
+	assert.True(t, btc.ValidAddress("BC1QAR0SRRR7XFKVY5L643LYDNW9RE59GTZZWF5MDQ"))
+	assert.False(t, btc.ValidAddress("bc1QAR0SRRR7XFKVY5L643LYDNW9RE59GTZZWF5MDQ"))
+	assert.False(t, btc.ValidAddress("BC1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"))
+	assert.Equal(t, "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", btc.NormalAddress("BC1QAR0SRRR7XFKVY5L643LYDNW9RE59GTZZWF5MDQ"))
+	assert.Equal(t, "1BoatSLRHtKNngkdXEeobR76b53LETtpyT", btc.NormalAddress("1BoatSLRHtKNngkdXEeobR76b53LETtpyT"))
+}
+
+func Test_LoadRegistry(t *testing.T) {
+	r, err := LoadRegistry(strings.NewReader(`[{"id":"BTC","sochain_id":"BTC","hash_format":"sha256","address_prefixes":["1"],"confirmations":3}]`))
+	assert.Nil(t, err)
+
+	n, err := r.Lookup("btc")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, n.Confirmations)
+	assert.Equal(t, []string{"btc"}, r.IDs())
+
+	_, err = LoadRegistry(strings.NewReader(`[{"id":"btc"}]`))
+	assert.NotNil(t, err)
+
+	_, err = LoadRegistry(strings.NewReader(`{`))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,296 @@
+// Package rawtx decodes serialized transactions of BTC, LTC & DOGE in legacy & segwit (BIP 144) format
+package rawtx
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrTruncated    = errors.New("rawtx: unexpected end of transaction")
+	ErrTrailingData = errors.New("rawtx: trailing data after locktime")
+	ErrNoInputs     = errors.New("rawtx: transaction has no inputs")
+	ErrNoOutputs    = errors.New("rawtx: transaction has no outputs")
+	// ErrNonCanonical is returned for a CompactSize integer not encoded in its shortest form. Nodes reject those, Txid
+	// & Wtxid of the re-serialization would not be the hashes of the decoded bytes
+	ErrNonCanonical = errors.New("rawtx: non-canonical compact size")
+)
+
+type Tx struct {
+	Version  int32
+	Inputs   []TxIn
+	Outputs  []TxOut
+	Locktime uint32
+	// true if the transaction was serialized with marker & flag, witnesses are only present in segwit transactions
+	Segwit bool
+}
+
+type TxIn struct {
+	// Txid of the spent output in RPC byte order
+	PrevTxid  string
+	PrevIndex uint32
+	ScriptSig []byte
+	Sequence  uint32
+	Witness   [][]byte
+}
+
+type TxOut struct {
+	// Value in satoshis
+	Value        int64
+	ScriptPubKey []byte
+}
+
+// DecodeString decodes a hex encoded transaction
+func DecodeString(txHex string) (*Tx, error) {
+	b, err := hex.DecodeString(txHex)
+	if err != nil {
+		return nil, fmt.Errorf("rawtx: invalid hex: %w", err)
+	}
+
+	return Decode(b)
+}
+
+// Decode parses a serialized transaction. The whole input has to be consumed by the transaction
+func Decode(b []byte) (*Tx, error) {
+	r := &reader{b: b}
+	var tx Tx
+
+	tx.Version = int32(r.uint32())
+
+	// segwit transactions start with marker 0x00 & flag 0x01 where legacy transactions hold the input count
+	if r.remaining() >= 2 && r.b[r.pos] == 0x00 && r.b[r.pos+1] == 0x01 {
+		tx.Segwit = true
+		r.pos += 2
+	}
+
+	inCount := r.count(41)
+	tx.Inputs = make([]TxIn, inCount)
+	for i := range tx.Inputs {
+		in := &tx.Inputs[i]
+		in.PrevTxid = hex.EncodeToString(reverse(r.bytes(32)))
+		in.PrevIndex = r.uint32()
+		in.ScriptSig = r.bytes(r.count(1))
+		in.Sequence = r.uint32()
+	}
+
+	outCount := r.count(9)
+	tx.Outputs = make([]TxOut, outCount)
+	for i := range tx.Outputs {
+		out := &tx.Outputs[i]
+		out.Value = int64(r.uint64())
+		out.ScriptPubKey = r.bytes(r.count(1))
+	}
+
+	if tx.Segwit {
+		for i := range tx.Inputs {
+			items := r.count(1)
+			tx.Inputs[i].Witness = make([][]byte, items)
+			for j := range tx.Inputs[i].Witness {
+				tx.Inputs[i].Witness[j] = r.bytes(r.count(1))
+			}
+		}
+	}
+
+	tx.Locktime = r.uint32()
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.remaining() > 0 {
+		return nil, ErrTrailingData
+	}
+	if len(tx.Inputs) == 0 {
+		return nil, ErrNoInputs
+	}
+	if len(tx.Outputs) == 0 {
+		return nil, ErrNoOutputs
+	}
+
+	return &tx, nil
+}
+
+// Encode serializes tx, witness includes marker, flag & witnesses of segwit transactions
+func (tx *Tx) Encode(witness bool) []byte {
+	witness = witness && tx.Segwit
+
+	var w writer
+	w.uint32(uint32(tx.Version))
+	if witness {
+		w.b = append(w.b, 0x00, 0x01)
+	}
+
+	w.varInt(uint64(len(tx.Inputs)))
+	for _, in := range tx.Inputs {
+		prevTxid, _ := hex.DecodeString(in.PrevTxid)
+		w.b = append(w.b, reverse(prevTxid)...)
+		w.uint32(in.PrevIndex)
+		w.varBytes(in.ScriptSig)
+		w.uint32(in.Sequence)
+	}
+
+	w.varInt(uint64(len(tx.Outputs)))
+	for _, out := range tx.Outputs {
+		w.uint64(uint64(out.Value))
+		w.varBytes(out.ScriptPubKey)
+	}
+
+	if witness {
+		for _, in := range tx.Inputs {
+			w.varInt(uint64(len(in.Witness)))
+			for _, item := range in.Witness {
+				w.varBytes(item)
+			}
+		}
+	}
+
+	w.uint32(tx.Locktime)
+	return w.b
+}
+
+// Txid is the double SHA-256 of the serialization without witnesses in RPC byte order
+func (tx *Tx) Txid() string {
+	return hashString(tx.Encode(false))
+}
+
+// Wtxid is the double SHA-256 of the serialization with witnesses in RPC byte order, equal to Txid for legacy transactions
+func (tx *Tx) Wtxid() string {
+	return hashString(tx.Encode(true))
+}
+
+func hashString(b []byte) string {
+	first := sha256.Sum256(b)
+	second := sha256.Sum256(first[:])
+	return hex.EncodeToString(reverse(second[:]))
+}
+
+// writer serializes a transaction
+type writer struct {
+	b []byte
+}
+
+func (w *writer) uint32(v uint32) {
+	w.b = append(w.b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func (w *writer) uint64(v uint64) {
+	w.uint32(uint32(v))
+	w.uint32(uint32(v >> 32))
+}
+
+// varInt writes v as CompactSize unsigned integer
+func (w *writer) varInt(v uint64) {
+	switch {
+	case v < 0xfd:
+		w.b = append(w.b, byte(v))
+	case v <= 0xffff:
+		w.b = append(w.b, 0xfd, byte(v), byte(v>>8))
+	case v <= 0xffffffff:
+		w.b = append(w.b, 0xfe)
+		w.uint32(uint32(v))
+	default:
+		w.b = append(w.b, 0xff)
+		w.uint64(v)
+	}
+}
+
+func (w *writer) varBytes(b []byte) {
+	w.varInt(uint64(len(b)))
+	w.b = append(w.b, b...)
+}
+
+// reader consumes a serialized transaction, the first error stops all further reads
+type reader struct {
+	b   []byte
+	pos int
+	err error
+}
+
+func (r *reader) remaining() int {
+	return len(r.b) - r.pos
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > r.remaining() {
+		r.err = ErrTruncated
+		return nil
+	}
+
+	b := r.b[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *reader) uint32() uint32 {
+	b := r.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b)
+}
+
+func (r *reader) uint64() uint64 {
+	b := r.bytes(8)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+// varInt reads a CompactSize unsigned integer, values which would fit a shorter encoding are rejected
+func (r *reader) varInt() uint64 {
+	prefix := r.bytes(1)
+	if prefix == nil {
+		return 0
+	}
+
+	var v, min uint64
+	switch prefix[0] {
+	case 0xfd:
+		b := r.bytes(2)
+		if b == nil {
+			return 0
+		}
+		v, min = uint64(binary.LittleEndian.Uint16(b)), 0xfd
+	case 0xfe:
+		v, min = uint64(r.uint32()), 0x10000
+	case 0xff:
+		v, min = r.uint64(), 0x100000000
+	default:
+		return uint64(prefix[0])
+	}
+	if r.err == nil && v < min {
+		r.err = ErrNonCanonical
+		return 0
+	}
+
+	return v
+}
+
+// count reads a varInt which counts elements of at least minSize bytes each.
+// Counts exceeding the remaining input are rejected before anything gets allocated
+func (r *reader) count(minSize int) int {
+	n := r.varInt()
+	if r.err != nil {
+		return 0
+	}
+	if n > uint64(r.remaining()/minSize) {
+		r.err = ErrTruncated
+		return 0
+	}
+
+	return int(n)
+}
+
+func reverse(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return r
+}
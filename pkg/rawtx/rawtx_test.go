@@ -0,0 +1,126 @@
+package rawtx
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// coinbase transaction of the BTC genesis block
+const genesisCoinbaseHex = "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff4d04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73ffffffff0100f2052a01000000434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac00000000"
+
+// P2WPKH spend with two outputs
+const segwitHex = "0200000000010184fd9bac333ad79154348296204fa7f8c537a96e08983e5f73b3f5aca8e8edf70100000000fdffffff02f049020000000000160014ca978112ca1bbdcafac231b39a23dc4da786eff890a6f802000000001600143e23e8160039594a33894f6564e1b1348bbd7a00024730440220454349e422f05297191ead13e21d3db520e5abef52055e4964b82fb213f593a10220043a718774c572bd8a25adbeb1bfcd5c0256ae11cecf9f9c3f925d0e52beaf890121020017dea7770f7ecff7ab3c20506546129e96bdeba2f544bb8e5414eb797861220a000000"
+
+func Test_Decode_Legacy(t *testing.T) {
+	tx, err := DecodeString(genesisCoinbaseHex)
+	assert.Nil(t, err)
+
+	assert.Equal(t, int32(1), tx.Version)
+	assert.False(t, tx.Segwit)
+	assert.Len(t, tx.Inputs, 1)
+	assert.Equal(t, "0000000000000000000000000000000000000000000000000000000000000000", tx.Inputs[0].PrevTxid)
+	assert.Equal(t, uint32(0xffffffff), tx.Inputs[0].PrevIndex)
+	assert.Len(t, tx.Inputs[0].ScriptSig, 77)
+	assert.Nil(t, tx.Inputs[0].Witness)
+	assert.Len(t, tx.Outputs, 1)
+	assert.Equal(t, int64(5000000000), tx.Outputs[0].Value)
+	assert.Len(t, tx.Outputs[0].ScriptPubKey, 67)
+	assert.Equal(t, uint32(0), tx.Locktime)
+}
+
+func Test_Decode_Segwit(t *testing.T) {
+	tx, err := DecodeString(segwitHex)
+	assert.Nil(t, err)
+
+	assert.Equal(t, int32(2), tx.Version)
+	assert.True(t, tx.Segwit)
+	assert.Len(t, tx.Inputs, 1)
+	assert.Equal(t, "f7ede8a8acf5b3735f3e98086ea937c5f8a74f209682345491d73a33ac9bfd84", tx.Inputs[0].PrevTxid)
+	assert.Equal(t, uint32(1), tx.Inputs[0].PrevIndex)
+	assert.Empty(t, tx.Inputs[0].ScriptSig)
+	assert.Equal(t, uint32(0xfffffffd), tx.Inputs[0].Sequence)
+	assert.Len(t, tx.Inputs[0].Witness, 2)
+	assert.Len(t, tx.Inputs[0].Witness[1], 33)
+	assert.Len(t, tx.Outputs, 2)
+	assert.Equal(t, int64(150000), tx.Outputs[0].Value)
+	assert.Equal(t, int64(49850000), tx.Outputs[1].Value)
+	assert.Equal(t, uint32(10), tx.Locktime)
+}
+
+func Test_Decode_Error(t *testing.T) {
+	b, err := hex.DecodeString(segwitHex)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		title string
+		got   []byte
+		want  error
+	}{
+		{title: "empty", got: nil, want: ErrTruncated},
+		{title: "truncated", got: b[:len(b)-1], want: ErrTruncated},
+		{title: "trailing data", got: append(append([]byte{}, b...), 0x00), want: ErrTrailingData},
+		{title: "input count exceeds data", got: []byte{1, 0, 0, 0, 0xfe, 0xff, 0xff, 0xff, 0xff}, want: ErrTruncated},
+		{title: "non-canonical input count", got: append(append([]byte{}, b[:6]...), append([]byte{0xfd, 0x01, 0x00}, b[7:]...)...), want: ErrNonCanonical},
+		{title: "non-canonical script length", got: mustDecodeHex(t, strings.Replace(genesisCoinbaseHex, "ffffffff4d04", "fffffffffd4d0004", 1)), want: ErrNonCanonical},
+		{title: "non-canonical 4 byte count", got: []byte{1, 0, 0, 0, 0xfe, 0xff, 0xff, 0, 0}, want: ErrNonCanonical},
+		{title: "non-canonical 8 byte count", got: []byte{1, 0, 0, 0, 0xff, 0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0}, want: ErrNonCanonical},
+		{title: "no outputs", got: mustDecodeHex(t, "01000000"+"01"+strings.Repeat("00", 32)+"ffffffff"+"00"+"ffffffff"+"00"+"00000000"), want: ErrNoOutputs},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			_, err := Decode(tt.got)
+			assert.True(t, errors.Is(err, tt.want), "got %v", err)
+		})
+	}
+}
+
+func Test_DecodeString_Error_Hex(t *testing.T) {
+	_, err := DecodeString("zz")
+	assert.NotNil(t, err)
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func Test_Txid(t *testing.T) {
+	tests := []struct {
+		title     string
+		txHex     string
+		wantTxid  string
+		wantWtxid string
+	}{
+		{
+			title:     "legacy",
+			txHex:     genesisCoinbaseHex,
+			wantTxid:  "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b",
+			wantWtxid: "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b",
+		},
+		{
+			title:     "segwit",
+			txHex:     segwitHex,
+			wantTxid:  "79d1188c6480a21ad4d89c38569ba52fe8f372ce044a389dc8a285b773d25fe8",
+			wantWtxid: "a303bd03b4913e4601969f1bd1f2e3795889ff4b4b51ea77e3bdf2a5e6b02f41",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			tx, err := DecodeString(tt.txHex)
+			assert.Nil(t, err)
+
+			assert.Equal(t, tt.txHex, hex.EncodeToString(tx.Encode(true)))
+			assert.Equal(t, tt.wantTxid, tx.Txid())
+			assert.Equal(t, tt.wantWtxid, tx.Wtxid())
+		})
+	}
+}
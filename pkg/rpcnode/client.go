@@ -0,0 +1,512 @@
+// Package rpcnode implements sochain.Connector on top of the JSON-RPC interface of Bitcoin Core, Litecoin Core &
+// Dogecoin Core nodes. Transactions outside the mempool require the node to run with -txindex
+package rpcnode
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sochain-client/pkg/network"
+	"sochain-client/pkg/sochain"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// RPC error codes of the node
+const (
+	rpcInvalidParameter    = -8
+	rpcInvalidAddressOrKey = -5
+	rpcDeserialization     = -22
+	rpcVerify              = -25
+	rpcVerifyRejected      = -26
+	rpcVerifyAlreadyInUTXO = -27
+	rpcInWarmup            = -28
+)
+
+// Endpoint is the JSON-RPC interface of a node. Credentials are either User & Password or the CookieFile the node
+// writes on startup, e.g. ~/.bitcoin/.cookie. The cookie file is read on every call to pick up node restarts
+type Endpoint struct {
+	URL        string
+	User       string
+	Password   string
+	CookieFile string
+}
+
+type Node struct {
+	Client    *http.Client
+	endpoints map[string]Endpoint
+	timeout   time.Duration
+	networks  *network.Registry
+	id        uint64
+	// parallel calls of Transactions
+	concurrency int
+}
+
+func NewNode(opts ...Option) sochain.Connector {
+	n := &Node{
+		Client:      &http.Client{},
+		endpoints:   map[string]Endpoint{},
+		networks:    network.DefaultRegistry(),
+		concurrency: sochain.DefaultConcurrency,
+	}
+
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	if n.timeout > 0 {
+		client := *n.Client
+		client.Timeout = n.timeout
+		n.Client = &client
+	}
+
+	return n
+}
+
+// RPCError is an error returned by the node
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// NetworkInfo reports the chain tip, difficulty & mempool size, nodes serve no market data
+func (c *Node) NetworkInfo(ctx context.Context, networkID string) (*sochain.NetworkInfo, error) {
+
+	n, e, err := c.lookup(networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	var info blockchainInfo
+	if err := c.query(ctx, e, fmt.Sprintf("networkID '%s'", n.SochainID), &info, "getblockchaininfo"); err != nil {
+		return nil, err
+	}
+
+	var mempool mempoolInfo
+	if err := c.query(ctx, e, "mempool", &mempool, "getmempoolinfo"); err != nil {
+		return nil, err
+	}
+
+	return &sochain.NetworkInfo{
+		Status: "success",
+		Data: sochain.NetworkData{
+			Name:             n.Name,
+			Acronym:          n.SochainID,
+			Network:          n.SochainID,
+			MiningDifficulty: info.Difficulty.String(),
+			UnconfirmedTxs:   mempool.Size,
+			Blocks:           info.Blocks,
+		},
+	}, nil
+}
+
+func (c *Node) BlockHeight(ctx context.Context, networkID string, height int) (*sochain.Block, error) {
+
+	n, e, err := c.lookup(networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	var hash string
+	if err := c.query(ctx, e, fmt.Sprintf("height '%d'", height), &hash, "getblockhash", height); err != nil {
+		return nil, err
+	}
+
+	return c.block(ctx, n, e, hash)
+}
+
+func (c *Node) BlockHash(ctx context.Context, networkID, blockHash string) (*sochain.Block, error) {
+
+	n, e, err := c.lookup(networkID)
+	if err != nil {
+		return nil, err
+	}
+	if !n.ValidHash(blockHash) {
+		return nil, sochain.NewValidationErr(sochain.ErrInvalidHash, "blockhash", fmt.Errorf("invalid blockhash '%s' of network '%s'", blockHash, n.ID))
+	}
+
+	return c.block(ctx, n, e, blockHash)
+}
+
+// Transaction resolves the values & addresses of the inputs from the transactions they spend
+func (c *Node) Transaction(ctx context.Context, networkID, txHash string) (*sochain.Transaction, error) {
+
+	n, e, err := c.lookup(networkID)
+	if err != nil {
+		return nil, err
+	}
+	if !n.ValidHash(txHash) {
+		return nil, sochain.NewValidationErr(sochain.ErrInvalidHash, "txhash", fmt.Errorf("invalid txhash '%s' of network '%s'", txHash, n.ID))
+	}
+
+	t, err := c.rawTransaction(ctx, e, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	blockNo := 0
+	if t.Blockhash != "" {
+		var header blockHeader
+		if err := c.query(ctx, e, fmt.Sprintf("blockhash '%s'", t.Blockhash), &header, "getblockheader", t.Blockhash, true); err != nil {
+			return nil, err
+		}
+		blockNo = header.Height
+	}
+
+	prevTxs := map[string]*rawTx{}
+	var received sochain.Amount
+	inputs := make(sochain.Inputs, len(t.Vin))
+	for i, in := range t.Vin {
+		if in.Coinbase != "" {
+			inputs[i] = sochain.Input{InputNo: i, Address: "coinbase", ScriptHex: in.Coinbase, Witness: in.Txinwitness}
+			continue
+		}
+
+		prev, ok := prevTxs[in.Txid]
+		if !ok {
+			if prev, err = c.rawTransaction(ctx, e, in.Txid); err != nil {
+				return nil, err
+			}
+			prevTxs[in.Txid] = prev
+		}
+		if in.Vout >= len(prev.Vout) {
+			return nil, fmt.Errorf("input %d of tx '%s' spends missing output %d of tx '%s'", i, txHash, in.Vout, in.Txid)
+		}
+
+		out := prev.Vout[in.Vout]
+		if received, err = received.Add(out.Value); err != nil {
+			return nil, err
+		}
+
+		input := sochain.Input{
+			InputNo:      i,
+			Address:      out.ScriptPubKey.address(),
+			Value:        out.Value,
+			ReceivedFrom: &sochain.ReceivedFrom{Txid: in.Txid, OutputNo: in.Vout},
+			Witness:      in.Txinwitness,
+		}
+		if in.ScriptSig != nil {
+			input.ScriptAsm, input.ScriptHex = in.ScriptSig.Asm, in.ScriptSig.Hex
+		}
+		inputs[i] = input
+	}
+
+	var sent sochain.Amount
+	outputs := make(sochain.Outputs, len(t.Vout))
+	for i, out := range t.Vout {
+		if sent, err = sent.Add(out.Value); err != nil {
+			return nil, err
+		}
+
+		outputs[i] = sochain.Output{
+			OutputNo:  out.N,
+			Address:   out.ScriptPubKey.address(),
+			Value:     out.Value,
+			Type:      out.ScriptPubKey.Type,
+			ReqSigs:   out.ScriptPubKey.ReqSigs,
+			ScriptAsm: out.ScriptPubKey.Asm,
+			ScriptHex: out.ScriptPubKey.Hex,
+		}
+	}
+
+	var fee sochain.Amount
+	if received > sent {
+		fee = received - sent
+	}
+
+	vsize := t.Vsize
+	if vsize == 0 {
+		vsize = t.Size
+	}
+
+	return &sochain.Transaction{
+		Status: "success",
+		Data: sochain.TransactionData{
+			Network:       n.SochainID,
+			Txid:          t.Txid,
+			Blockhash:     t.Blockhash,
+			BlockNo:       blockNo,
+			Confirmations: t.Confirmations,
+			Time:          t.Time,
+			Size:          t.Size,
+			Vsize:         vsize,
+			Version:       t.Version,
+			Locktime:      t.Locktime,
+			SentValue:     sent,
+			Fee:           fee,
+			Inputs:        inputs,
+			Outputs:       outputs,
+			TxHex:         t.Hex,
+		},
+	}, nil
+}
+
+func (c *Node) Transactions(ctx context.Context, networkID string, hashes []string) ([]sochain.TxResult, error) {
+
+	if _, _, err := c.lookup(networkID); err != nil {
+		return nil, err
+	}
+
+	return sochain.FetchTransactions(ctx, hashes, c.concurrency, func(ctx context.Context, hash string) (*sochain.Transaction, error) {
+		return c.Transaction(ctx, networkID, hash)
+	}), nil
+}
+
+func (c *Node) AddressBalance(ctx context.Context, networkID, address string) (*sochain.AddressBalance, error) {
+	return nil, c.unsupportedAddress(networkID, address)
+}
+
+func (c *Node) ReceivedTransactions(ctx context.Context, networkID, address, afterTxid string) (*sochain.ReceivedTxs, error) {
+	return nil, c.unsupportedAddress(networkID, address)
+}
+
+// OutputSpender is unsupported, nodes index unspent outputs only & never the inputs spending an output
+func (c *Node) OutputSpender(ctx context.Context, networkID, txHash string, outputNo int) (*sochain.SpentBy, error) {
+
+	n, _, err := c.lookup(networkID)
+	if err != nil {
+		return nil, err
+	}
+	if !n.ValidHash(txHash) {
+		return nil, sochain.NewValidationErr(sochain.ErrInvalidHash, "txhash", fmt.Errorf("invalid txhash '%s' of network '%s'", txHash, n.ID))
+	}
+
+	return nil, sochain.NewClientErr(fmt.Errorf("spending inputs of network '%s' are not supported by nodes", n.ID), http.StatusNotImplemented)
+}
+
+func (c *Node) SpentTransactions(ctx context.Context, networkID, address, afterTxid string) (*sochain.SpentTxs, error) {
+	return nil, c.unsupportedAddress(networkID, address)
+}
+
+func (c *Node) UnspentOutputs(ctx context.Context, networkID, address, afterTxid string) (*sochain.UnspentOutputs, error) {
+	return nil, c.unsupportedAddress(networkID, address)
+}
+
+// BroadcastTransaction pushes a signed raw transaction to the node. Broadcasts are never retried
+func (c *Node) BroadcastTransaction(ctx context.Context, networkID, txHex string) (*sochain.BroadcastTx, error) {
+
+	n, e, err := c.lookup(networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	var txid string
+	if err := c.call(ctx, e, &txid, "sendrawtransaction", txHex); err != nil {
+		if rErr, ok := err.(*RPCError); ok {
+			switch rErr.Code {
+			case rpcDeserialization, rpcVerify, rpcVerifyRejected, rpcVerifyAlreadyInUTXO:
+				return nil, sochain.NewBroadcastErr(rErr.Message)
+			}
+			return nil, clientErr(rErr, fmt.Sprintf("broadcast network '%s'", n.SochainID))
+		}
+		return nil, err
+	}
+
+	return &sochain.BroadcastTx{
+		Status: "success",
+		Data:   sochain.BroadcastTxData{Network: n.SochainID, Txid: txid},
+	}, nil
+}
+
+// lookup resolves networkID in the network registry & its node. Unknown, disabled & networks without node are
+// returned as *sochain.ClientError matching sochain.ErrInvalidNetwork
+func (c *Node) lookup(networkID string) (network.Network, Endpoint, error) {
+	n, err := c.networks.Lookup(networkID)
+	if err != nil {
+		return network.Network{}, Endpoint{}, sochain.NewValidationErr(sochain.ErrInvalidNetwork, "network", err)
+	}
+
+	e, ok := c.endpoints[n.ID]
+	if !ok {
+		return network.Network{}, Endpoint{}, sochain.NewValidationErr(sochain.ErrInvalidNetwork, "network", fmt.Errorf("network '%s' has no node configured", n.ID))
+	}
+
+	return n, e, nil
+}
+
+// unsupportedAddress validates address & rejects the lookup, nodes keep no address index
+func (c *Node) unsupportedAddress(networkID, address string) error {
+	n, _, err := c.lookup(networkID)
+	if err != nil {
+		return err
+	}
+	if !n.ValidAddress(address) {
+		return sochain.NewValidationErr(sochain.ErrInvalidAddress, "address", fmt.Errorf("invalid address '%s' of network '%s'", address, n.ID))
+	}
+
+	return sochain.NewClientErr(fmt.Errorf("address lookups of network '%s' are not supported by nodes", n.ID), http.StatusNotImplemented)
+}
+
+func (c *Node) block(ctx context.Context, n network.Network, e Endpoint, hash string) (*sochain.Block, error) {
+
+	var b block
+	if err := c.query(ctx, e, fmt.Sprintf("blockhash '%s'", hash), &b, "getblock", hash, true); err != nil {
+		return nil, err
+	}
+
+	// blocks off the main chain have -1 confirmations
+	confirmations := b.Confirmations
+	if confirmations < 0 {
+		confirmations = 0
+	}
+
+	// bits are reported as hex
+	version, nonce := int32(b.Version), b.Nonce
+	var bits *uint32
+	if v, err := strconv.ParseUint(b.Bits, 16, 32); err == nil {
+		bits = new(uint32)
+		*bits = uint32(v)
+	}
+
+	return &sochain.Block{
+		Status: "success",
+		Data: sochain.BlockData{
+			Network:           n.SochainID,
+			Blockhash:         b.Hash,
+			BlockNo:           b.Height,
+			MiningDifficulty:  b.Difficulty.String(),
+			Time:              b.Time,
+			Confirmations:     confirmations,
+			IsOrphan:          b.Confirmations < 0,
+			Txs:               b.Tx,
+			Merkleroot:        b.Merkleroot,
+			PreviousBlockhash: b.PreviousBlockhash,
+			NextBlockhash:     b.NextBlockhash,
+			Size:              b.Size,
+			Version:           &version,
+			Bits:              bits,
+			Nonce:             &nonce,
+		},
+	}, nil
+}
+
+func (c *Node) rawTransaction(ctx context.Context, e Endpoint, txHash string) (*rawTx, error) {
+	var t rawTx
+	if err := c.query(ctx, e, fmt.Sprintf("txhash '%s'", txHash), &t, "getrawtransaction", txHash, true); err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// query performs call & returns errors of the node as *sochain.ClientError, subject describes the requested resource
+func (c *Node) query(ctx context.Context, e Endpoint, subject string, v interface{}, method string, params ...interface{}) error {
+	err := c.call(ctx, e, v, method, params...)
+	if rErr, ok := err.(*RPCError); ok {
+		return clientErr(rErr, subject)
+	}
+
+	return err
+}
+
+// clientErr maps an error of the node to the status of the resource
+func clientErr(rErr *RPCError, subject string) *sochain.ClientError {
+	status := http.StatusBadGateway
+	switch rErr.Code {
+	case rpcInvalidAddressOrKey:
+		status = http.StatusNotFound
+	case rpcInvalidParameter:
+		status = http.StatusBadRequest
+		if strings.Contains(strings.ToLower(rErr.Message), "out of range") {
+			status = http.StatusNotFound
+		}
+	case rpcInWarmup:
+		status = http.StatusServiceUnavailable
+	}
+
+	cErr := sochain.NewClientErr(fmt.Errorf("node rpc error %d, %s", rErr.Code, subject), status)
+	cErr.UpstreamCode = rErr.Code
+	cErr.Message = rErr.Message
+	return cErr
+}
+
+// call invokes method of the node bound to ctx & decodes the result into v. Errors reported by the node are returned as *RPCError
+func (c *Node) call(ctx context.Context, e Endpoint, v interface{}, method string, params ...interface{}) error {
+
+	if params == nil {
+		params = []interface{}{}
+	}
+	payload, err := json.Marshal(struct {
+		JSONRPC string        `json:"jsonrpc"`
+		ID      uint64        `json:"id"`
+		Method  string        `json:"method"`
+		Params  []interface{} `json:"params"`
+	}{"1.0", atomic.AddUint64(&c.id, 1), method, params})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	user, password, err := e.credentials()
+	if err != nil {
+		return err
+	}
+	if user != "" || password != "" {
+		req.SetBasicAuth(user, password)
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return sochain.NewClientErr(fmt.Errorf("node rejected credentials, statuscode %d, %s", resp.StatusCode, method), http.StatusBadGateway)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	// nodes respond to failed calls with 404 & 500, the body carries the error
+	var r struct {
+		Result json.RawMessage `json:"result"`
+		Error  *RPCError       `json:"error"`
+	}
+	if err := json.Unmarshal(body, &r); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return sochain.NewClientErr(fmt.Errorf("node response statuscode %d, %s", resp.StatusCode, method), http.StatusBadGateway)
+		}
+		return err
+	}
+	if r.Error != nil {
+		return r.Error
+	}
+
+	return json.Unmarshal(r.Result, v)
+}
+
+// credentials of e, the cookie file consists of user & password separated by a colon
+func (e Endpoint) credentials() (string, string, error) {
+	if e.CookieFile == "" {
+		return e.User, e.Password, nil
+	}
+
+	cookie, err := ioutil.ReadFile(e.CookieFile)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to read cookie file: %w", err)
+	}
+
+	kv := strings.SplitN(strings.TrimSpace(string(cookie)), ":", 2)
+	if len(kv) != 2 {
+		return "", "", fmt.Errorf("invalid cookie file '%s'", e.CookieFile)
+	}
+
+	return kv[0], kv[1], nil
+}
@@ -0,0 +1,179 @@
+package rpcnode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sochain-client/pkg/sochain"
+	"sochain-client/pkg/sochain/sochaintest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rpcFixture is the recorded result of a call, read from File or given inline by Result, or an error of the node
+type rpcFixture struct {
+	File   string
+	Result string
+	Err    *RPCError
+}
+
+// newRPCServer fakes a node serving fixtures keyed by path, method & params, e.g. `/ getblockhash [100000]`
+func newRPCServer(t *testing.T, user, password string, fixtures map[string]rpcFixture) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != user || p != password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid request: %s", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		key := r.URL.Path + " " + req.Method + " " + string(req.Params)
+		f, ok := fixtures[key]
+		if !ok {
+			t.Errorf("no fixture for %s", key)
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"result":null,"error":{"code":-32601,"message":"Method not found"},"id":` + string(req.ID) + `}`))
+			return
+		}
+
+		if f.Err != nil {
+			rErr, _ := json.Marshal(f.Err)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"result":null,"error":` + string(rErr) + `,"id":` + string(req.ID) + `}`))
+			return
+		}
+
+		result := []byte(f.Result)
+		if f.File != "" {
+			var err error
+			if result, err = ioutil.ReadFile(f.File); err != nil {
+				t.Errorf("unable to read fixture: %s", err)
+			}
+		}
+		w.Write([]byte(`{"result":` + string(result) + `,"error":null,"id":` + string(req.ID) + `}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func Test_Conformance(t *testing.T) {
+	srv := newRPCServer(t, "user", "password", map[string]rpcFixture{
+		"/ getblockchaininfo []":                                  {File: "testdata/getblockchaininfo.json"},
+		"/ getmempoolinfo []":                                     {File: "testdata/getmempoolinfo.json"},
+		"/ getblockhash [100000]":                                 {Result: `"` + sochaintest.BlockHash + `"`},
+		`/ getblock ["` + sochaintest.BlockHash + `",true]`:       {File: "testdata/getblock_100000.json"},
+		`/ getrawtransaction ["` + sochaintest.TxHash + `",true]`: {File: "testdata/getrawtransaction_genesis.json"},
+		`/ getblockheader ["000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f",true]`: {File: "testdata/getblockheader_genesis.json"},
+		`/ getrawtransaction ["` + sochaintest.MissingTxHash + `",true]`:                             {Err: &RPCError{Code: -5, Message: "No such mempool or blockchain transaction. Use gettransaction for wallet transactions."}},
+		`/ sendrawtransaction ["` + sochaintest.BroadcastTxHex + `"]`:                                {Result: `"` + sochaintest.BroadcastTxid + `"`},
+		`/btctest sendrawtransaction ["` + sochaintest.BroadcastTxHex + `"]`:                         {Err: &RPCError{Code: -27, Message: "Transaction already in block chain"}},
+	})
+
+	sochaintest.Run(t, NewNode(
+		WithEndpoint("btc", Endpoint{URL: srv.URL + "/", User: "user", Password: "password"}),
+		WithEndpoint("btctest", Endpoint{URL: srv.URL + "/btctest", User: "user", Password: "password"}),
+	))
+}
+
+func Test_CookieFile(t *testing.T) {
+	srv := newRPCServer(t, "__cookie__", "3a5f1c", map[string]rpcFixture{
+		"/ getblockhash [100000]":                           {Result: `"` + sochaintest.BlockHash + `"`},
+		`/ getblock ["` + sochaintest.BlockHash + `",true]`: {File: "testdata/getblock_100000.json"},
+	})
+
+	cookie := filepath.Join(t.TempDir(), ".cookie")
+	assert.Nil(t, ioutil.WriteFile(cookie, []byte("__cookie__:3a5f1c\n"), 0600))
+
+	n := NewNode(WithEndpoint("btc", Endpoint{URL: srv.URL, CookieFile: cookie}))
+	got, err := n.BlockHeight(context.Background(), "btc", 100000)
+	assert.Nil(t, err)
+	assert.Equal(t, sochaintest.BlockHash, got.Data.Blockhash)
+}
+
+func Test_Unauthorized(t *testing.T) {
+	srv := newRPCServer(t, "user", "password", nil)
+
+	n := NewNode(WithEndpoint("btc", Endpoint{URL: srv.URL, User: "user", Password: "wrong"}))
+	_, err := n.NetworkInfo(context.Background(), "btc")
+
+	var cErr *sochain.ClientError
+	assert.True(t, errors.As(err, &cErr))
+	assert.Equal(t, http.StatusBadGateway, cErr.Code())
+}
+
+func Test_BlockHeight_OutOfRange(t *testing.T) {
+	srv := newRPCServer(t, "user", "password", map[string]rpcFixture{
+		"/ getblockhash [900000]": {Err: &RPCError{Code: -8, Message: "Block height out of range"}},
+	})
+
+	n := NewNode(WithEndpoint("btc", Endpoint{URL: srv.URL, User: "user", Password: "password"}))
+	_, err := n.BlockHeight(context.Background(), "btc", 900000)
+	assert.ErrorIs(t, err, sochain.ErrNotFound)
+}
+
+func Test_Transaction_Inputs(t *testing.T) {
+	const (
+		txid = "0000000000000000000000000000000000000000000000000000000000000002"
+		prev = "0000000000000000000000000000000000000000000000000000000000000003"
+	)
+
+	srv := newRPCServer(t, "user", "password", map[string]rpcFixture{
+		`/ getrawtransaction ["` + txid + `",true]`: {Result: `{"txid":"` + txid + `","size":225,"vsize":144,"version":2,"locktime":0,"confirmations":0,
+			"vin":[{"txid":"` + prev + `","vout":1,"scriptSig":{"asm":"","hex":""},"txinwitness":["3044","02ab"],"sequence":4294967293}],
+			"vout":[{"value":0.1,"n":0,"scriptPubKey":{"hex":"0014ca978112ca1bbdcafac231b39a23dc4da786eff8","type":"witness_v0_keyhash","address":"bc1qe2tczy4jr0wu47kzxxue5g7ufkncdmlcdwepsg"}},
+			        {"value":0.19999856,"n":1,"scriptPubKey":{"hex":"a914d8b6fcc85a383261df05423ddf068a8987bf028787","type":"scripthash","addresses":["3MeAF7FFfXMk4PnmCsWMZDUwzH7Lv6bNsk"]}}]}`},
+		`/ getrawtransaction ["` + prev + `",true]`: {Result: `{"txid":"` + prev + `","vin":[],
+			"vout":[{"value":1.5,"n":0,"scriptPubKey":{"type":"nonstandard"}},{"value":0.30000000,"n":1,"scriptPubKey":{"type":"witness_v0_keyhash","address":"bc1qe2tczy4jr0wu47kzxxue5g7ufkncdmlcdwepsg"}}]}`},
+	})
+
+	n := NewNode(WithEndpoint("btc", Endpoint{URL: srv.URL, User: "user", Password: "password"}))
+	got, err := n.Transaction(context.Background(), "btc", txid)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	d := got.Data
+	assert.Equal(t, 0, d.BlockNo)
+	assert.Equal(t, 144, d.Vsize)
+	assert.Equal(t, sochain.Amount(29999856), d.SentValue)
+	assert.Equal(t, sochain.Amount(144), d.Fee)
+	if assert.Len(t, d.Inputs, 1) {
+		assert.Equal(t, "bc1qe2tczy4jr0wu47kzxxue5g7ufkncdmlcdwepsg", d.Inputs[0].Address)
+		assert.Equal(t, sochain.Amount(30000000), d.Inputs[0].Value)
+		assert.Equal(t, []string{"3044", "02ab"}, d.Inputs[0].Witness)
+	}
+	if assert.Len(t, d.Outputs, 2) {
+		assert.Equal(t, sochain.Amount(10000000), d.Outputs[0].Value)
+		assert.Equal(t, "3MeAF7FFfXMk4PnmCsWMZDUwzH7Lv6bNsk", d.Outputs[1].Address)
+	}
+}
+
+func Test_AddressUnsupported(t *testing.T) {
+	n := NewNode(WithEndpoint("btc", Endpoint{URL: "http://localhost"}))
+
+	_, err := n.AddressBalance(context.Background(), "btc", sochaintest.Address)
+	assert.ErrorIs(t, err, sochain.ErrUnsupported)
+
+	_, err = n.UnspentOutputs(context.Background(), "btc", "xyz", "")
+	assert.ErrorIs(t, err, sochain.ErrInvalidAddress)
+
+	_, err = n.UnspentOutputs(context.Background(), "ltc", sochaintest.Address, "")
+	assert.ErrorIs(t, err, sochain.ErrInvalidNetwork)
+}
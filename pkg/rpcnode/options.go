@@ -0,0 +1,46 @@
+package rpcnode
+
+import (
+	"net/http"
+	"sochain-client/pkg/network"
+	"strings"
+	"time"
+)
+
+// Option configures a Node client created by NewNode
+type Option func(*Node)
+
+// WithEndpoint sets the JSON-RPC endpoint of the node serving networkID
+func WithEndpoint(networkID string, e Endpoint) Option {
+	return func(n *Node) {
+		n.endpoints[strings.ToLower(networkID)] = e
+	}
+}
+
+// WithHTTPClient replaces the underlying http client. WithTimeout applies to a copy, c itself is never modified
+func WithHTTPClient(c *http.Client) Option {
+	return func(n *Node) {
+		n.Client = c
+	}
+}
+
+// WithTimeout limits the time of a single call including reading the response body
+func WithTimeout(d time.Duration) Option {
+	return func(n *Node) {
+		n.timeout = d
+	}
+}
+
+// WithNetworks validates network ids against r, defaults to network.DefaultRegistry
+func WithNetworks(r *network.Registry) Option {
+	return func(n *Node) {
+		n.networks = r
+	}
+}
+
+// WithConcurrency limits the parallel calls of Transactions to limit, default sochain.DefaultConcurrency
+func WithConcurrency(limit int) Option {
+	return func(n *Node) {
+		n.concurrency = limit
+	}
+}
@@ -0,0 +1,95 @@
+package rpcnode
+
+import (
+	"encoding/json"
+	"sochain-client/pkg/sochain"
+)
+
+type blockchainInfo struct {
+	Chain         string      `json:"chain"`
+	Blocks        int         `json:"blocks"`
+	Headers       int         `json:"headers"`
+	BestBlockHash string      `json:"bestblockhash"`
+	Difficulty    json.Number `json:"difficulty"`
+}
+
+type mempoolInfo struct {
+	Size int `json:"size"`
+}
+
+type block struct {
+	Hash              string      `json:"hash"`
+	Confirmations     int         `json:"confirmations"`
+	Size              int         `json:"size"`
+	Height            int         `json:"height"`
+	Version           int         `json:"version"`
+	Merkleroot        string      `json:"merkleroot"`
+	Tx                []string    `json:"tx"`
+	Time              int         `json:"time"`
+	Nonce             uint32      `json:"nonce"`
+	Bits              string      `json:"bits"`
+	Difficulty        json.Number `json:"difficulty"`
+	PreviousBlockhash string      `json:"previousblockhash"`
+	NextBlockhash     string      `json:"nextblockhash"`
+}
+
+type blockHeader struct {
+	Height int `json:"height"`
+}
+
+type rawTx struct {
+	Hex           string `json:"hex"`
+	Txid          string `json:"txid"`
+	Hash          string `json:"hash"`
+	Size          int    `json:"size"`
+	Vsize         int    `json:"vsize"`
+	Version       int    `json:"version"`
+	Locktime      int    `json:"locktime"`
+	Vin           []vin  `json:"vin"`
+	Vout          []vout `json:"vout"`
+	Blockhash     string `json:"blockhash"`
+	Confirmations int    `json:"confirmations"`
+	Time          int    `json:"time"`
+	Blocktime     int    `json:"blocktime"`
+}
+
+type vin struct {
+	// hex encoded coinbase script, set instead of Txid & ScriptSig on coinbase inputs
+	Coinbase    string     `json:"coinbase"`
+	Txid        string     `json:"txid"`
+	Vout        int        `json:"vout"`
+	ScriptSig   *scriptSig `json:"scriptSig"`
+	Txinwitness []string   `json:"txinwitness"`
+	Sequence    uint32     `json:"sequence"`
+}
+
+type scriptSig struct {
+	Asm string `json:"asm"`
+	Hex string `json:"hex"`
+}
+
+type vout struct {
+	Value        sochain.Amount `json:"value"`
+	N            int            `json:"n"`
+	ScriptPubKey scriptPubKey   `json:"scriptPubKey"`
+}
+
+type scriptPubKey struct {
+	Asm  string `json:"asm"`
+	Hex  string `json:"hex"`
+	Type string `json:"type"`
+	// set by nodes before Bitcoin Core 22
+	ReqSigs *int `json:"reqSigs"`
+	// set by Bitcoin Core 22 and later
+	Address string `json:"address"`
+	// set by older nodes, e.g. Dogecoin Core
+	Addresses []string `json:"addresses"`
+}
+
+func (s scriptPubKey) address() string {
+	if s.Address != "" || len(s.Addresses) == 0 {
+		return s.Address
+	}
+
+	return s.Addresses[0]
+}
@@ -0,0 +1,125 @@
+package sochain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// SatoshisPerCoin is the number of satoshis of a single coin
+const SatoshisPerCoin = 1e8
+
+// ErrAmountOverflow is returned by Amount arithmetic exceeding the range of int64 satoshis
+var ErrAmountOverflow = errors.New("amount overflow")
+
+// Amount is an exact amount of coins in satoshis. It's encoded to JSON the way sochain does, as a string of coins with 8 decimals,
+// and decoded from strings & numbers of coins
+type Amount int64
+
+// ParseAmount parses a decimal amount of coins, e.g. "0.001". Exponents & amounts below a satoshi are rejected
+func ParseAmount(s string) (Amount, error) {
+	_, frac, ok := splitDecimal(s)
+	if !ok {
+		return 0, fmt.Errorf("invalid amount '%s'", s)
+	}
+	if len(frac) > 8 {
+		return 0, fmt.Errorf("amount '%s' exceeds satoshi precision", s)
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, fmt.Errorf("invalid amount '%s'", s)
+	}
+
+	r.Mul(r, big.NewRat(SatoshisPerCoin, 1))
+	if !r.Num().IsInt64() {
+		return 0, fmt.Errorf("%w, '%s'", ErrAmountOverflow, s)
+	}
+
+	return Amount(r.Num().Int64()), nil
+}
+
+// Satoshis returns a as an integer number of satoshis
+func (a Amount) Satoshis() int64 {
+	return int64(a)
+}
+
+// String formats a as coins with 8 decimals
+func (a Amount) String() string {
+	sign := ""
+	sats := uint64(a)
+	if a < 0 {
+		sign, sats = "-", uint64(-(a+1))+1
+	}
+
+	return fmt.Sprintf("%s%d.%08d", sign, sats/SatoshisPerCoin, sats%SatoshisPerCoin)
+}
+
+// SatoshiString formats a as an integer number of satoshis
+func (a Amount) SatoshiString() string {
+	return strconv.FormatInt(int64(a), 10)
+}
+
+// Add returns a + b, ErrAmountOverflow if the sum exceeds int64 satoshis
+func (a Amount) Add(b Amount) (Amount, error) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return 0, fmt.Errorf("%w, %s + %s", ErrAmountOverflow, a, b)
+	}
+
+	return a + b, nil
+}
+
+// Sub returns a - b, ErrAmountOverflow if the difference exceeds int64 satoshis
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if (b < 0 && a > math.MaxInt64+b) || (b > 0 && a < math.MinInt64+b) {
+		return 0, fmt.Errorf("%w, %s - %s", ErrAmountOverflow, a, b)
+	}
+
+	return a - b, nil
+}
+
+// SumAmounts returns the sum of amounts, ErrAmountOverflow if it exceeds int64 satoshis
+func SumAmounts(amounts ...Amount) (Amount, error) {
+	var sum Amount
+	for _, a := range amounts {
+		var err error
+		if sum, err = sum.Add(a); err != nil {
+			return 0, err
+		}
+	}
+
+	return sum, nil
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON accepts coins as string or number, null & empty strings leave a unchanged
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	s := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		if s == "" {
+			return nil
+		}
+	}
+
+	v, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+
+	*a = v
+	return nil
+}
@@ -0,0 +1,104 @@
+package sochain
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseAmount(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Amount
+		wantErr bool
+	}{
+		{value: "50.00000000", want: 5000000000},
+		{value: "0.1", want: 10000000},
+		{value: "0.29999856", want: 29999856},
+		{value: "-0.00000001", want: -1},
+		{value: "0.10000000", want: 10000000},
+		{value: "21000000", want: 2100000000000000},
+		{value: "0.123456789", wantErr: true},
+		{value: "0.100000000", wantErr: true},
+		{value: "1e-08", wantErr: true},
+		{value: "1e999999999", wantErr: true},
+		{value: "+1", wantErr: true},
+		{value: ".5", wantErr: true},
+		{value: "5.", wantErr: true},
+		{value: "--1", wantErr: true},
+		{value: "1/3", wantErr: true},
+		{value: "100000000000", wantErr: true},
+		{value: "abc", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseAmount(tt.value)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_Amount_String(t *testing.T) {
+	assert.Equal(t, "0.00000000", Amount(0).String())
+	assert.Equal(t, "0.00005000", Amount(5000).String())
+	assert.Equal(t, "50.00000000", Amount(5000000000).String())
+	assert.Equal(t, "-1.00000001", Amount(-100000001).String())
+	assert.Equal(t, "-92233720368.54775808", Amount(math.MinInt64).String())
+	assert.Equal(t, "5000", Amount(5000).SatoshiString())
+	assert.Equal(t, int64(5000), Amount(5000).Satoshis())
+}
+
+func Test_Amount_JSON(t *testing.T) {
+	var v struct {
+		String Amount `json:"string"`
+		Number Amount `json:"number"`
+		Empty  Amount `json:"empty"`
+		Null   Amount `json:"null"`
+	}
+	err := json.Unmarshal([]byte(`{"string":"0.00100000","number":0.29999856,"empty":"","null":null}`), &v)
+	assert.Nil(t, err)
+	assert.Equal(t, Amount(100000), v.String)
+	assert.Equal(t, Amount(29999856), v.Number)
+	assert.Equal(t, Amount(0), v.Empty)
+	assert.Equal(t, Amount(0), v.Null)
+
+	b, err := json.Marshal(v)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"string":"0.00100000","number":"0.29999856","empty":"0.00000000","null":"0.00000000"}`, string(b))
+
+	var a Amount
+	assert.NotNil(t, json.Unmarshal([]byte(`"0.000000001"`), &a))
+	assert.NotNil(t, json.Unmarshal([]byte(`true`), &a))
+}
+
+func Test_Amount_Arithmetic(t *testing.T) {
+	sum, err := Amount(1).Add(2)
+	assert.Nil(t, err)
+	assert.Equal(t, Amount(3), sum)
+
+	diff, err := Amount(1).Sub(2)
+	assert.Nil(t, err)
+	assert.Equal(t, Amount(-1), diff)
+
+	_, err = Amount(math.MaxInt64).Add(1)
+	assert.True(t, errors.Is(err, ErrAmountOverflow))
+
+	_, err = Amount(math.MinInt64).Sub(1)
+	assert.True(t, errors.Is(err, ErrAmountOverflow))
+
+	sum, err = SumAmounts(1, 2, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, Amount(6), sum)
+
+	_, err = SumAmounts(math.MaxInt64, 1, -1)
+	assert.True(t, errors.Is(err, ErrAmountOverflow))
+}
@@ -0,0 +1,58 @@
+package sochain
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultConcurrency is the default limit of parallel requests of Connector.Transactions
+const DefaultConcurrency = 8
+
+// TxResult is the outcome of fetching one transaction of a batch, Err is set if Tx couldn't be fetched
+type TxResult struct {
+	Hash string
+	Tx   *Transaction
+	Err  error
+}
+
+// FetchTransactions calls fetch for every hash with at most concurrency calls in parallel, DefaultConcurrency if
+// concurrency isn't positive. Results are in the order of hashes, hashes not fetched before ctx is done fail with its
+// error
+func FetchTransactions(ctx context.Context, hashes []string, concurrency int, fetch func(ctx context.Context, hash string) (*Transaction, error)) []TxResult {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	if concurrency > len(hashes) {
+		concurrency = len(hashes)
+	}
+
+	results := make([]TxResult, len(hashes))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				tx, err := fetch(ctx, hashes[j])
+				results[j] = TxResult{Hash: hashes[j], Tx: tx, Err: err}
+			}
+		}()
+	}
+
+	for i, hash := range hashes {
+		if ctx.Err() == nil {
+			select {
+			case jobs <- i:
+				continue
+			case <-ctx.Done():
+			}
+		}
+		results[i] = TxResult{Hash: hash, Err: ctx.Err()}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
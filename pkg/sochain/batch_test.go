@@ -0,0 +1,74 @@
+package sochain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FetchTransactions(t *testing.T) {
+	hashes := make([]string, 50)
+	for i := range hashes {
+		hashes[i] = fmt.Sprint(i)
+	}
+
+	var inFlight, maxInFlight int32
+	results := FetchTransactions(context.Background(), hashes, 4, func(ctx context.Context, hash string) (*Transaction, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+
+		if hash == "7" {
+			return nil, errors.New("some")
+		}
+		return &Transaction{Data: TransactionData{Txid: hash}}, nil
+	})
+
+	assert.Equal(t, len(hashes), len(results))
+	for i, r := range results {
+		assert.Equal(t, hashes[i], r.Hash)
+		if i == 7 {
+			assert.NotNil(t, r.Err)
+			assert.Nil(t, r.Tx)
+			continue
+		}
+		assert.Nil(t, r.Err)
+		assert.Equal(t, hashes[i], r.Tx.Data.Txid)
+	}
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(4))
+	assert.Greater(t, atomic.LoadInt32(&maxInFlight), int32(1))
+
+	assert.Empty(t, FetchTransactions(context.Background(), nil, 4, nil))
+}
+
+func Test_FetchTransactions_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var calls int32
+	results := FetchTransactions(ctx, []string{"1", "2", "3", "4"}, 1, func(ctx context.Context, hash string) (*Transaction, error) {
+		atomic.AddInt32(&calls, 1)
+		if hash == "2" {
+			cancel()
+			return nil, ctx.Err()
+		}
+		return &Transaction{}, nil
+	})
+
+	assert.Nil(t, results[0].Err)
+	for _, r := range results[1:] {
+		assert.True(t, errors.Is(r.Err, context.Canceled))
+	}
+	// at most the fetch handed to the worker before it noticed the cancellation
+	assert.LessOrEqual(t, atomic.LoadInt32(&calls), int32(3))
+}
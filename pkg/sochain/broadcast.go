@@ -0,0 +1,160 @@
+package sochain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+type BroadcastTx struct {
+	Status string          `json:"status"`
+	Data   BroadcastTxData `json:"data"`
+}
+
+type BroadcastTxData struct {
+	Network string `json:"network"`
+	Txid    string `json:"txid"`
+}
+
+type BroadcastResponse struct {
+	Network string `json:"network"`
+	TxID    string `json:"txid"`
+}
+
+func (b BroadcastTx) Response() BroadcastResponse {
+	return BroadcastResponse{
+		Network: b.Data.Network,
+		TxID:    b.Data.Txid,
+	}
+}
+
+// RejectReason classifies why a node refused to accept a transaction
+type RejectReason string
+
+const (
+	RejectInvalid         RejectReason = "invalid"
+	RejectAlreadyKnown    RejectReason = "already_known"
+	RejectMissingInputs   RejectReason = "missing_inputs"
+	RejectInsufficientFee RejectReason = "insufficient_fee"
+	RejectNonStandard     RejectReason = "non_standard"
+)
+
+// Substrings of node reject messages per reason, checked in order
+var rejectPatterns = []struct {
+	reason   RejectReason
+	patterns []string
+}{
+	{RejectAlreadyKnown, []string{"already in block chain", "already-known", "already-in-mempool", "already in the mempool", "already known"}},
+	{RejectMissingInputs, []string{"missing inputs", "missingorspent", "inputs-spent", "mempool-conflict", "double spend"}},
+	{RejectInsufficientFee, []string{"min relay fee not met", "mempool min fee not met", "insufficient fee", "insufficient priority"}},
+	{RejectNonStandard, []string{"non-standard", "nonstandard", "dust", "non-final", "non-bip68-final", "tx-size", "scriptpubkey"}},
+}
+
+// ClassifyRejection maps the reject message of a node to a RejectReason, unknown messages are RejectInvalid
+func ClassifyRejection(message string) RejectReason {
+	m := strings.ToLower(message)
+	for _, r := range rejectPatterns {
+		for _, p := range r.patterns {
+			if strings.Contains(m, p) {
+				return r.reason
+			}
+		}
+	}
+
+	return RejectInvalid
+}
+
+// BroadcastError is returned if the upstream node rejects a transaction
+type BroadcastError struct {
+	Reason  RejectReason
+	Message string
+}
+
+func (b *BroadcastError) Error() string {
+	return fmt.Sprintf("transaction rejected (%s): %s", b.Reason, b.Message)
+}
+
+func NewBroadcastErr(message string) *BroadcastError {
+	return &BroadcastError{
+		Reason:  ClassifyRejection(message),
+		Message: message,
+	}
+}
+
+// BroadcastTransaction pushes a signed raw transaction to the network. Broadcasts are never retried
+func (c *Sochain) BroadcastTransaction(ctx context.Context, networkID, txHex string) (*BroadcastTx, error) {
+
+	n, err := c.lookup(networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/send_tx/%s", c.baseUrl, n.SochainID)
+
+	payload, err := json.Marshal(map[string]string{"tx_hex": txHex})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.limiter.wait(ctx, n.SochainID); err != nil {
+		return nil, err
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return nil, NewBroadcastErr(failMessage(body))
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, NewClientErr(fmt.Errorf("sochain response statuscode %d, send_tx network '%s'", resp.StatusCode, n.SochainID), resp.StatusCode)
+	}
+
+	var tx BroadcastTx
+	if err := json.Unmarshal(body, &tx); err != nil {
+		return nil, err
+	}
+
+	if tx.Status == "fail" {
+		return nil, NewBroadcastErr(failMessage(body))
+	}
+
+	return &tx, nil
+}
+
+// failMessage extracts the reason of a sochain "fail" response, the tx_hex parameter message takes precedence
+func failMessage(body []byte) string {
+	var fail failBody
+	if err := json.Unmarshal(body, &fail); err != nil {
+		return strings.TrimSpace(string(body))
+	}
+
+	var fields map[string]string
+	if err := json.Unmarshal(fail.Data, &fields); err == nil {
+		if v, ok := fields["tx_hex"]; ok {
+			return v
+		}
+	}
+
+	_, msg := fail.reason()
+	return msg
+}
@@ -0,0 +1,127 @@
+package sochain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BroadcastTransaction_Success(t *testing.T) {
+	var gotBody map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/send_tx/BTC", r.URL.Path)
+
+		b, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(b, &gotBody)
+		w.Write([]byte(`{"status":"success","data":{"network":"BTC","txid":"txid"}}`))
+	}))
+	defer srv.Close()
+
+	s := NewSochain(WithBaseURL(srv.URL))
+	got, err := s.BroadcastTransaction(context.Background(), "BTC", "0100")
+	assert.Nil(t, err)
+	assert.Equal(t, "txid", got.Data.Txid)
+	assert.Equal(t, "0100", gotBody["tx_hex"])
+}
+
+func Test_BroadcastTransaction_Error_Rejected(t *testing.T) {
+	tests := []struct {
+		title      string
+		statuscode int
+		body       string
+		want       RejectReason
+	}{
+		{
+			title:      "already known",
+			statuscode: http.StatusBadRequest,
+			body:       `{"status":"fail","data":{"network":"BTC","tx_hex":"Transaction already in block chain"}}`,
+			want:       RejectAlreadyKnown,
+		},
+		{
+			title:      "missing inputs",
+			statuscode: http.StatusBadRequest,
+			body:       `{"status":"fail","data":{"tx_hex":"bad-txns-inputs-missingorspent"}}`,
+			want:       RejectMissingInputs,
+		},
+		{
+			title:      "fee in status 200 fail response",
+			statuscode: http.StatusOK,
+			body:       `{"status":"fail","data":{"tx_hex":"min relay fee not met"}}`,
+			want:       RejectInsufficientFee,
+		},
+		{
+			title:      "fee too high is no insufficient fee",
+			statuscode: http.StatusBadRequest,
+			body:       `{"status":"fail","data":{"tx_hex":"absurdly-high-fee, 1000000 > 100000"}}`,
+			want:       RejectInvalid,
+		},
+		{
+			title:      "invalid message field",
+			statuscode: http.StatusUnprocessableEntity,
+			body:       `{"status":"fail","message":"TX decode failed"}`,
+			want:       RejectInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statuscode)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			s := NewSochain(WithBaseURL(srv.URL))
+			_, err := s.BroadcastTransaction(context.Background(), "BTC", "0100")
+
+			var bErr *BroadcastError
+			assert.True(t, errors.As(err, &bErr))
+			assert.Equal(t, tt.want, bErr.Reason)
+		})
+	}
+}
+
+func Test_BroadcastTransaction_Error_StatusCode(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	s := NewSochain(WithBaseURL(srv.URL), WithRetryPolicy(testRetryPolicy()))
+	_, err := s.BroadcastTransaction(context.Background(), "BTC", "0100")
+
+	var cErr *ClientError
+	assert.True(t, errors.As(err, &cErr))
+	assert.Equal(t, http.StatusServiceUnavailable, cErr.Code())
+	assert.Equal(t, 1, calls)
+}
+
+func Test_ClassifyRejection(t *testing.T) {
+	tests := map[string]RejectReason{
+		"txn-already-in-mempool":                  RejectAlreadyKnown,
+		"txn-mempool-conflict":                    RejectMissingInputs,
+		"Missing inputs":                          RejectMissingInputs,
+		"mempool min fee not met":                 RejectInsufficientFee,
+		"min relay fee not met, 0 < 141":          RejectInsufficientFee,
+		"insufficient fee, rejecting replacement": RejectInsufficientFee,
+		"bad-txns-fee-outofrange":                 RejectInvalid,
+		"absurdly-high-fee":                       RejectInvalid,
+		"max-fee-exceeded":                        RejectInvalid,
+		"dust":                                    RejectNonStandard,
+		"non-mandatory-script-verify-flag":        RejectInvalid,
+		"":                                        RejectInvalid,
+	}
+
+	for message, want := range tests {
+		assert.Equal(t, want, ClassifyRejection(message), message)
+	}
+}
@@ -1,137 +1,397 @@
 package sochain
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"sochain-client/pkg/network"
+	"time"
+
+	"go.uber.org/zap"
 )
 
-var apiURL = "https://sochain.com/api/v2"
+const apiURL = "https://sochain.com/api/v2"
 
 type Sochain struct {
-	Client  *http.Client
-	baseUrl string
+	Client    *http.Client
+	baseUrl   string
+	transport http.RoundTripper
+	timeout   time.Duration
+	userAgent string
+	header    http.Header
+	retry     RetryPolicy
+	// reports retried attempts
+	retryObserver func(networkID string, attempt int, err error)
+	limiter       *rateLimiter
+	verifyTx      bool
+	// check merkle roots of fetched blocks
+	verifyMerkle bool
+	// parallel requests of Transactions
+	concurrency int
+	networks    *network.Registry
+	logger      *zap.Logger
 }
 
-func NewSochain() Connector {
-	return &Sochain{
-		Client:  &http.Client{},
-		baseUrl: apiURL,
+func NewSochain(opts ...Option) Connector {
+	s := &Sochain{
+		Client:      &http.Client{},
+		baseUrl:     apiURL,
+		header:      http.Header{},
+		limiter:     newRateLimiter(),
+		concurrency: DefaultConcurrency,
+		networks:    network.DefaultRegistry(),
+		logger:      zap.NewNop(),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.limiter.init()
+
+	if s.transport != nil || s.timeout > 0 {
+		client := *s.Client
+		if s.transport != nil {
+			client.Transport = s.transport
+		}
+		if s.timeout > 0 {
+			client.Timeout = s.timeout
+		}
+		s.Client = &client
 	}
+
+	return s
 }
 
+// Connector fetches blockchain data. Every call is bound to ctx, cancelling ctx aborts the upstream request.
 type Connector interface {
-	NetworkInfo(networkID string) (*NetworkInfo, error)
-	BlockHeight(networkID string, height int) (*Block, error)
-	BlockHash(networkID, blockHash string) (*Block, error)
-	Transaction(networkID, txHash string) (*Transaction, error)
+	NetworkInfo(ctx context.Context, networkID string) (*NetworkInfo, error)
+	BlockHeight(ctx context.Context, networkID string, height int) (*Block, error)
+	BlockHash(ctx context.Context, networkID, blockHash string) (*Block, error)
+	Transaction(ctx context.Context, networkID, txHash string) (*Transaction, error)
+	// Transactions fetches hashes with bounded concurrency. Results are in the order of hashes with an error per hash,
+	// the error is returned for batches failing as a whole, e.g. of an unknown network
+	Transactions(ctx context.Context, networkID string, hashes []string) ([]TxResult, error)
+	// OutputSpender returns the input spending output outputNo of txHash, nil while unspent. Providers that don't know
+	// spending inputs fail with ErrUnsupported. Spends change after a transaction is final, they are never cached
+	OutputSpender(ctx context.Context, networkID, txHash string, outputNo int) (*SpentBy, error)
+	AddressBalance(ctx context.Context, networkID, address string) (*AddressBalance, error)
+	// Address transaction lists are paginated, afterTxid continues a list after the given txid. Empty afterTxid returns the first page
+	ReceivedTransactions(ctx context.Context, networkID, address, afterTxid string) (*ReceivedTxs, error)
+	SpentTransactions(ctx context.Context, networkID, address, afterTxid string) (*SpentTxs, error)
+	UnspentOutputs(ctx context.Context, networkID, address, afterTxid string) (*UnspentOutputs, error)
+	// Rejected transactions are returned as *BroadcastError
+	BroadcastTransaction(ctx context.Context, networkID, txHex string) (*BroadcastTx, error)
 }
 
-func (c *Sochain) NetworkInfo(networkID string) (*NetworkInfo, error) {
+func (c *Sochain) NetworkInfo(ctx context.Context, networkID string) (*NetworkInfo, error) {
 
-	url := fmt.Sprintf("%s/get_info/%s", apiURL, networkID)
-	req, err := http.NewRequest("GET", url, nil)
+	n, err := c.lookup(networkID)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.Client.Do(req)
+	url := fmt.Sprintf("%s/get_info/%s", c.baseUrl, n.SochainID)
+
+	var info NetworkInfo
+	if err := c.get(ctx, n.SochainID, url, fmt.Sprintf("networkID '%s'", n.SochainID), &info); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+func (c *Sochain) BlockHeight(ctx context.Context, networkID string, height int) (*Block, error) {
+
+	n, err := c.lookup(networkID)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, NewClientErr(fmt.Errorf("sochain response statuscode %d, networkID '%s'", resp.StatusCode, networkID), resp.StatusCode)
+	url := fmt.Sprintf("%s/get_block/%s/%d", c.baseUrl, n.SochainID, height)
+
+	var b Block
+	if err := c.get(ctx, n.SochainID, url, fmt.Sprintf("height '%d'", height), &b); err != nil {
+		return nil, err
+	}
+	if c.verifyMerkle {
+		if err := b.Data.VerifyMerkleRoot(); err != nil {
+			return nil, err
+		}
 	}
 
-	body, err := ioutil.ReadAll(resp.Body)
+	return &b, nil
+}
+
+func (c *Sochain) BlockHash(ctx context.Context, networkID, blockHash string) (*Block, error) {
+
+	n, err := c.lookup(networkID)
 	if err != nil {
 		return nil, err
 	}
+	if !n.ValidHash(blockHash) {
+		return nil, NewValidationErr(ErrInvalidHash, "blockhash", fmt.Errorf("invalid blockhash '%s' of network '%s'", blockHash, n.ID))
+	}
 
-	var info NetworkInfo
-	if err := json.Unmarshal(body, &info); err != nil {
+	url := fmt.Sprintf("%s/get_block/%s/%s", c.baseUrl, n.SochainID, blockHash)
+
+	var b Block
+	if err := c.get(ctx, n.SochainID, url, fmt.Sprintf("blockhash '%s'", blockHash), &b); err != nil {
 		return nil, err
 	}
+	if c.verifyMerkle {
+		if err := b.Data.VerifyMerkleRoot(); err != nil {
+			return nil, err
+		}
+	}
 
-	return &info, nil
+	return &b, nil
 }
 
-func (c *Sochain) BlockHeight(networkID string, height int) (*Block, error) {
+func (c *Sochain) Transaction(ctx context.Context, networkID, txHash string) (*Transaction, error) {
 
-	url := fmt.Sprintf("%s/get_block/%s/%d", apiURL, networkID, height)
-	resp, err := http.Get(url)
+	n, err := c.lookup(networkID)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	if !n.ValidHash(txHash) {
+		return nil, NewValidationErr(ErrInvalidHash, "txhash", fmt.Errorf("invalid txhash '%s' of network '%s'", txHash, n.ID))
+	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, NewClientErr(fmt.Errorf("sochain response statuscode %d, height '%d'", resp.StatusCode, height), resp.StatusCode)
+	url := fmt.Sprintf("%s/tx/%s/%s", c.baseUrl, n.SochainID, txHash)
+
+	var tx Transaction
+	if err := c.get(ctx, n.SochainID, url, fmt.Sprintf("txhash '%s'", txHash), &tx); err != nil {
+		return nil, err
+	}
+	if c.verifyTx {
+		if err := VerifyTx(txHash, tx.Data); err != nil {
+			return nil, err
+		}
 	}
 
-	body, err := ioutil.ReadAll(resp.Body)
+	return &tx, nil
+}
+
+func (c *Sochain) Transactions(ctx context.Context, networkID string, hashes []string) ([]TxResult, error) {
+
+	if _, err := c.lookup(networkID); err != nil {
+		return nil, err
+	}
+
+	return FetchTransactions(ctx, hashes, c.concurrency, func(ctx context.Context, hash string) (*Transaction, error) {
+		return c.Transaction(ctx, networkID, hash)
+	}), nil
+}
+
+// OutputSpender looks the output up in its transaction, sochain reports the spending input of every output
+func (c *Sochain) OutputSpender(ctx context.Context, networkID, txHash string, outputNo int) (*SpentBy, error) {
+
+	tx, err := c.Transaction(ctx, networkID, txHash)
 	if err != nil {
 		return nil, err
 	}
 
-	var b Block
-	if err := json.Unmarshal(body, &b); err != nil {
+	for _, out := range tx.Data.Outputs {
+		if out.OutputNo == outputNo {
+			return out.Spent, nil
+		}
+	}
+
+	return nil, NewClientErr(fmt.Errorf("output %d of txhash '%s' not found", outputNo, txHash), http.StatusNotFound)
+}
+
+func (c *Sochain) AddressBalance(ctx context.Context, networkID, address string) (*AddressBalance, error) {
+
+	n, err := c.lookup(networkID)
+	if err != nil {
+		return nil, err
+	}
+	if !n.ValidAddress(address) {
+		return nil, NewValidationErr(ErrInvalidAddress, "address", fmt.Errorf("invalid address '%s' of network '%s'", address, n.ID))
+	}
+
+	url := fmt.Sprintf("%s/get_address_balance/%s/%s", c.baseUrl, n.SochainID, address)
+
+	var b AddressBalance
+	if err := c.get(ctx, n.SochainID, url, fmt.Sprintf("address '%s'", address), &b); err != nil {
 		return nil, err
 	}
 
 	return &b, nil
 }
 
-func (c *Sochain) BlockHash(networkID, blockHash string) (*Block, error) {
-	url := fmt.Sprintf("%s/get_block/%s/%s", apiURL, networkID, blockHash)
-	resp, err := http.Get(url)
+func (c *Sochain) ReceivedTransactions(ctx context.Context, networkID, address, afterTxid string) (*ReceivedTxs, error) {
+
+	n, err := c.lookup(networkID)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	if !n.ValidAddress(address) {
+		return nil, NewValidationErr(ErrInvalidAddress, "address", fmt.Errorf("invalid address '%s' of network '%s'", address, n.ID))
+	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, NewClientErr(fmt.Errorf("sochain response statuscode %d, blockhash '%s'", resp.StatusCode, blockHash), resp.StatusCode)
+	url := addressTxsURL(c.baseUrl, "get_tx_received", n.SochainID, address, afterTxid)
+
+	var txs ReceivedTxs
+	if err := c.get(ctx, n.SochainID, url, fmt.Sprintf("address '%s'", address), &txs); err != nil {
+		return nil, err
 	}
 
-	body, err := ioutil.ReadAll(resp.Body)
+	return &txs, nil
+}
+
+func (c *Sochain) SpentTransactions(ctx context.Context, networkID, address, afterTxid string) (*SpentTxs, error) {
+
+	n, err := c.lookup(networkID)
 	if err != nil {
 		return nil, err
 	}
+	if !n.ValidAddress(address) {
+		return nil, NewValidationErr(ErrInvalidAddress, "address", fmt.Errorf("invalid address '%s' of network '%s'", address, n.ID))
+	}
 
-	var b Block
-	if err := json.Unmarshal(body, &b); err != nil {
+	url := addressTxsURL(c.baseUrl, "get_tx_spent", n.SochainID, address, afterTxid)
+
+	var txs SpentTxs
+	if err := c.get(ctx, n.SochainID, url, fmt.Sprintf("address '%s'", address), &txs); err != nil {
 		return nil, err
 	}
 
-	return &b, nil
+	return &txs, nil
 }
 
-func (c *Sochain) Transaction(networkID, txHash string) (*Transaction, error) {
+func (c *Sochain) UnspentOutputs(ctx context.Context, networkID, address, afterTxid string) (*UnspentOutputs, error) {
 
-	url := fmt.Sprintf("%s/tx/%s/%s", apiURL, networkID, txHash)
-	resp, err := http.Get(url)
+	n, err := c.lookup(networkID)
 	if err != nil {
 		return nil, err
 	}
+	if !n.ValidAddress(address) {
+		return nil, NewValidationErr(ErrInvalidAddress, "address", fmt.Errorf("invalid address '%s' of network '%s'", address, n.ID))
+	}
+
+	url := addressTxsURL(c.baseUrl, "get_tx_unspent", n.SochainID, address, afterTxid)
+
+	var outputs UnspentOutputs
+	if err := c.get(ctx, n.SochainID, url, fmt.Sprintf("address '%s'", address), &outputs); err != nil {
+		return nil, err
+	}
+
+	return &outputs, nil
+}
+
+// lookup resolves networkID in the network registry, unknown & disabled networks are returned as *ClientError matching ErrInvalidNetwork
+func (c *Sochain) lookup(networkID string) (network.Network, error) {
+	n, err := c.networks.Lookup(networkID)
+	if err != nil {
+		return network.Network{}, NewValidationErr(ErrInvalidNetwork, "network", err)
+	}
+
+	return n, nil
+}
+
+// addressTxsURL appends the optional pagination cursor afterTxid to the url of an address transaction list
+func addressTxsURL(baseURL, endpoint, networkID, address, afterTxid string) string {
+	url := fmt.Sprintf("%s/%s/%s/%s", baseURL, endpoint, networkID, address)
+	if afterTxid != "" {
+		url += "/" + afterTxid
+	}
+
+	return url
+}
+
+// get requests url through c.Client bound to ctx and decodes the JSON body into v. Failed attempts are retried according to c.retry.
+// Non 200 and "fail" responses are returned as *ClientError, subject describes the requested resource.
+func (c *Sochain) get(ctx context.Context, networkID, url, subject string, v interface{}) error {
+
+	for attempt := 1; ; attempt++ {
+		retryable, retryAfter, err := c.getOnce(ctx, networkID, url, subject, v)
+		if err == nil {
+			return nil
+		}
+
+		if !retryable || ctx.Err() != nil || attempt >= c.retry.MaxAttempts {
+			if attempt > 1 {
+				return &RetryError{Attempts: attempt, Err: err}
+			}
+			return err
+		}
+
+		delay := c.retry.backoff(attempt, retryAfter)
+		if c.retry.MaxDelay > 0 && delay > c.retry.MaxDelay {
+			c.logger.Warn("sochain Retry-After exceeds max retry delay", zap.String("url", url), zap.Duration("retryAfter", delay), zap.Error(err))
+			return &RetryError{Attempts: attempt, Err: err}
+		}
+
+		if c.retryObserver != nil {
+			c.retryObserver(networkID, attempt, err)
+		}
+		c.logger.Info("retrying sochain request", zap.String("url", url), zap.Int("attempt", attempt), zap.Duration("delay", delay), zap.Error(err))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return &RetryError{Attempts: attempt, Err: ctx.Err()}
+		case <-timer.C:
+		}
+	}
+}
+
+// getOnce performs a single attempt of get after waiting on the rate limiter of networkID. retryable reports whether the failed attempt
+// may be repeated, retryAfter is the delay requested by the upstream Retry-After header
+func (c *Sochain) getOnce(ctx context.Context, networkID, url, subject string, v interface{}) (retryable bool, retryAfter time.Duration, err error) {
+
+	if err := c.limiter.wait(ctx, networkID); err != nil {
+		return false, 0, err
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, 0, err
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return true, 0, err
+	}
 	defer resp.Body.Close()
 
+	body, err := ioutil.ReadAll(resp.Body)
+
 	if resp.StatusCode != http.StatusOK {
-		return nil, NewClientErr(fmt.Errorf("sochain response statuscode %d, txhash '%s'", resp.StatusCode, txHash), resp.StatusCode)
+		err := newFailErr(fmt.Errorf("sochain response statuscode %d, %s", resp.StatusCode, subject), resp.StatusCode, body)
+		return c.retry.retryableStatus(resp.StatusCode), parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()), err
+	}
+	if err != nil {
+		return true, 0, err
 	}
 
-	body, err := ioutil.ReadAll(resp.Body)
+	var fail failBody
+	if err := json.Unmarshal(body, &fail); err == nil && fail.Status == "fail" {
+		return false, 0, newFailErr(fmt.Errorf("sochain response status fail, %s", subject), http.StatusBadRequest, body)
+	}
+
+	return false, 0, json.Unmarshal(body, v)
+}
+
+// newRequest creates a request bound to ctx carrying the configured headers
+func (c *Sochain) newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, err
 	}
 
-	var tx Transaction
-	if err := json.Unmarshal(body, &tx); err != nil {
-		return nil, err
+	for k, values := range c.header {
+		req.Header[k] = values
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
 	}
 
-	return &tx, nil
+	return req, nil
 }
@@ -1,233 +1,294 @@
 package sochain
 
 import (
-	"reflect"
-	"strconv"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sochain-client/pkg/httpreplay"
+	"sochain-client/pkg/network"
+	"sync/atomic"
 	"testing"
+	"time"
 
-	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/assert"
 )
 
-func Test_NetworkInfo_Success(t *testing.T) {
-	httpmock.Activate()
-	defer httpmock.DeactivateAndReset()
-
-	want := NetworkInfo{
-		Status: "test",
-		Data: NetworkData{
-			Name:    "test",
-			Acronym: "test",
-			Network: "btc",
-		},
-	}
+// Chain data of the fixtures, see sochaintest
+const (
+	testBlockHash        = "000000000003ba27aa200b1cecaad478d2b00432346c3f1f3986da1afd33e506"
+	testMissingBlockHash = "0000000000000000000000000000000000000000000000000000000000000001"
+	testAddress          = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+	testDonationTxHash   = "3433844a443e80708d701b27c3442d17911a71d7269f6495309237829d82fbc3"
+)
 
-	gotNetwork := "btc"
+// newReplaySochain replays the fixture testdata/replay/<test>.json, HTTPREPLAY=record records it from the sochain API
+func newReplaySochain(t *testing.T) Connector {
+	return NewSochain(WithTransport(httpreplay.ForTest(t, filepath.Join("testdata", "replay", t.Name()+".json"))))
+}
 
-	httpmock.RegisterResponder("GET", "https://sochain.com/api/v2/get_info/"+gotNetwork,
-		httpmock.NewJsonResponderOrPanic(200, want))
+// newFaultSochain replays the fixture testdata/replay/<test>.json of a fault the sochain API can't be made to respond
+// with, it's written by hand & never recorded
+func newFaultSochain(t *testing.T) Connector {
+	return NewSochain(WithTransport(httpreplay.ReplayForTest(t, filepath.Join("testdata", "replay", t.Name()+".json"))))
+}
 
-	s := NewSochain()
-	got, err := s.NetworkInfo(gotNetwork)
+func Test_NetworkInfo_Success(t *testing.T) {
+	s := newReplaySochain(t)
+	got, err := s.NetworkInfo(context.Background(), "btc")
 	assert.Nil(t, err)
 
-	assert.True(t, reflect.DeepEqual(*got, want))
+	assert.Equal(t, "success", got.Status)
+	assert.Equal(t, "Bitcoin", got.Data.Name)
+	assert.Equal(t, "BTC", got.Data.Network)
+	// the tip of a recording is at least the tip of the hand-written fixture
+	assert.GreaterOrEqual(t, got.Data.Blocks, 800000)
 }
 
 func Test_NetworkInfo_Error_StatusCode(t *testing.T) {
-	httpmock.Activate()
-	defer httpmock.DeactivateAndReset()
-
-	gotNetwork := "btc"
-	httpmock.RegisterResponder("GET", "https://sochain.com/api/v2/get_info/"+gotNetwork,
-		httpmock.NewStringResponder(500, ""))
+	s := newFaultSochain(t)
+	_, err := s.NetworkInfo(context.Background(), "btc")
+	assert.Equal(t, 500, StatusCode(err))
+}
 
-	s := NewSochain()
-	_, err := s.NetworkInfo(gotNetwork)
+func Test_NetworkInfo_Error_Unmarshal(t *testing.T) {
+	s := newFaultSochain(t)
+	_, err := s.NetworkInfo(context.Background(), "btc")
 	assert.NotNil(t, err)
 }
 
-func Test_NetworkInfo_Error_Unmarshal(t *testing.T) {
-	httpmock.Activate()
-	defer httpmock.DeactivateAndReset()
+func Test_BlockHeight_Success(t *testing.T) {
+	s := newReplaySochain(t)
+	got, err := s.BlockHeight(context.Background(), "btc", 100000)
+	assert.Nil(t, err)
 
-	gotNetwork := "btc"
+	assert.Equal(t, "BTC", got.Data.Network)
+	assert.Equal(t, 100000, got.Data.BlockNo)
+	assert.Equal(t, testBlockHash, got.Data.Blockhash)
+	assert.Len(t, got.Data.Txs, 4)
+	assert.Nil(t, got.Data.VerifyMerkleRoot())
+}
 
-	httpmock.RegisterResponder("GET", "https://sochain.com/api/v2/get_info/"+gotNetwork,
-		httpmock.NewBytesResponder(200, nil))
+func Test_BlockHeight_Error_StatusCode(t *testing.T) {
+	s := newFaultSochain(t)
+	_, err := s.BlockHeight(context.Background(), "btc", 200000)
+	assert.Equal(t, 500, StatusCode(err))
+}
 
-	s := NewSochain()
-	_, err := s.NetworkInfo(gotNetwork)
+func Test_BlockHeight_Error_Unmarshal(t *testing.T) {
+	s := newFaultSochain(t)
+	_, err := s.BlockHeight(context.Background(), "btc", 200000)
 	assert.NotNil(t, err)
 }
 
-func Test_BlockHeight_Success(t *testing.T) {
-	httpmock.Activate()
-	defer httpmock.DeactivateAndReset()
-
-	want := Block{
-		Status: "test",
-		Data: BlockData{
-			Network:   "btc",
-			Blockhash: "somehash",
-			BlockNo:   200000,
-		},
-	}
+func Test_BlockHash_Success(t *testing.T) {
+	s := newReplaySochain(t)
+	got, err := s.BlockHash(context.Background(), "btc", testBlockHash)
+	assert.Nil(t, err)
 
-	gotNetwork := "btc"
-	gotHeight := 200000
-	httpmock.RegisterResponder("GET", "https://sochain.com/api/v2/get_block/"+gotNetwork+"/"+strconv.Itoa(gotHeight),
-		httpmock.NewJsonResponderOrPanic(200, want))
+	assert.Equal(t, 100000, got.Data.BlockNo)
+	assert.Equal(t, testBlockHash, got.Data.Blockhash)
+	assert.Equal(t, 1293623863, got.Data.Time)
+}
 
-	s := NewSochain()
-	got, err := s.BlockHeight(gotNetwork, gotHeight)
-	assert.Nil(t, err)
+func Test_BlockHash_Error_StatusCode(t *testing.T) {
+	s := newFaultSochain(t)
+	_, err := s.BlockHash(context.Background(), "btc", testBlockHash)
+	assert.Equal(t, 500, StatusCode(err))
+}
 
-	assert.True(t, reflect.DeepEqual(*got, want))
+func Test_BlockHash_Error_Unmarshal(t *testing.T) {
+	s := newFaultSochain(t)
+	_, err := s.BlockHash(context.Background(), "btc", testBlockHash)
+	assert.NotNil(t, err)
 }
 
-func Test_BlockHeight_Error_StatusCode(t *testing.T) {
-	httpmock.Activate()
-	defer httpmock.DeactivateAndReset()
+func Test_BlockHash_Error_NotFound(t *testing.T) {
+	s := newReplaySochain(t)
+	_, err := s.BlockHash(context.Background(), "btc", testMissingBlockHash)
 
-	gotNetwork := "btc"
-	gotHeight := 200000
-	httpmock.RegisterResponder("GET", "https://sochain.com/api/v2/get_block/"+gotNetwork+"/"+strconv.Itoa(gotHeight),
-		httpmock.NewStringResponder(500, ""))
+	var cErr *ClientError
+	assert.True(t, errors.As(err, &cErr))
+	assert.Equal(t, 404, cErr.Code())
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
 
-	s := NewSochain()
-	_, err := s.BlockHeight(gotNetwork, gotHeight)
-	assert.NotNil(t, err)
+func Test_Transaction_Success(t *testing.T) {
+	s := newReplaySochain(t)
+	got, err := s.Transaction(context.Background(), "btc", testTxHash)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "BTC", got.Data.Network)
+	assert.Equal(t, testTxHash, got.Data.Txid)
+	assert.Equal(t, 0, got.Data.BlockNo)
+	assert.Equal(t, Amount(5000000000), got.Data.SentValue)
+	if assert.Len(t, got.Data.Outputs, 1) {
+		assert.Equal(t, testAddress, got.Data.Outputs[0].Address)
+	}
+	assert.Nil(t, VerifyTx(testTxHash, got.Data))
 }
 
-func Test_BlockHeight_Error_Unmarshal(t *testing.T) {
-	httpmock.Activate()
-	defer httpmock.DeactivateAndReset()
+func Test_Transaction_Error_NotFound(t *testing.T) {
+	s := newReplaySochain(t)
+	_, err := s.Transaction(context.Background(), "btc", "0000000000000000000000000000000000000000000000000000000000000001")
 
-	gotNetwork := "btc"
-	gotHeight := 200000
-	httpmock.RegisterResponder("GET", "https://sochain.com/api/v2/get_block/"+gotNetwork+"/"+strconv.Itoa(gotHeight),
-		httpmock.NewBytesResponder(200, nil))
+	var cErr *ClientError
+	assert.True(t, errors.As(err, &cErr))
+	assert.Equal(t, 404, cErr.Code())
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
 
-	s := NewSochain()
-	_, err := s.BlockHeight(gotNetwork, gotHeight)
+func Test_Transaction_Error_StatusCode(t *testing.T) {
+	s := newFaultSochain(t)
+	_, err := s.Transaction(context.Background(), "btc", testTxHash)
+	assert.Equal(t, 500, StatusCode(err))
+}
+
+func Test_Transaction_Error_Unmarshal(t *testing.T) {
+	s := newFaultSochain(t)
+	_, err := s.Transaction(context.Background(), "btc", testTxHash)
 	assert.NotNil(t, err)
 }
 
-func Test_BlockHash_Success(t *testing.T) {
-	httpmock.Activate()
-	defer httpmock.DeactivateAndReset()
-
-	want := Block{
-		Status: "test",
-		Data: BlockData{
-			Network:   "btc",
-			Blockhash: "somehash",
-			BlockNo:   200000,
-		},
-	}
+// newBlockingServer never answers, its handlers return once the client gave up on the request
+func newBlockingServer(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	t.Cleanup(srv.Close)
 
-	gotNetwork := "btc"
-	gotBlockhash := "200000"
-	httpmock.RegisterResponder("GET", "https://sochain.com/api/v2/get_block/"+gotNetwork+"/"+gotBlockhash,
-		httpmock.NewJsonResponderOrPanic(200, want))
+	return srv
+}
 
-	s := NewSochain()
-	got, err := s.BlockHash(gotNetwork, gotBlockhash)
-	assert.Nil(t, err)
+func Test_Transaction_Error_ContextCanceled(t *testing.T) {
+	srv := newBlockingServer(t)
 
-	assert.True(t, reflect.DeepEqual(*got, want))
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	s := NewSochain(WithBaseURL(srv.URL))
+	_, err := s.Transaction(ctx, "btc", "00000000000000000008fa3759141044ae3db1e6ec222e114651354f58d5cc42")
+	assert.True(t, errors.Is(err, context.Canceled))
 }
 
-func Test_BlockHash_Error_StatusCode(t *testing.T) {
-	httpmock.Activate()
-	defer httpmock.DeactivateAndReset()
+func Test_BlockHeight_Error_ContextDeadline(t *testing.T) {
+	srv := newBlockingServer(t)
 
-	gotNetwork := "btc"
-	gotBlockhash := "200000"
-	httpmock.RegisterResponder("GET", "https://sochain.com/api/v2/get_block/"+gotNetwork+"/"+gotBlockhash,
-		httpmock.NewStringResponder(500, ""))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
 
-	s := NewSochain()
-	_, err := s.BlockHash(gotNetwork, gotBlockhash)
-	assert.NotNil(t, err)
+	s := NewSochain(WithBaseURL(srv.URL))
+	_, err := s.BlockHeight(ctx, "btc", 200000)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
 }
 
-func Test_BlockHash_Error_Unmarshal(t *testing.T) {
-	httpmock.Activate()
-	defer httpmock.DeactivateAndReset()
+func Test_AddressBalance_Success(t *testing.T) {
+	s := newReplaySochain(t)
+	got, err := s.AddressBalance(context.Background(), "btc", testAddress)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "BTC", got.Data.Network)
+	assert.Equal(t, testAddress, got.Data.Address)
+	// donations to the address never spent keep its balance from dropping below the genesis reward
+	assert.GreaterOrEqual(t, got.Data.ConfirmedBalance.Satoshis(), int64(5000000000))
+}
 
-	gotNetwork := "btc"
-	gotBlockhash := "200000"
-	httpmock.RegisterResponder("GET", "https://sochain.com/api/v2/get_block/"+gotNetwork+"/"+gotBlockhash,
-		httpmock.NewBytesResponder(200, nil))
+func Test_AddressBalance_Error_StatusCode(t *testing.T) {
+	s := newFaultSochain(t)
+	_, err := s.AddressBalance(context.Background(), "btc", testAddress)
 
-	s := NewSochain()
-	_, err := s.BlockHash(gotNetwork, gotBlockhash)
-	assert.NotNil(t, err)
+	var cErr *ClientError
+	assert.True(t, errors.As(err, &cErr))
+	assert.Equal(t, 404, cErr.Code())
 }
 
-func Test_Transaction_Success(t *testing.T) {
-	httpmock.Activate()
-	defer httpmock.DeactivateAndReset()
-
-	want := Transaction{
-		Status: "test",
-		Code:   1,
-		Data: TransactionData{
-			Network: "btc",
-			Txid:    "txid",
-			Inputs: Inputs{
-				{
-					InputNo: 1,
-					Address: "0x0000",
-				},
-			},
-			Outputs: Outputs{
-				{
-					OutputNo: 1,
-					Address:  "0x000",
-				},
-			},
-		},
+func Test_ReceivedTransactions_Success(t *testing.T) {
+	s := newReplaySochain(t)
+	got, err := s.ReceivedTransactions(context.Background(), "btc", testAddress, "")
+	assert.Nil(t, err)
+
+	assert.Equal(t, testAddress, got.Data.Address)
+	if assert.Len(t, got.Data.Txs, 2) {
+		assert.Equal(t, testDonationTxHash, got.Data.Txs[0].Txid)
+		assert.Equal(t, Amount(5000), got.Data.Txs[0].Value)
 	}
 
-	gotNetwork := "btc"
-	gotTxHash := "200000"
-	httpmock.RegisterResponder("GET", "https://sochain.com/api/v2/tx/"+gotNetwork+"/"+gotTxHash,
-		httpmock.NewJsonResponderOrPanic(200, want))
+	got, err = s.ReceivedTransactions(context.Background(), "btc", testAddress, testDonationTxHash)
+	assert.Nil(t, err)
+	if assert.Len(t, got.Data.Txs, 1) {
+		assert.Equal(t, testTxHash, got.Data.Txs[0].Txid)
+	}
+}
 
-	s := NewSochain()
-	got, err := s.Transaction(gotNetwork, gotTxHash)
+func Test_SpentTransactions_Success(t *testing.T) {
+	s := newReplaySochain(t)
+	got, err := s.SpentTransactions(context.Background(), "btc", testAddress, "")
 	assert.Nil(t, err)
 
-	assert.True(t, reflect.DeepEqual(*got, want))
+	assert.Equal(t, testAddress, got.Data.Address)
+	assert.Empty(t, got.Data.Txs)
 }
 
-func Test_Transaction_Error_StatusCode(t *testing.T) {
-	httpmock.Activate()
-	defer httpmock.DeactivateAndReset()
+func Test_UnspentOutputs_Success(t *testing.T) {
+	s := newReplaySochain(t)
+	got, err := s.UnspentOutputs(context.Background(), "btc", testAddress, "")
+	assert.Nil(t, err)
 
-	gotNetwork := "btc"
-	gotTxHash := "200000"
-	httpmock.RegisterResponder("GET", "https://sochain.com/api/v2/tx/"+gotNetwork+"/"+gotTxHash,
-		httpmock.NewStringResponder(500, ""))
+	assert.Equal(t, testAddress, got.Data.Address)
+	assert.Len(t, got.Data.Txs, 2)
 
-	s := NewSochain()
-	_, err := s.Transaction(gotNetwork, gotTxHash)
+	got, err = s.UnspentOutputs(context.Background(), "btc", testAddress, testDonationTxHash)
+	assert.Nil(t, err)
+	if assert.Len(t, got.Data.Txs, 1) {
+		assert.Equal(t, testTxHash, got.Data.Txs[0].Txid)
+		assert.Equal(t, "4104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac", got.Data.Txs[0].ScriptHex)
+	}
+}
+
+func Test_UnspentOutputs_Error_Unmarshal(t *testing.T) {
+	s := newFaultSochain(t)
+	_, err := s.UnspentOutputs(context.Background(), "btc", testAddress, "")
 	assert.NotNil(t, err)
 }
 
-func Test_Transaction_Error_Unmarshal(t *testing.T) {
-	httpmock.Activate()
-	defer httpmock.DeactivateAndReset()
+func Test_Transaction_Success_Testnet(t *testing.T) {
+	s := newReplaySochain(t)
+	got, err := s.Transaction(context.Background(), "BtcTest", testTxHash)
+	assert.Nil(t, err)
 
-	gotNetwork := "btc"
-	gotTxHash := "200000"
-	httpmock.RegisterResponder("GET", "https://sochain.com/api/v2/tx/"+gotNetwork+"/"+gotTxHash,
-		httpmock.NewBytesResponder(200, nil))
+	assert.Equal(t, "BTCTEST", got.Data.Network)
+	assert.Equal(t, testTxHash, got.Data.Txid)
+}
 
-	s := NewSochain()
-	_, err := s.Transaction(gotNetwork, gotTxHash)
-	assert.NotNil(t, err)
+func Test_Client_Error_Validation(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer srv.Close()
+
+	s := NewSochain(WithBaseURL(srv.URL))
+	registry := network.DefaultRegistry()
+	registry.Disable("doge")
+	disabled := NewSochain(WithBaseURL(srv.URL), WithNetworks(registry))
+
+	errs := []error{}
+	_, err := s.NetworkInfo(context.Background(), "eth")
+	errs = append(errs, err)
+	_, err = disabled.BlockHeight(context.Background(), "doge", 1)
+	errs = append(errs, err)
+	_, err = s.BlockHash(context.Background(), "btc", "200000")
+	errs = append(errs, err)
+	_, err = s.Transaction(context.Background(), "ltc", "200000")
+	errs = append(errs, err)
+	_, err = s.AddressBalance(context.Background(), "ltc", "1BoatSLRHtKNngkdXEeobR76b53LETtpyT")
+	errs = append(errs, err)
+
+	for _, err := range errs {
+		var cErr *ClientError
+		assert.True(t, errors.As(err, &cErr))
+		assert.Equal(t, 400, cErr.Code())
+	}
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
 }
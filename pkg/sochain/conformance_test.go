@@ -0,0 +1,28 @@
+package sochain_test
+
+import (
+	"net/http"
+	"sochain-client/pkg/sochain"
+	"sochain-client/pkg/sochain/sochaintest"
+	"testing"
+)
+
+func Test_Conformance(t *testing.T) {
+	srv := sochaintest.NewServer(t, map[string]sochaintest.Fixture{
+		"GET /get_info/BTC":                                                                  {File: "testdata/get_info_btc.json"},
+		"GET /get_block/BTC/100000":                                                          {File: "testdata/get_block_btc_100000.json"},
+		"GET /get_block/BTC/" + sochaintest.BlockHash:                                        {File: "testdata/get_block_btc_100000.json"},
+		"GET /tx/BTC/" + sochaintest.TxHash:                                                  {File: "testdata/tx_btc_genesis.json"},
+		"GET /tx/BTC/" + sochaintest.MissingTxHash:                                           {Status: http.StatusNotFound, File: "testdata/tx_btc_missing.json"},
+		"GET /get_address_balance/BTC/" + sochaintest.Address:                                {File: "testdata/get_address_balance_btc.json"},
+		"GET /get_tx_received/BTC/" + sochaintest.Address:                                    {File: "testdata/get_tx_received_btc.json"},
+		"GET /get_tx_received/BTC/" + sochaintest.Address + "/" + sochaintest.DonationTxHash: {File: "testdata/get_tx_received_btc_after.json"},
+		"GET /get_tx_spent/BTC/" + sochaintest.Address:                                       {File: "testdata/get_tx_spent_btc.json"},
+		"GET /get_tx_unspent/BTC/" + sochaintest.Address:                                     {File: "testdata/get_tx_unspent_btc.json"},
+		"GET /get_tx_unspent/BTC/" + sochaintest.Address + "/" + sochaintest.DonationTxHash:  {File: "testdata/get_tx_unspent_btc_after.json"},
+		"POST /send_tx/BTC":                                                                  {File: "testdata/send_tx_btc.json"},
+		"POST /send_tx/BTCTEST":                                                              {Status: http.StatusBadRequest, File: "testdata/send_tx_btctest.json"},
+	})
+
+	sochaintest.Run(t, sochain.NewSochain(sochain.WithBaseURL(srv.URL), sochain.WithHTTPClient(srv.Client())))
+}
@@ -0,0 +1,101 @@
+package sochain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Decimal is an exact decimal number of any precision, e.g. a price. It's encoded to JSON as a string keeping the
+// decimals it was parsed with, and decoded from strings & numbers. The zero value is 0
+type Decimal struct {
+	// rat is never modified once set, copies of a Decimal share it
+	rat      *big.Rat
+	decimals int
+}
+
+// ParseDecimal parses a decimal number, e.g. "29174.12" or "-0.5". Exponents are rejected
+func ParseDecimal(s string) (Decimal, error) {
+	_, frac, ok := splitDecimal(s)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal '%s'", s)
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal '%s'", s)
+	}
+
+	return Decimal{rat: r, decimals: len(frac)}, nil
+}
+
+// Rat returns d as a big.Rat owned by the caller
+func (d Decimal) Rat() *big.Rat {
+	if d.rat == nil {
+		return new(big.Rat)
+	}
+
+	return new(big.Rat).Set(d.rat)
+}
+
+// String formats d with the decimals it was parsed with
+func (d Decimal) String() string {
+	return d.Rat().FloatString(d.decimals)
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts decimals as string or number, null & empty strings leave d unchanged
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	s := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		if s == "" {
+			return nil
+		}
+	}
+
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+
+	*d = v
+	return nil
+}
+
+// splitDecimal splits s of the form [-]digits[.digits] into its whole & fractional digits, ok is false for any other
+// form. Checking the form first keeps big.Rat from parsing fractions & exponents, e.g. "1e999999999" allocating
+// a billion digits
+func splitDecimal(s string) (whole, frac string, ok bool) {
+	whole, frac, hasFrac := strings.Cut(strings.TrimPrefix(s, "-"), ".")
+	if !digits(whole) || (hasFrac && !digits(frac)) {
+		return "", "", false
+	}
+
+	return whole, frac, true
+}
+
+// digits reports whether s is a non-empty string of ASCII digits
+func digits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
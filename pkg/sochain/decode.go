@@ -0,0 +1,108 @@
+package sochain
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sochain-client/pkg/rawtx"
+	"strings"
+)
+
+// DecodedTxResponse is a transaction decoded from its tx_hex, scripts & witness items are hex encoded
+type DecodedTxResponse struct {
+	Txid     string                  `json:"txid"`
+	Wtxid    string                  `json:"wtxid"`
+	Version  int32                   `json:"version"`
+	Segwit   bool                    `json:"segwit"`
+	Inputs   []DecodedInputResponse  `json:"inputs"`
+	Outputs  []DecodedOutputResponse `json:"outputs"`
+	Locktime uint32                  `json:"locktime"`
+}
+
+type DecodedInputResponse struct {
+	PrevTxid  string   `json:"prev_txid"`
+	PrevIndex uint32   `json:"prev_index"`
+	ScriptSig string   `json:"script_sig"`
+	Sequence  uint32   `json:"sequence"`
+	Witness   []string `json:"witness,omitempty"`
+}
+
+type DecodedOutputResponse struct {
+	Value        Amount `json:"value"`
+	ScriptPubKey string `json:"script_pubkey"`
+}
+
+// Decode decodes the tx_hex of t
+func (t TransactionData) Decode() (*rawtx.Tx, error) {
+	if t.TxHex == "" {
+		return nil, fmt.Errorf("tx_hex of transaction '%s' missing", t.Txid)
+	}
+
+	return rawtx.DecodeString(t.TxHex)
+}
+
+// DecodedResponse decodes the tx_hex of t
+func (t Transaction) DecodedResponse() (*DecodedTxResponse, error) {
+	tx, err := t.Data.Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &DecodedTxResponse{
+		Txid:     tx.Txid(),
+		Wtxid:    tx.Wtxid(),
+		Version:  tx.Version,
+		Segwit:   tx.Segwit,
+		Inputs:   make([]DecodedInputResponse, len(tx.Inputs)),
+		Outputs:  make([]DecodedOutputResponse, len(tx.Outputs)),
+		Locktime: tx.Locktime,
+	}
+	for i, in := range tx.Inputs {
+		r.Inputs[i] = DecodedInputResponse{
+			PrevTxid:  in.PrevTxid,
+			PrevIndex: in.PrevIndex,
+			ScriptSig: hex.EncodeToString(in.ScriptSig),
+			Sequence:  in.Sequence,
+		}
+		for _, item := range in.Witness {
+			r.Inputs[i].Witness = append(r.Inputs[i].Witness, hex.EncodeToString(item))
+		}
+	}
+	for i, out := range tx.Outputs {
+		r.Outputs[i] = DecodedOutputResponse{
+			Value:        Amount(out.Value),
+			ScriptPubKey: hex.EncodeToString(out.ScriptPubKey),
+		}
+	}
+
+	return r, nil
+}
+
+// VerifyTx checks that d is the transaction txHash & its tx_hex hashes to its txid. Mismatches are returned as *ClientError
+// matching ErrTxMismatch
+func VerifyTx(txHash string, d TransactionData) error {
+	if !strings.EqualFold(d.Txid, txHash) {
+		return newMismatchErr(ErrTxMismatch, "txhash", fmt.Errorf("requested tx '%s', got tx '%s'", txHash, d.Txid))
+	}
+
+	tx, err := d.Decode()
+	if err != nil {
+		return newMismatchErr(ErrTxMismatch, "txhash", fmt.Errorf("unable to decode tx '%s': %w", d.Txid, err))
+	}
+	if txid := tx.Txid(); !strings.EqualFold(txid, d.Txid) {
+		return newMismatchErr(ErrTxMismatch, "txhash", fmt.Errorf("tx_hex of tx '%s' hashes to '%s'", d.Txid, txid))
+	}
+
+	return nil
+}
+
+// newMismatchErr rejects inconsistent upstream data, kind is one of the sentinel errors
+func newMismatchErr(kind error, param string, e error) *ClientError {
+	return &ClientError{
+		err:          e,
+		statuscode:   http.StatusBadGateway,
+		kind:         kind,
+		UpstreamCode: http.StatusOK,
+		Param:        param,
+	}
+}
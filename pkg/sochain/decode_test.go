@@ -0,0 +1,116 @@
+package sochain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const genesisTxid = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+func genesisTx(t *testing.T) string {
+	b, err := ioutil.ReadFile("testdata/tx_btc_genesis.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func Test_VerifyTx(t *testing.T) {
+	var tx Transaction
+	assert.Nil(t, json.Unmarshal([]byte(genesisTx(t)), &tx))
+
+	tampered := tx.Data
+	tampered.TxHex = strings.Replace(tampered.TxHex, "00f2052a01", "00f2052a02", 1)
+
+	missing := tx.Data
+	missing.TxHex = ""
+
+	tests := []struct {
+		title   string
+		txHash  string
+		got     TransactionData
+		wantErr bool
+	}{
+		{title: "match", txHash: genesisTxid, got: tx.Data},
+		{title: "match upper case", txHash: strings.ToUpper(genesisTxid), got: tx.Data},
+		{title: "other tx", txHash: strings.Repeat("0", 64), got: tx.Data, wantErr: true},
+		{title: "tampered tx_hex", txHash: genesisTxid, got: tampered, wantErr: true},
+		{title: "missing tx_hex", txHash: genesisTxid, got: missing, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			err := VerifyTx(tt.txHash, tt.got)
+			if !tt.wantErr {
+				assert.Nil(t, err)
+				return
+			}
+
+			var cErr *ClientError
+			assert.True(t, errors.As(err, &cErr))
+			assert.True(t, errors.Is(err, ErrTxMismatch))
+			assert.Equal(t, http.StatusBadGateway, cErr.Code())
+		})
+	}
+}
+
+func Test_Transaction_Verify(t *testing.T) {
+	body := genesisTx(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tx/BTC/" + genesisTxid:
+			w.Write([]byte(body))
+		case "/tx/LTC/" + genesisTxid:
+			w.Write([]byte(strings.Replace(body, "00f2052a01", "00f2052a02", 1)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	s := NewSochain(WithBaseURL(srv.URL), WithTxVerification(true))
+
+	_, err := s.Transaction(context.Background(), "btc", genesisTxid)
+	assert.Nil(t, err)
+
+	_, err = s.Transaction(context.Background(), "ltc", genesisTxid)
+	assert.True(t, errors.Is(err, ErrTxMismatch))
+
+	_, err = NewSochain(WithBaseURL(srv.URL)).Transaction(context.Background(), "ltc", genesisTxid)
+	assert.Nil(t, err)
+}
+
+func Test_Transaction_DecodedResponse(t *testing.T) {
+	var tx Transaction
+	assert.Nil(t, json.Unmarshal([]byte(genesisTx(t)), &tx))
+
+	got, err := tx.DecodedResponse()
+	assert.Nil(t, err)
+
+	assert.Equal(t, genesisTxid, got.Txid)
+	assert.Equal(t, genesisTxid, got.Wtxid)
+	assert.Equal(t, int32(1), got.Version)
+	assert.False(t, got.Segwit)
+	if assert.Len(t, got.Inputs, 1) {
+		assert.Equal(t, strings.Repeat("0", 64), got.Inputs[0].PrevTxid)
+		assert.Equal(t, uint32(0xffffffff), got.Inputs[0].PrevIndex)
+		assert.Equal(t, tx.Data.Inputs[0].ScriptHex, got.Inputs[0].ScriptSig)
+		assert.Nil(t, got.Inputs[0].Witness)
+	}
+	if assert.Len(t, got.Outputs, 1) {
+		assert.Equal(t, Amount(5000000000), got.Outputs[0].Value)
+		assert.Equal(t, tx.Data.Outputs[0].ScriptHex, got.Outputs[0].ScriptPubKey)
+	}
+
+	tx.Data.TxHex = ""
+	_, err = tx.DecodedResponse()
+	assert.NotNil(t, err)
+}
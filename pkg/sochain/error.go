@@ -1,21 +1,199 @@
 package sochain
 
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Sentinel errors classifying a *ClientError, match them by errors.Is
+var (
+	ErrNotFound       = errors.New("not found")
+	ErrRateLimited    = errors.New("rate limited")
+	ErrInvalidNetwork = errors.New("invalid network")
+	ErrInvalidHash    = errors.New("invalid hash")
+	ErrInvalidAddress = errors.New("invalid address")
+	// the upstream doesn't serve the requested resource at all, e.g. address lookups of a node without address index
+	ErrUnsupported = errors.New("unsupported")
+	// the upstream returned a transaction whose tx_hex doesn't hash to its txid
+	ErrTxMismatch = errors.New("transaction mismatch")
+	// the upstream returned a block whose transactions don't hash to its merkle root
+	ErrMerkleMismatch = errors.New("merkle root mismatch")
+	// providers asked for the same resource disagree or fewer than two of them answered
+	ErrNoConsensus = errors.New("no consensus")
+)
+
+// ClientError is returned if a request is rejected by sochain or fails validation before being sent
 type ClientError struct {
 	err        error
 	statuscode int
+	kind       error
+
+	// Code of the sochain "fail" response, the statuscode if the response carries none
+	UpstreamCode int
+	// Reason given by sochain or the validation
+	Message string
+	// Request parameter the error refers to, e.g. 'network' or 'txid'
+	Param string
 }
 
-func (c ClientError) Error() string {
-	return c.err.Error()
+func (c *ClientError) Error() string {
+	if c.Message == "" {
+		return c.err.Error()
+	}
+	if c.Param == "" {
+		return fmt.Sprintf("%s: %s", c.err.Error(), c.Message)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", c.err.Error(), c.Param, c.Message)
 }
 
-func (c ClientError) Code() int {
+func (c *ClientError) Code() int {
 	return c.statuscode
 }
 
+func (c *ClientError) Unwrap() error {
+	return c.err
+}
+
+// Is matches the sentinel error classifying c
+func (c *ClientError) Is(target error) bool {
+	return c.kind != nil && target == c.kind
+}
+
 func NewClientErr(e error, statuscode int) *ClientError {
+	return &ClientError{
+		err:          e,
+		statuscode:   statuscode,
+		kind:         kindOfStatus(statuscode),
+		UpstreamCode: statuscode,
+	}
+}
+
+// NewValidationErr rejects param before any request is sent, kind is one of the sentinel errors
+func NewValidationErr(kind error, param string, e error) *ClientError {
 	return &ClientError{
 		err:        e,
-		statuscode: statuscode,
+		statuscode: http.StatusBadRequest,
+		kind:       kind,
+		Param:      param,
+	}
+}
+
+// Non-standard statuscode (nginx) of calls & requests whose caller went away before a response was written
+const StatusClientClosedRequest = 499
+
+// StatusCode classifies the outcome of a Connector call by the HTTP statuscode it maps to, e.g. to label metrics & spans
+func StatusCode(err error) int {
+	if err == nil {
+		return http.StatusOK
 	}
+
+	var cErr *ClientError
+	var bErr *BroadcastError
+	switch {
+	case errors.As(err, &cErr):
+		return cErr.Code()
+	case errors.Is(err, ErrNoConsensus):
+		return http.StatusBadGateway
+	case errors.As(err, &bErr):
+		return http.StatusBadRequest
+	case errors.Is(err, context.Canceled):
+		return StatusClientClosedRequest
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+
+	return http.StatusInternalServerError
+}
+
+// failBody is the sochain "fail" response, e.g. {"status":"fail","data":{"txid":"Transaction not found."}}
+type failBody struct {
+	Status  string          `json:"status"`
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// reason returns the offending parameter & the message of the fail response. The data field is either an object of
+// parameter messages or a plain message, the message field is the fallback
+func (f failBody) reason() (string, string) {
+	var fields map[string]string
+	if err := json.Unmarshal(f.Data, &fields); err == nil && len(fields) > 0 {
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		msgs := make([]string, len(keys))
+		for i, k := range keys {
+			msgs[i] = fields[k]
+		}
+		return keys[0], strings.Join(msgs, "; ")
+	}
+
+	var msg string
+	if err := json.Unmarshal(f.Data, &msg); err == nil && msg != "" {
+		return "", msg
+	}
+
+	return "", f.Message
+}
+
+// newFailErr decodes a sochain "fail" response body into a *ClientError. Bodies which aren't JSON keep the bare error
+func newFailErr(e error, statuscode int, body []byte) *ClientError {
+	cErr := NewClientErr(e, statuscode)
+
+	var fail failBody
+	if err := json.Unmarshal(body, &fail); err != nil {
+		return cErr
+	}
+
+	if fail.Code != 0 {
+		cErr.UpstreamCode = fail.Code
+	}
+	cErr.Param, cErr.Message = fail.reason()
+	if cErr.kind == nil {
+		cErr.kind = kindOfReason(cErr.Param, cErr.Message)
+	}
+	// sochain reports some missing resources as failed 200 responses
+	if cErr.kind == ErrNotFound {
+		cErr.statuscode = http.StatusNotFound
+	}
+
+	return cErr
+}
+
+func kindOfStatus(statuscode int) error {
+	switch statuscode {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusNotImplemented:
+		return ErrUnsupported
+	}
+
+	return nil
+}
+
+func kindOfReason(param, message string) error {
+	m := strings.ToLower(message)
+	switch {
+	case strings.Contains(m, "not found"):
+		return ErrNotFound
+	case param == "network":
+		return ErrInvalidNetwork
+	case param == "txid" || param == "tx_hash" || param == "blockhash" || param == "block_hash" || param == "hash" || strings.Contains(m, "hash"):
+		return ErrInvalidHash
+	case param == "address":
+		return ErrInvalidAddress
+	}
+
+	return nil
 }
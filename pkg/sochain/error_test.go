@@ -0,0 +1,201 @@
+package sochain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testTxHash = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+func Test_NewFailErr(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuscode   int
+		body         string
+		wantCode     int
+		wantUpstream int
+		wantParam    string
+		wantMessage  string
+		wantKind     error
+	}{
+		{
+			name:         "data object",
+			statuscode:   404,
+			body:         `{"status":"fail","data":{"txid":"Transaction not found."}}`,
+			wantCode:     404,
+			wantUpstream: 404,
+			wantParam:    "txid",
+			wantMessage:  "Transaction not found.",
+			wantKind:     ErrNotFound,
+		},
+		{
+			name:         "upstream code",
+			statuscode:   400,
+			body:         `{"status":"fail","code":422,"data":{"network":"Network is required (DOGE, DOGETEST, ...)"}}`,
+			wantCode:     400,
+			wantUpstream: 422,
+			wantParam:    "network",
+			wantMessage:  "Network is required (DOGE, DOGETEST, ...)",
+			wantKind:     ErrInvalidNetwork,
+		},
+		{
+			name:         "invalid hash",
+			statuscode:   400,
+			body:         `{"status":"fail","data":{"block_hash":"Valid block hash is required."}}`,
+			wantCode:     400,
+			wantUpstream: 400,
+			wantParam:    "block_hash",
+			wantMessage:  "Valid block hash is required.",
+			wantKind:     ErrInvalidHash,
+		},
+		{
+			name:         "message",
+			statuscode:   429,
+			body:         `{"status":"fail","message":"Too many requests."}`,
+			wantCode:     429,
+			wantUpstream: 429,
+			wantMessage:  "Too many requests.",
+			wantKind:     ErrRateLimited,
+		},
+		{
+			name:         "not found on 200",
+			statuscode:   400,
+			body:         `{"status":"fail","data":{"address":"Address not found."}}`,
+			wantCode:     404,
+			wantUpstream: 400,
+			wantParam:    "address",
+			wantMessage:  "Address not found.",
+			wantKind:     ErrNotFound,
+		},
+		{
+			name:         "no json",
+			statuscode:   502,
+			body:         `<html>bad gateway</html>`,
+			wantCode:     502,
+			wantUpstream: 502,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cErr := newFailErr(errors.New("some"), tt.statuscode, []byte(tt.body))
+
+			assert.Equal(t, tt.wantCode, cErr.Code())
+			assert.Equal(t, tt.wantUpstream, cErr.UpstreamCode)
+			assert.Equal(t, tt.wantParam, cErr.Param)
+			assert.Equal(t, tt.wantMessage, cErr.Message)
+			if tt.wantKind != nil {
+				assert.ErrorIs(t, cErr, tt.wantKind)
+			}
+			for _, kind := range []error{ErrNotFound, ErrRateLimited, ErrInvalidNetwork, ErrInvalidHash, ErrInvalidAddress} {
+				if kind != tt.wantKind {
+					assert.False(t, errors.Is(cErr, kind), kind.Error())
+				}
+			}
+		})
+	}
+}
+
+// newStaticServer responds to path with status & body, to any other path with 404
+func newStaticServer(t *testing.T, path string, status int, body string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func Test_ClientError_Error(t *testing.T) {
+	cErr := newFailErr(errors.New("sochain response statuscode 404"), 404, []byte(`{"status":"fail","data":{"txid":"Transaction not found."}}`))
+	assert.Equal(t, "sochain response statuscode 404: txid: Transaction not found.", cErr.Error())
+
+	cErr = NewClientErr(errors.New("some"), 400)
+	assert.Equal(t, "some", cErr.Error())
+}
+
+func Test_Transaction_Error_Fail(t *testing.T) {
+	srv := newStaticServer(t, "/tx/BTC/"+testTxHash, http.StatusNotFound, `{"status":"fail","data":{"txid":"Transaction not found."}}`)
+
+	s := NewSochain(WithBaseURL(srv.URL))
+	_, err := s.Transaction(context.Background(), "btc", testTxHash)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	var cErr *ClientError
+	assert.True(t, errors.As(err, &cErr))
+	assert.Equal(t, "txid", cErr.Param)
+	assert.Equal(t, "Transaction not found.", cErr.Message)
+}
+
+func Test_Transaction_Error_FailStatusOK(t *testing.T) {
+	srv := newStaticServer(t, "/tx/BTC/"+testTxHash, http.StatusOK, `{"status":"fail","data":{"txid":"Transaction not found."}}`)
+
+	s := NewSochain(WithBaseURL(srv.URL))
+	_, err := s.Transaction(context.Background(), "btc", testTxHash)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	var cErr *ClientError
+	assert.True(t, errors.As(err, &cErr))
+	assert.Equal(t, http.StatusNotFound, cErr.Code())
+}
+
+func Test_ValidationErrors(t *testing.T) {
+	s := NewSochain()
+
+	_, err := s.NetworkInfo(context.Background(), "xyz")
+	assert.ErrorIs(t, err, ErrInvalidNetwork)
+
+	_, err = s.Transaction(context.Background(), "btc", "hash")
+	assert.ErrorIs(t, err, ErrInvalidHash)
+
+	_, err = s.BlockHash(context.Background(), "btc", "hash")
+	assert.ErrorIs(t, err, ErrInvalidHash)
+
+	_, err = s.AddressBalance(context.Background(), "btc", "address")
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+
+	var cErr *ClientError
+	assert.True(t, errors.As(err, &cErr))
+	assert.Equal(t, http.StatusBadRequest, cErr.Code())
+	assert.Equal(t, "address", cErr.Param)
+}
+
+func Test_RetryError_Is(t *testing.T) {
+	srv := newStaticServer(t, "/get_info/BTC", http.StatusTooManyRequests, `{"status":"fail","message":"Too many requests."}`)
+
+	s := NewSochain(WithBaseURL(srv.URL), WithRetryPolicy(testRetryPolicy()))
+	_, err := s.NetworkInfo(context.Background(), "btc")
+
+	var rErr *RetryError
+	assert.True(t, errors.As(err, &rErr))
+	assert.ErrorIs(t, err, ErrRateLimited)
+}
+
+func Test_StatusCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, http.StatusOK},
+		{NewClientErr(errors.New("some"), http.StatusTooManyRequests), http.StatusTooManyRequests},
+		{&RetryError{Attempts: 3, Err: NewClientErr(errors.New("some"), http.StatusBadGateway)}, http.StatusBadGateway},
+		{NewBroadcastErr("bad-txns-inputs-missingorspent"), http.StatusBadRequest},
+		{fmt.Errorf("get: %w", context.Canceled), 499},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout},
+		{errors.New("some"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, StatusCode(tt.err), fmt.Sprint(tt.err))
+	}
+}
@@ -0,0 +1,304 @@
+package sochain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// blocks of a walk which don't link to their neighbours, e.g. after a reorg mid-walk
+	ErrChainMismatch = errors.New("chain mismatch")
+	// walks exceeding WithMaxBlocks
+	ErrRangeTooLarge = errors.New("range too large")
+)
+
+// BlockRef refers to a block by height or, if Hash is set, by hash
+type BlockRef struct {
+	Height int
+	Hash   string
+}
+
+func HeightRef(height int) BlockRef {
+	return BlockRef{Height: height}
+}
+
+func HashRef(hash string) BlockRef {
+	return BlockRef{Hash: hash}
+}
+
+// ParseBlockRef parses a decimal height or a block hash. Hashes aren't validated against a network
+func ParseBlockRef(s string) (BlockRef, error) {
+	if s == "" {
+		return BlockRef{}, errors.New("empty block reference")
+	}
+
+	// hashes may consist of digits only
+	if height, err := strconv.Atoi(s); err == nil && len(s) != 64 {
+		if height < 0 {
+			return BlockRef{}, fmt.Errorf("negative height %d", height)
+		}
+		return HeightRef(height), nil
+	}
+
+	return HashRef(s), nil
+}
+
+func (r BlockRef) String() string {
+	if r.Hash != "" {
+		return r.Hash
+	}
+	return strconv.Itoa(r.Height)
+}
+
+// IteratorOption configures a BlockIterator created by NewBlockIterator
+type IteratorOption func(*BlockIterator)
+
+// WithPrefetch limits the number of blocks fetched ahead of the consumer, 8 by default
+func WithPrefetch(n int) IteratorOption {
+	return func(it *BlockIterator) {
+		if n > 0 {
+			it.prefetch = n
+		}
+	}
+}
+
+// WithMaxBlocks rejects walks of more than n blocks with a *ClientError of status 400 matching ErrRangeTooLarge,
+// unlimited by default
+func WithMaxBlocks(n int) IteratorOption {
+	return func(it *BlockIterator) {
+		it.maxBlocks = n
+	}
+}
+
+type blockResult struct {
+	block *Block
+	err   error
+}
+
+// BlockIterator walks the blocks between two refs of a network, both inclusive. It walks forward if from is below to
+// & backward otherwise. Blocks are fetched by height with bounded concurrency & checked to link to their neighbour by
+// PreviousBlockhash & NextBlockhash, blocks referred by hash have to be the ones on the walked chain.
+//
+//	it := sochain.NewBlockIterator(ctx, c, "btc", sochain.HeightRef(100), sochain.HashRef(hash))
+//	defer it.Close()
+//	for it.Next() {
+//		b := it.Block()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type BlockIterator struct {
+	client    Connector
+	networkID string
+	from, to  BlockRef
+	prefetch  int
+	maxBlocks int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	started bool
+	// heights of from & to, valid once started
+	fromHeight, toHeight int
+	results              chan chan blockResult
+	sem                  chan struct{}
+
+	block *Block
+	err   error
+}
+
+func NewBlockIterator(ctx context.Context, c Connector, networkID string, from, to BlockRef, opts ...IteratorOption) *BlockIterator {
+	ctx, cancel := context.WithCancel(ctx)
+	it := &BlockIterator{
+		client:    c,
+		networkID: networkID,
+		from:      from,
+		to:        to,
+		prefetch:  8,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+
+	for _, opt := range opts {
+		opt(it)
+	}
+
+	return it
+}
+
+// Next advances to the next block, false once the walk is done or failed
+func (it *BlockIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if !it.started {
+		it.started = true
+		if it.err = it.start(); it.err != nil {
+			it.cancel()
+			return false
+		}
+	}
+
+	next, ok := <-it.results
+	if !ok {
+		it.block = nil
+		it.cancel()
+		return false
+	}
+
+	var r blockResult
+	select {
+	case r = <-next:
+	case <-it.ctx.Done():
+		r.err = it.ctx.Err()
+	}
+	<-it.sem
+
+	if r.err == nil {
+		r.err = it.link(it.block, r.block)
+	}
+	if r.err != nil {
+		it.block, it.err = nil, r.err
+		it.cancel()
+		return false
+	}
+
+	it.block = r.block
+	return true
+}
+
+// Block returns the current block of the walk
+func (it *BlockIterator) Block() *Block {
+	return it.block
+}
+
+// Err returns the error which ended the walk, nil if it completed or was closed
+func (it *BlockIterator) Err() error {
+	return it.err
+}
+
+// Close stops fetching ahead, it has to be called if the walk isn't iterated to its end
+func (it *BlockIterator) Close() {
+	it.cancel()
+}
+
+// Len returns the number of blocks of the walk, valid once Next was called
+func (it *BlockIterator) Len() int {
+	if it.toHeight >= it.fromHeight {
+		return it.toHeight - it.fromHeight + 1
+	}
+	return it.fromHeight - it.toHeight + 1
+}
+
+// start resolves the heights of the walk & starts fetching ahead
+func (it *BlockIterator) start() error {
+	var err error
+	if it.fromHeight, err = it.resolve(it.from); err != nil {
+		return err
+	}
+	if it.toHeight, err = it.resolve(it.to); err != nil {
+		return err
+	}
+
+	if it.maxBlocks > 0 && it.Len() > it.maxBlocks {
+		return NewValidationErr(ErrRangeTooLarge, "to", fmt.Errorf("range of %d blocks from %s to %s exceeds maximum of %d", it.Len(), it.from, it.to, it.maxBlocks))
+	}
+
+	it.results = make(chan chan blockResult, it.prefetch)
+	it.sem = make(chan struct{}, it.prefetch)
+	go it.fetch()
+	return nil
+}
+
+func (it *BlockIterator) resolve(ref BlockRef) (int, error) {
+	if ref.Hash == "" {
+		return ref.Height, nil
+	}
+
+	b, err := it.client.BlockHash(it.ctx, it.networkID, ref.Hash)
+	if err != nil {
+		return 0, err
+	}
+	return b.Data.BlockNo, nil
+}
+
+// fetch starts the fetches of all heights in order, at most prefetch ahead of the consumer
+func (it *BlockIterator) fetch() {
+	defer close(it.results)
+
+	step := 1
+	if it.toHeight < it.fromHeight {
+		step = -1
+	}
+
+	for height := it.fromHeight; ; height += step {
+		select {
+		case it.sem <- struct{}{}:
+		case <-it.ctx.Done():
+			return
+		}
+
+		next := make(chan blockResult, 1)
+		go func(height int) {
+			b, err := it.client.BlockHeight(it.ctx, it.networkID, height)
+			next <- blockResult{block: b, err: err}
+		}(height)
+
+		// results has room for every permit of sem
+		it.results <- next
+		if height == it.toHeight {
+			return
+		}
+	}
+}
+
+// link checks that b follows prev in the direction of the walk, prev is nil for the first block
+func (it *BlockIterator) link(prev, b *Block) error {
+	height := it.fromHeight
+	forward := it.toHeight >= it.fromHeight
+	if prev != nil {
+		height = prev.Data.BlockNo + 1
+		if !forward {
+			height = prev.Data.BlockNo - 1
+		}
+	}
+
+	if b.Data.BlockNo != height {
+		return newChainErr(fmt.Errorf("expected block %d, got block %d '%s'", height, b.Data.BlockNo, b.Data.Blockhash))
+	}
+	if b.Data.BlockNo == it.fromHeight && !refers(it.from, b) {
+		return newChainErr(fmt.Errorf("block %d is '%s', not '%s'", b.Data.BlockNo, b.Data.Blockhash, it.from.Hash))
+	}
+	if b.Data.BlockNo == it.toHeight && !refers(it.to, b) {
+		return newChainErr(fmt.Errorf("block %d is '%s', not '%s'", b.Data.BlockNo, b.Data.Blockhash, it.to.Hash))
+	}
+	if prev == nil {
+		return nil
+	}
+
+	parent, child := prev, b
+	if !forward {
+		parent, child = b, prev
+	}
+	if !strings.EqualFold(child.Data.PreviousBlockhash, parent.Data.Blockhash) {
+		return newChainErr(fmt.Errorf("previous hash '%s' of block %d doesn't match block %d '%s'", child.Data.PreviousBlockhash, child.Data.BlockNo, parent.Data.BlockNo, parent.Data.Blockhash))
+	}
+	// the next hash is unknown for the tip at the time parent was fetched
+	if parent.Data.NextBlockhash != "" && !strings.EqualFold(parent.Data.NextBlockhash, child.Data.Blockhash) {
+		return newChainErr(fmt.Errorf("next hash '%s' of block %d doesn't match block %d '%s'", parent.Data.NextBlockhash, parent.Data.BlockNo, child.Data.BlockNo, child.Data.Blockhash))
+	}
+
+	return nil
+}
+
+// refers reports whether b is the block of ref, refs by height refer to any block of their height
+func refers(ref BlockRef, b *Block) bool {
+	return ref.Hash == "" || strings.EqualFold(ref.Hash, b.Data.Blockhash)
+}
+
+func newChainErr(e error) *ClientError {
+	return newMismatchErr(ErrChainMismatch, "blockhash", e)
+}
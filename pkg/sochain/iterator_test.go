@@ -0,0 +1,217 @@
+package sochain_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sochain-client/pkg/sochain"
+	mock_client "sochain-client/pkg/sochain/mock"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// chain returns linked blocks of heights 0 to n-1
+func chain(n int) []*sochain.Block {
+	blocks := make([]*sochain.Block, n)
+	for i := range blocks {
+		blocks[i] = &sochain.Block{Data: sochain.BlockData{BlockNo: i, Blockhash: fmt.Sprintf("%064x", i+1)}}
+		if i > 0 {
+			blocks[i].Data.PreviousBlockhash = blocks[i-1].Data.Blockhash
+			blocks[i-1].Data.NextBlockhash = blocks[i].Data.Blockhash
+		}
+	}
+	return blocks
+}
+
+// serve answers block lookups of m from blocks
+func serve(m *mock_client.MockConnector, blocks []*sochain.Block) {
+	m.EXPECT().BlockHeight(gomock.Any(), "btc", gomock.Any()).AnyTimes().DoAndReturn(func(_ context.Context, _ string, height int) (*sochain.Block, error) {
+		if height >= len(blocks) {
+			return nil, sochain.NewClientErr(errors.New("block not found"), http.StatusNotFound)
+		}
+		return blocks[height], nil
+	})
+	m.EXPECT().BlockHash(gomock.Any(), "btc", gomock.Any()).AnyTimes().DoAndReturn(func(_ context.Context, _ string, hash string) (*sochain.Block, error) {
+		for _, b := range blocks {
+			if b.Data.Blockhash == hash {
+				return b, nil
+			}
+		}
+		return nil, sochain.NewClientErr(errors.New("block not found"), http.StatusNotFound)
+	})
+}
+
+func walk(it *sochain.BlockIterator) ([]int, error) {
+	defer it.Close()
+
+	var heights []int
+	for it.Next() {
+		heights = append(heights, it.Block().Data.BlockNo)
+	}
+	return heights, it.Err()
+}
+
+func Test_BlockIterator(t *testing.T) {
+	blocks := chain(20)
+
+	tests := []struct {
+		title    string
+		from, to sochain.BlockRef
+		want     []int
+	}{
+		{title: "forward", from: sochain.HeightRef(3), to: sochain.HeightRef(7), want: []int{3, 4, 5, 6, 7}},
+		{title: "backward", from: sochain.HeightRef(7), to: sochain.HeightRef(3), want: []int{7, 6, 5, 4, 3}},
+		{title: "single", from: sochain.HeightRef(0), to: sochain.HeightRef(0), want: []int{0}},
+		{title: "by hash", from: sochain.HashRef(blocks[17].Data.Blockhash), to: sochain.HashRef(blocks[19].Data.Blockhash), want: []int{17, 18, 19}},
+		{title: "mixed", from: sochain.HashRef(blocks[2].Data.Blockhash), to: sochain.HeightRef(0), want: []int{2, 1, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			mCtrl := gomock.NewController(t)
+			defer mCtrl.Finish()
+
+			m := mock_client.NewMockConnector(mCtrl)
+			serve(m, blocks)
+
+			got, err := walk(sochain.NewBlockIterator(context.Background(), m, "btc", tt.from, tt.to, sochain.WithPrefetch(2)))
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_BlockIterator_Error(t *testing.T) {
+	broken := chain(10)
+	broken[5] = &sochain.Block{Data: sochain.BlockData{BlockNo: 5, Blockhash: fmt.Sprintf("%064x", 99), PreviousBlockhash: broken[4].Data.Blockhash}}
+
+	tests := []struct {
+		title    string
+		blocks   []*sochain.Block
+		from, to sochain.BlockRef
+		opts     []sochain.IteratorOption
+		want     []int
+		wantErr  error
+		wantCode int
+	}{
+		{
+			title:    "next hash mismatch",
+			blocks:   broken,
+			from:     sochain.HeightRef(2),
+			to:       sochain.HeightRef(8),
+			want:     []int{2, 3, 4},
+			wantErr:  sochain.ErrChainMismatch,
+			wantCode: http.StatusBadGateway,
+		},
+		{
+			title:    "previous hash mismatch",
+			blocks:   broken,
+			from:     sochain.HeightRef(8),
+			to:       sochain.HeightRef(2),
+			want:     []int{8, 7, 6},
+			wantErr:  sochain.ErrChainMismatch,
+			wantCode: http.StatusBadGateway,
+		},
+		{
+			title:    "hash off chain",
+			blocks:   chain(10),
+			from:     sochain.HeightRef(2),
+			to:       sochain.HashRef(broken[5].Data.Blockhash),
+			wantErr:  sochain.ErrNotFound,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			title:    "end beyond tip",
+			blocks:   chain(10),
+			from:     sochain.HeightRef(8),
+			to:       sochain.HeightRef(11),
+			want:     []int{8, 9},
+			wantErr:  sochain.ErrNotFound,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			title:    "range too large",
+			blocks:   chain(10),
+			from:     sochain.HeightRef(0),
+			to:       sochain.HeightRef(9),
+			opts:     []sochain.IteratorOption{sochain.WithMaxBlocks(5)},
+			wantErr:  sochain.ErrRangeTooLarge,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			mCtrl := gomock.NewController(t)
+			defer mCtrl.Finish()
+
+			m := mock_client.NewMockConnector(mCtrl)
+			serve(m, tt.blocks)
+
+			got, err := walk(sochain.NewBlockIterator(context.Background(), m, "btc", tt.from, tt.to, tt.opts...))
+			assert.Equal(t, tt.want, got)
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), err)
+			}
+
+			var cErr *sochain.ClientError
+			assert.True(t, errors.As(err, &cErr))
+			assert.Equal(t, tt.wantCode, cErr.Code())
+		})
+	}
+}
+
+func Test_BlockIterator_Prefetch(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	blocks := chain(100)
+	var inFlight, maxInFlight int32
+	m := mock_client.NewMockConnector(mCtrl)
+	m.EXPECT().BlockHeight(gomock.Any(), "btc", gomock.Any()).AnyTimes().DoAndReturn(func(_ context.Context, _ string, height int) (*sochain.Block, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+
+		time.Sleep(time.Millisecond)
+		return blocks[height], nil
+	})
+
+	got, err := walk(sochain.NewBlockIterator(context.Background(), m, "btc", sochain.HeightRef(0), sochain.HeightRef(99), sochain.WithPrefetch(4)))
+	assert.Nil(t, err)
+	assert.Equal(t, 100, len(got))
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(4))
+	assert.Greater(t, atomic.LoadInt32(&maxInFlight), int32(1))
+}
+
+func Test_BlockIterator_Cancel(t *testing.T) {
+	mCtrl := gomock.NewController(t)
+	defer mCtrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	blocks := chain(100)
+	m := mock_client.NewMockConnector(mCtrl)
+	m.EXPECT().BlockHeight(gomock.Any(), "btc", gomock.Any()).AnyTimes().DoAndReturn(func(ctx context.Context, _ string, height int) (*sochain.Block, error) {
+		if height == 3 {
+			cancel()
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return blocks[height], nil
+	})
+
+	got, err := walk(sochain.NewBlockIterator(ctx, m, "btc", sochain.HeightRef(0), sochain.HeightRef(99), sochain.WithPrefetch(1)))
+	assert.Equal(t, []int{0, 1, 2}, got)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
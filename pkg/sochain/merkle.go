@@ -0,0 +1,49 @@
+package sochain
+
+import (
+	"errors"
+	"fmt"
+	"sochain-client/pkg/merkle"
+	"strings"
+)
+
+// MerkleStatus is the result of checking the merkle root of a block against its transactions
+type MerkleStatus string
+
+const (
+	MerkleVerified MerkleStatus = "verified"
+	MerkleMismatch MerkleStatus = "mismatch"
+	// the block lacks its merkle root or transactions
+	MerkleUnverified MerkleStatus = "unverified"
+)
+
+// VerifyMerkleRoot checks that Txs hash to Merkleroot. Mismatches & blocks which can't be checked are returned as
+// *ClientError matching ErrMerkleMismatch
+func (b BlockData) VerifyMerkleRoot() error {
+	_, err := b.checkMerkleRoot()
+	return err
+}
+
+func (b BlockData) MerkleStatus() MerkleStatus {
+	status, _ := b.checkMerkleRoot()
+	return status
+}
+
+func (b BlockData) checkMerkleRoot() (MerkleStatus, error) {
+	if b.Merkleroot == "" {
+		return MerkleUnverified, newMismatchErr(ErrMerkleMismatch, "blockhash", fmt.Errorf("merkle root of block '%s' missing", b.Blockhash))
+	}
+
+	root, err := merkle.Root(b.Txs)
+	if errors.Is(err, merkle.ErrMutated) {
+		return MerkleMismatch, newMismatchErr(ErrMerkleMismatch, "blockhash", fmt.Errorf("txs of block '%s' are mutated: %w", b.Blockhash, err))
+	}
+	if err != nil {
+		return MerkleUnverified, newMismatchErr(ErrMerkleMismatch, "blockhash", fmt.Errorf("unable to compute merkle root of block '%s': %w", b.Blockhash, err))
+	}
+	if !strings.EqualFold(root, b.Merkleroot) {
+		return MerkleMismatch, newMismatchErr(ErrMerkleMismatch, "blockhash", fmt.Errorf("txs of block '%s' hash to merkle root '%s', expected '%s'", b.Blockhash, root, b.Merkleroot))
+	}
+
+	return MerkleVerified, nil
+}
@@ -0,0 +1,80 @@
+package sochain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func block100000(t *testing.T) string {
+	b, err := ioutil.ReadFile("testdata/get_block_btc_100000.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func Test_BlockData_MerkleStatus(t *testing.T) {
+	var b Block
+	assert.Nil(t, json.Unmarshal([]byte(block100000(t)), &b))
+
+	assert.Equal(t, MerkleVerified, b.Data.MerkleStatus())
+	assert.Nil(t, b.Data.VerifyMerkleRoot())
+
+	reordered := b.Data
+	reordered.Txs = []string{b.Data.Txs[1], b.Data.Txs[0], b.Data.Txs[2], b.Data.Txs[3]}
+	assert.Equal(t, MerkleMismatch, reordered.MerkleStatus())
+	assert.True(t, errors.Is(reordered.VerifyMerkleRoot(), ErrMerkleMismatch))
+
+	missing := b.Data
+	missing.Txs = missing.Txs[:3]
+	assert.Equal(t, MerkleMismatch, missing.MerkleStatus())
+
+	// duplicated txs are a mismatch, not a block which can't be checked
+	mutated := b.Data
+	mutated.Txs = append(append([]string{}, b.Data.Txs...), b.Data.Txs...)
+	assert.Equal(t, MerkleMismatch, mutated.MerkleStatus())
+	assert.True(t, errors.Is(mutated.VerifyMerkleRoot(), ErrMerkleMismatch))
+
+	noRoot := b.Data
+	noRoot.Merkleroot = ""
+	assert.Equal(t, MerkleUnverified, noRoot.MerkleStatus())
+	assert.True(t, errors.Is(noRoot.VerifyMerkleRoot(), ErrMerkleMismatch))
+
+	noTxs := b.Data
+	noTxs.Txs = nil
+	assert.Equal(t, MerkleUnverified, noTxs.MerkleStatus())
+}
+
+func Test_BlockHeight_VerifyMerkle(t *testing.T) {
+	body := block100000(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/get_block/BTC/100000":
+			w.Write([]byte(body))
+		case "/get_block/LTC/100000":
+			w.Write([]byte(strings.Replace(body, `"8c14f0db`, `"9c14f0db`, 1)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	s := NewSochain(WithBaseURL(srv.URL), WithMerkleVerification(true))
+
+	_, err := s.BlockHeight(context.Background(), "btc", 100000)
+	assert.Nil(t, err)
+
+	_, err = s.BlockHeight(context.Background(), "ltc", 100000)
+	assert.True(t, errors.Is(err, ErrMerkleMismatch))
+
+	_, err = NewSochain(WithBaseURL(srv.URL)).BlockHeight(context.Background(), "ltc", 100000)
+	assert.Nil(t, err)
+}
@@ -5,8 +5,9 @@
 package mock_sochain
 
 import (
-	sochain "sochain-client/pkg/sochain"
+	context "context"
 	reflect "reflect"
+	sochain "sochain-client/pkg/sochain"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -34,62 +35,167 @@ func (m *MockConnector) EXPECT() *MockConnectorMockRecorder {
 	return m.recorder
 }
 
+// AddressBalance mocks base method.
+func (m *MockConnector) AddressBalance(ctx context.Context, networkID, address string) (*sochain.AddressBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddressBalance", ctx, networkID, address)
+	ret0, _ := ret[0].(*sochain.AddressBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddressBalance indicates an expected call of AddressBalance.
+func (mr *MockConnectorMockRecorder) AddressBalance(ctx, networkID, address interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddressBalance", reflect.TypeOf((*MockConnector)(nil).AddressBalance), ctx, networkID, address)
+}
+
 // BlockHash mocks base method.
-func (m *MockConnector) BlockHash(networkID, blockHash string) (*sochain.Block, error) {
+func (m *MockConnector) BlockHash(ctx context.Context, networkID, blockHash string) (*sochain.Block, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BlockHash", networkID, blockHash)
+	ret := m.ctrl.Call(m, "BlockHash", ctx, networkID, blockHash)
 	ret0, _ := ret[0].(*sochain.Block)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // BlockHash indicates an expected call of BlockHash.
-func (mr *MockConnectorMockRecorder) BlockHash(networkID, blockHash interface{}) *gomock.Call {
+func (mr *MockConnectorMockRecorder) BlockHash(ctx, networkID, blockHash interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockHash", reflect.TypeOf((*MockConnector)(nil).BlockHash), networkID, blockHash)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockHash", reflect.TypeOf((*MockConnector)(nil).BlockHash), ctx, networkID, blockHash)
 }
 
 // BlockHeight mocks base method.
-func (m *MockConnector) BlockHeight(networkID string, height int) (*sochain.Block, error) {
+func (m *MockConnector) BlockHeight(ctx context.Context, networkID string, height int) (*sochain.Block, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BlockHeight", networkID, height)
+	ret := m.ctrl.Call(m, "BlockHeight", ctx, networkID, height)
 	ret0, _ := ret[0].(*sochain.Block)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // BlockHeight indicates an expected call of BlockHeight.
-func (mr *MockConnectorMockRecorder) BlockHeight(networkID, height interface{}) *gomock.Call {
+func (mr *MockConnectorMockRecorder) BlockHeight(ctx, networkID, height interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockHeight", reflect.TypeOf((*MockConnector)(nil).BlockHeight), networkID, height)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockHeight", reflect.TypeOf((*MockConnector)(nil).BlockHeight), ctx, networkID, height)
+}
+
+// BroadcastTransaction mocks base method.
+func (m *MockConnector) BroadcastTransaction(ctx context.Context, networkID, txHex string) (*sochain.BroadcastTx, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BroadcastTransaction", ctx, networkID, txHex)
+	ret0, _ := ret[0].(*sochain.BroadcastTx)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BroadcastTransaction indicates an expected call of BroadcastTransaction.
+func (mr *MockConnectorMockRecorder) BroadcastTransaction(ctx, networkID, txHex interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BroadcastTransaction", reflect.TypeOf((*MockConnector)(nil).BroadcastTransaction), ctx, networkID, txHex)
 }
 
 // NetworkInfo mocks base method.
-func (m *MockConnector) NetworkInfo(networkID string) (*sochain.NetworkInfo, error) {
+func (m *MockConnector) NetworkInfo(ctx context.Context, networkID string) (*sochain.NetworkInfo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "NetworkInfo", networkID)
+	ret := m.ctrl.Call(m, "NetworkInfo", ctx, networkID)
 	ret0, _ := ret[0].(*sochain.NetworkInfo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // NetworkInfo indicates an expected call of NetworkInfo.
-func (mr *MockConnectorMockRecorder) NetworkInfo(networkID interface{}) *gomock.Call {
+func (mr *MockConnectorMockRecorder) NetworkInfo(ctx, networkID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NetworkInfo", reflect.TypeOf((*MockConnector)(nil).NetworkInfo), ctx, networkID)
+}
+
+// OutputSpender mocks base method.
+func (m *MockConnector) OutputSpender(ctx context.Context, networkID, txHash string, outputNo int) (*sochain.SpentBy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OutputSpender", ctx, networkID, txHash, outputNo)
+	ret0, _ := ret[0].(*sochain.SpentBy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OutputSpender indicates an expected call of OutputSpender.
+func (mr *MockConnectorMockRecorder) OutputSpender(ctx, networkID, txHash, outputNo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OutputSpender", reflect.TypeOf((*MockConnector)(nil).OutputSpender), ctx, networkID, txHash, outputNo)
+}
+
+// ReceivedTransactions mocks base method.
+func (m *MockConnector) ReceivedTransactions(ctx context.Context, networkID, address, afterTxid string) (*sochain.ReceivedTxs, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceivedTransactions", ctx, networkID, address, afterTxid)
+	ret0, _ := ret[0].(*sochain.ReceivedTxs)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReceivedTransactions indicates an expected call of ReceivedTransactions.
+func (mr *MockConnectorMockRecorder) ReceivedTransactions(ctx, networkID, address, afterTxid interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NetworkInfo", reflect.TypeOf((*MockConnector)(nil).NetworkInfo), networkID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceivedTransactions", reflect.TypeOf((*MockConnector)(nil).ReceivedTransactions), ctx, networkID, address, afterTxid)
+}
+
+// SpentTransactions mocks base method.
+func (m *MockConnector) SpentTransactions(ctx context.Context, networkID, address, afterTxid string) (*sochain.SpentTxs, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SpentTransactions", ctx, networkID, address, afterTxid)
+	ret0, _ := ret[0].(*sochain.SpentTxs)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SpentTransactions indicates an expected call of SpentTransactions.
+func (mr *MockConnectorMockRecorder) SpentTransactions(ctx, networkID, address, afterTxid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SpentTransactions", reflect.TypeOf((*MockConnector)(nil).SpentTransactions), ctx, networkID, address, afterTxid)
 }
 
 // Transaction mocks base method.
-func (m *MockConnector) Transaction(networkID, txHash string) (*sochain.Transaction, error) {
+func (m *MockConnector) Transaction(ctx context.Context, networkID, txHash string) (*sochain.Transaction, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Transaction", networkID, txHash)
+	ret := m.ctrl.Call(m, "Transaction", ctx, networkID, txHash)
 	ret0, _ := ret[0].(*sochain.Transaction)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Transaction indicates an expected call of Transaction.
-func (mr *MockConnectorMockRecorder) Transaction(networkID, txHash interface{}) *gomock.Call {
+func (mr *MockConnectorMockRecorder) Transaction(ctx, networkID, txHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transaction", reflect.TypeOf((*MockConnector)(nil).Transaction), ctx, networkID, txHash)
+}
+
+// Transactions mocks base method.
+func (m *MockConnector) Transactions(ctx context.Context, networkID string, hashes []string) ([]sochain.TxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transactions", ctx, networkID, hashes)
+	ret0, _ := ret[0].([]sochain.TxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transactions indicates an expected call of Transactions.
+func (mr *MockConnectorMockRecorder) Transactions(ctx, networkID, hashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transactions", reflect.TypeOf((*MockConnector)(nil).Transactions), ctx, networkID, hashes)
+}
+
+// UnspentOutputs mocks base method.
+func (m *MockConnector) UnspentOutputs(ctx context.Context, networkID, address, afterTxid string) (*sochain.UnspentOutputs, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnspentOutputs", ctx, networkID, address, afterTxid)
+	ret0, _ := ret[0].(*sochain.UnspentOutputs)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnspentOutputs indicates an expected call of UnspentOutputs.
+func (mr *MockConnectorMockRecorder) UnspentOutputs(ctx, networkID, address, afterTxid interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transaction", reflect.TypeOf((*MockConnector)(nil).Transaction), networkID, txHash)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnspentOutputs", reflect.TypeOf((*MockConnector)(nil).UnspentOutputs), ctx, networkID, address, afterTxid)
 }
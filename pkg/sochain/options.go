@@ -0,0 +1,93 @@
+package sochain
+
+import (
+	"net/http"
+	"sochain-client/pkg/network"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Option configures a Sochain client created by NewSochain
+type Option func(*Sochain)
+
+// WithBaseURL overrides the sochain API url, e.g. to point the client at a mirror or a local stand-in server
+func WithBaseURL(baseURL string) Option {
+	return func(s *Sochain) {
+		s.baseUrl = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithHTTPClient replaces the underlying http client. WithTransport & WithTimeout apply to a copy, c itself is never modified
+func WithHTTPClient(c *http.Client) Option {
+	return func(s *Sochain) {
+		s.Client = c
+	}
+}
+
+// WithTransport sets the RoundTripper of the underlying http client, e.g. to route requests through a proxy
+func WithTransport(rt http.RoundTripper) Option {
+	return func(s *Sochain) {
+		s.transport = rt
+	}
+}
+
+// WithTimeout limits the time of a single request including reading the response body
+func WithTimeout(d time.Duration) Option {
+	return func(s *Sochain) {
+		s.timeout = d
+	}
+}
+
+// WithUserAgent sets the User-Agent header of all requests
+func WithUserAgent(userAgent string) Option {
+	return func(s *Sochain) {
+		s.userAgent = userAgent
+	}
+}
+
+// WithHeader adds an extra header to all requests
+func WithHeader(key, value string) Option {
+	return func(s *Sochain) {
+		s.header.Add(key, value)
+	}
+}
+
+// WithLogger logs retries & other upstream events to l
+func WithLogger(l *zap.Logger) Option {
+	return func(s *Sochain) {
+		s.logger = l
+	}
+}
+
+// WithNetworks validates network ids against r & resolves their Sochain identifiers, defaults to network.DefaultRegistry
+func WithNetworks(r *network.Registry) Option {
+	return func(s *Sochain) {
+		s.networks = r
+	}
+}
+
+// WithTxVerification decodes the tx_hex of every fetched transaction & rejects transactions whose txid doesn't match
+// the requested hash as *ClientError matching ErrTxMismatch
+func WithTxVerification(verify bool) Option {
+	return func(s *Sochain) {
+		s.verifyTx = verify
+	}
+}
+
+// WithMerkleVerification checks the merkle root of every fetched block against its transactions & rejects mismatching
+// blocks as *ClientError matching ErrMerkleMismatch
+func WithMerkleVerification(verify bool) Option {
+	return func(s *Sochain) {
+		s.verifyMerkle = verify
+	}
+}
+
+// WithConcurrency limits the parallel requests of Transactions to n, default DefaultConcurrency. Requests are subject
+// to the rate limit as well
+func WithConcurrency(n int) Option {
+	return func(s *Sochain) {
+		s.concurrency = n
+	}
+}
@@ -0,0 +1,68 @@
+package sochain
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func Test_Options_BaseURL_UserAgent_Header(t *testing.T) {
+	var gotReq *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotReq = r
+		w.Write([]byte(`{"status":"success","data":{"network":"BTC","blocks":1}}`))
+	}))
+	defer srv.Close()
+
+	s := NewSochain(
+		WithBaseURL(srv.URL+"/api/v2/"),
+		WithUserAgent("sochain-client-test"),
+		WithHeader("X-Api-Key", "secret"),
+	)
+
+	got, err := s.NetworkInfo(context.Background(), "BTC")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, got.Data.Blocks)
+
+	assert.Equal(t, "/api/v2/get_info/BTC", gotReq.URL.Path)
+	assert.Equal(t, "sochain-client-test", gotReq.Header.Get("User-Agent"))
+	assert.Equal(t, "secret", gotReq.Header.Get("X-Api-Key"))
+}
+
+func Test_Options_Transport(t *testing.T) {
+	var gotURL string
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		gotURL = req.URL.String()
+		return httptest.NewRecorder().Result(), nil
+	})
+
+	custom := &http.Client{}
+	s := NewSochain(WithHTTPClient(custom), WithBaseURL("http://mirror.local"), WithTransport(rt))
+
+	_, err := s.BlockHeight(context.Background(), "BTC", 1)
+	assert.NotNil(t, err)
+	assert.Equal(t, "http://mirror.local/get_block/BTC/1", gotURL)
+	assert.Nil(t, custom.Transport)
+}
+
+func Test_Options_Timeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	s := NewSochain(WithBaseURL(srv.URL), WithTimeout(10*time.Millisecond))
+
+	_, err := s.Transaction(context.Background(), "BTC", "00000000000000000008fa3759141044ae3db1e6ec222e114651354f58d5cc42")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,100 @@
+package sochain
+
+import (
+	"fmt"
+	"net/http"
+	"sochain-client/pkg/merkle"
+	"strings"
+)
+
+// ProofResponse proves that a transaction is included in a block by the merkle branch from its txid to the merkle root
+// of the block header. Hex is the same proof in the format of Bitcoin Core's gettxoutproof
+type ProofResponse struct {
+	Txid              string   `json:"txid"`
+	Blockhash         string   `json:"blockhash"`
+	BlockNo           int      `json:"block_no"`
+	Version           int32    `json:"version"`
+	PreviousBlockhash string   `json:"previous_blockhash"`
+	Merkleroot        string   `json:"merkleroot"`
+	Time              int      `json:"time"`
+	Bits              uint32   `json:"bits"`
+	Nonce             uint32   `json:"nonce"`
+	TxIndex           int      `json:"tx_index"`
+	TxCount           int      `json:"tx_count"`
+	Branch            []string `json:"branch"`
+	Hex               string   `json:"hex"`
+}
+
+// Header returns the header of b, false if the provider didn't report all of its fields
+func (b BlockData) Header() (merkle.Header, bool) {
+	if b.Version == nil || b.Bits == nil || b.Nonce == nil {
+		return merkle.Header{}, false
+	}
+
+	return merkle.Header{
+		Version:           *b.Version,
+		PreviousBlockhash: b.PreviousBlockhash,
+		Merkleroot:        b.Merkleroot,
+		Time:              uint32(b.Time),
+		Bits:              *b.Bits,
+		Nonce:             *b.Nonce,
+	}, true
+}
+
+// Proof builds the inclusion proof of txid in b. Blocks whose txs don't hash to their merkle root or whose header
+// doesn't hash to their blockhash are returned as *ClientError matching ErrMerkleMismatch, txids missing from b as
+// *ClientError matching ErrTxMismatch. Without the full header, e.g. of sochain blocks, the proof can't be tied to the
+// blockhash & a *ClientError matching ErrUnsupported is returned
+func (b BlockData) Proof(txid string) (*ProofResponse, error) {
+	if err := b.VerifyMerkleRoot(); err != nil {
+		return nil, err
+	}
+
+	index, err := merkle.Index(b.Txs, txid)
+	if err != nil {
+		return nil, newMismatchErr(ErrTxMismatch, "txhash", fmt.Errorf("block '%s': %w", b.Blockhash, err))
+	}
+
+	branch, err := merkle.Branch(b.Txs, index)
+	if err != nil {
+		return nil, newMismatchErr(ErrMerkleMismatch, "blockhash", fmt.Errorf("unable to compute merkle branch of block '%s': %w", b.Blockhash, err))
+	}
+
+	header, ok := b.Header()
+	if !ok {
+		return nil, NewClientErr(fmt.Errorf("provider doesn't report version, bits & nonce of block '%s'", b.Blockhash), http.StatusNotImplemented)
+	}
+
+	hash, err := header.Hash()
+	if err != nil {
+		return nil, newMismatchErr(ErrMerkleMismatch, "blockhash", fmt.Errorf("invalid header of block '%s': %w", b.Blockhash, err))
+	}
+	if !strings.EqualFold(hash, b.Blockhash) {
+		return nil, newMismatchErr(ErrMerkleMismatch, "blockhash", fmt.Errorf("header of block '%s' hashes to '%s'", b.Blockhash, hash))
+	}
+
+	var hex string
+	proof, err := merkle.NewProof(header, b.Txs, index)
+	if err == nil {
+		hex, err = proof.Encode()
+	}
+	if err != nil {
+		return nil, newMismatchErr(ErrMerkleMismatch, "blockhash", fmt.Errorf("unable to encode proof of block '%s': %w", b.Blockhash, err))
+	}
+
+	return &ProofResponse{
+		Txid:              b.Txs[index],
+		Blockhash:         b.Blockhash,
+		BlockNo:           b.BlockNo,
+		Version:           header.Version,
+		PreviousBlockhash: b.PreviousBlockhash,
+		Merkleroot:        b.Merkleroot,
+		Time:              b.Time,
+		Bits:              header.Bits,
+		Nonce:             header.Nonce,
+		TxIndex:           index,
+		TxCount:           len(b.Txs),
+		Branch:            branch,
+		Hex:               hex,
+	}, nil
+}
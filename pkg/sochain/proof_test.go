@@ -0,0 +1,52 @@
+package sochain
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sochain-client/pkg/merkle"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BlockData_Proof(t *testing.T) {
+	var b Block
+	assert.Nil(t, json.Unmarshal([]byte(block100000(t)), &b))
+	txid := b.Data.Txs[2]
+
+	// sochain doesn't report the full header
+	_, ok := b.Data.Header()
+	assert.False(t, ok)
+
+	_, err := b.Data.Proof(txid)
+	assert.True(t, errors.Is(err, ErrUnsupported))
+	assert.Equal(t, http.StatusNotImplemented, StatusCode(err))
+
+	version, bits, nonce := int32(1), uint32(0x1b04864c), uint32(274148111)
+	b.Data.Version, b.Data.Bits, b.Data.Nonce = &version, &bits, &nonce
+
+	p, err := b.Data.Proof(txid)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, p.TxIndex)
+	assert.Equal(t, 4, p.TxCount)
+	assert.Equal(t, bits, p.Bits)
+	assert.Nil(t, merkle.VerifyBranch(txid, p.TxIndex, p.Branch, p.Merkleroot))
+
+	hash, err := merkle.VerifyProof(p.Hex, txid)
+	assert.Nil(t, err)
+	assert.Equal(t, b.Data.Blockhash, hash)
+
+	_, err = b.Data.Proof("0000000000000000000000000000000000000000000000000000000000000000")
+	assert.True(t, errors.Is(err, ErrTxMismatch))
+
+	tampered := b.Data
+	nonce++
+	_, err = tampered.Proof(txid)
+	assert.True(t, errors.Is(err, ErrMerkleMismatch))
+
+	reordered := b.Data
+	reordered.Txs = []string{b.Data.Txs[1], b.Data.Txs[0], b.Data.Txs[2], b.Data.Txs[3]}
+	_, err = reordered.Proof(txid)
+	assert.True(t, errors.Is(err, ErrMerkleMismatch))
+}
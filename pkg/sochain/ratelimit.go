@@ -0,0 +1,98 @@
+package sochain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimit is a token bucket refilled with Rate requests per second, holding up to Burst requests.
+// Burst must be at least 1, requests of a bucket without burst fail
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// WithRateLimit limits the requests of all networks without a network specific limit by a single shared bucket
+func WithRateLimit(l RateLimit) Option {
+	return func(s *Sochain) {
+		s.limiter.defaultLimit = &l
+	}
+}
+
+// WithNetworkRateLimit limits the requests of networkID by a dedicated bucket
+func WithNetworkRateLimit(networkID string, l RateLimit) Option {
+	return func(s *Sochain) {
+		s.limiter.networkLimits[strings.ToUpper(networkID)] = l
+	}
+}
+
+// WithRateLimitObserver reports the time every rate limited request waited for a token, e.g. to alert on a saturated quota
+func WithRateLimitObserver(f func(networkID string, wait time.Duration)) Option {
+	return func(s *Sochain) {
+		s.limiter.observer = f
+	}
+}
+
+type rateLimiter struct {
+	defaultLimit  *RateLimit
+	networkLimits map[string]RateLimit
+	observer      func(networkID string, wait time.Duration)
+
+	defaultLimiter *rate.Limiter
+	networks       map[string]*rate.Limiter
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		networkLimits: make(map[string]RateLimit),
+	}
+}
+
+// init creates the buckets once all options are applied
+func (r *rateLimiter) init() {
+	if r.defaultLimit != nil {
+		r.defaultLimiter = rate.NewLimiter(rate.Limit(r.defaultLimit.Rate), r.defaultLimit.Burst)
+	}
+
+	r.networks = make(map[string]*rate.Limiter, len(r.networkLimits))
+	for networkID, l := range r.networkLimits {
+		r.networks[networkID] = rate.NewLimiter(rate.Limit(l.Rate), l.Burst)
+	}
+}
+
+// wait blocks until the bucket of networkID grants a request or ctx is done
+func (r *rateLimiter) wait(ctx context.Context, networkID string) error {
+	limiter, ok := r.networks[strings.ToUpper(networkID)]
+	if !ok {
+		limiter = r.defaultLimiter
+	}
+	if limiter == nil {
+		return nil
+	}
+
+	if limiter.Burst() < 1 && limiter.Limit() != rate.Inf {
+		return fmt.Errorf("rate limit of network %s: burst %d, must be at least 1", networkID, limiter.Burst())
+	}
+
+	start := time.Now()
+	err := limiter.Wait(ctx)
+	if r.observer != nil {
+		r.observer(networkID, time.Since(start))
+	}
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if _, ok := ctx.Deadline(); !ok {
+			return err
+		}
+		// the limiter refuses to wait past the deadline of ctx
+		return fmt.Errorf("rate limit wait exceeds request deadline: %w", context.DeadlineExceeded)
+	}
+
+	return nil
+}
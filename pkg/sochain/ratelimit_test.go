@@ -0,0 +1,91 @@
+package sochain
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RateLimit_Wait(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success"}`))
+	}))
+	defer srv.Close()
+
+	var mu sync.Mutex
+	waits := make(map[string][]time.Duration)
+	observer := func(networkID string, wait time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		waits[networkID] = append(waits[networkID], wait)
+	}
+
+	s := NewSochain(
+		WithBaseURL(srv.URL),
+		WithRateLimit(RateLimit{Rate: 20, Burst: 1}),
+		WithNetworkRateLimit("ltc", RateLimit{Rate: 1000, Burst: 10}),
+		WithRateLimitObserver(observer),
+	)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := s.NetworkInfo(context.Background(), "BTC")
+		assert.Nil(t, err)
+	}
+	assert.True(t, time.Since(start) >= 90*time.Millisecond)
+
+	start = time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := s.NetworkInfo(context.Background(), "LTC")
+		assert.Nil(t, err)
+	}
+	assert.True(t, time.Since(start) < 50*time.Millisecond)
+
+	assert.Len(t, waits["BTC"], 3)
+	assert.True(t, waits["BTC"][2] > 0)
+	assert.Len(t, waits["LTC"], 3)
+}
+
+func Test_RateLimit_Error_ContextCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success"}`))
+	}))
+	defer srv.Close()
+
+	s := NewSochain(WithBaseURL(srv.URL), WithRateLimit(RateLimit{Rate: 0.1, Burst: 1}))
+
+	_, err := s.NetworkInfo(context.Background(), "BTC")
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = s.NetworkInfo(ctx, "BTC")
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = s.NetworkInfo(ctx, "BTC")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func Test_RateLimit_Error_ZeroBurst(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success"}`))
+	}))
+	defer srv.Close()
+
+	s := NewSochain(WithBaseURL(srv.URL), WithRateLimit(RateLimit{Rate: 10, Burst: 0}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := s.NetworkInfo(ctx, "BTC")
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, http.StatusInternalServerError, StatusCode(err))
+}
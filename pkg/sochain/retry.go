@@ -0,0 +1,118 @@
+package sochain
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed GET requests are retried.
+// Requests are retried on network errors & on RetryableStatus responses, all other errors are returned immediately
+type RetryPolicy struct {
+	// Total attempts including the first one, values below 2 disable retries
+	MaxAttempts int
+	// Backoff before the second attempt, doubled for every following attempt
+	BaseDelay time.Duration
+	// Upper bound of a single backoff, 0 is unbounded. Requests whose Retry-After exceeds MaxDelay are not retried
+	MaxDelay        time.Duration
+	RetryableStatus []int
+}
+
+// Retries 429 & transient 5xx responses up to 3 attempts
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+		RetryableStatus: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// WithRetryPolicy enables retries of failed requests
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(s *Sochain) {
+		s.retry = p
+	}
+}
+
+// WithRetryObserver reports every failed attempt which is retried, e.g. to count retries per network
+func WithRetryObserver(f func(networkID string, attempt int, err error)) Option {
+	return func(s *Sochain) {
+		s.retryObserver = f
+	}
+}
+
+func (p RetryPolicy) retryableStatus(statuscode int) bool {
+	for _, v := range p.RetryableStatus {
+		if v == statuscode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// backoff returns the delay before the next attempt. retryAfter of the upstream response takes precedence,
+// otherwise the exponential backoff is jittered into [delay/2, delay] to spread concurrent retries
+func (p RetryPolicy) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || delay < p.MaxDelay) && delay <= math.MaxInt64/2; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// parseRetryAfter supports both Retry-After formats, delay-seconds & HTTP-date
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(v); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+
+	return 0
+}
+
+// RetryError is returned once a request failed after more than one attempt, Err is the error of the last attempt
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (r *RetryError) Error() string {
+	return fmt.Sprintf("sochain request failed after %d attempts: %v", r.Attempts, r.Err)
+}
+
+func (r *RetryError) Unwrap() error {
+	return r.Err
+}
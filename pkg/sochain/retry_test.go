@@ -0,0 +1,170 @@
+package sochain
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testRetryPolicy() RetryPolicy {
+	p := DefaultRetryPolicy()
+	p.BaseDelay = time.Millisecond
+	p.MaxDelay = 5 * time.Millisecond
+	return p
+}
+
+func Test_Retry_Success_AfterTransientErrors(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write([]byte(`{"status":"success","data":{"txid":"txid"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	var retried []int
+	s := NewSochain(
+		WithBaseURL(srv.URL),
+		WithRetryPolicy(testRetryPolicy()),
+		WithRetryObserver(func(networkID string, attempt int, err error) {
+			assert.Equal(t, "BTC", networkID)
+			assert.NotNil(t, err)
+			retried = append(retried, attempt)
+		}),
+	)
+	got, err := s.Transaction(context.Background(), "BTC", "00000000000000000008fa3759141044ae3db1e6ec222e114651354f58d5cc42")
+	assert.Nil(t, err)
+	assert.Equal(t, "txid", got.Data.Txid)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Equal(t, []int{1, 2}, retried)
+}
+
+func Test_Retry_Error_MaxAttempts(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	s := NewSochain(WithBaseURL(srv.URL), WithRetryPolicy(testRetryPolicy()))
+	_, err := s.BlockHeight(context.Background(), "BTC", 1)
+
+	var rErr *RetryError
+	assert.True(t, errors.As(err, &rErr))
+	assert.Equal(t, 3, rErr.Attempts)
+
+	var cErr *ClientError
+	assert.True(t, errors.As(err, &cErr))
+	assert.Equal(t, http.StatusBadGateway, cErr.Code())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func Test_Retry_Error_NotRetryable(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	s := NewSochain(WithBaseURL(srv.URL), WithRetryPolicy(testRetryPolicy()))
+	_, err := s.BlockHash(context.Background(), "BTC", "00000000000000000008fa3759141044ae3db1e6ec222e114651354f58d5cc42")
+
+	var rErr *RetryError
+	assert.False(t, errors.As(err, &rErr))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func Test_Retry_Error_RetryAfterExceedsMaxDelay(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	s := NewSochain(WithBaseURL(srv.URL), WithRetryPolicy(testRetryPolicy()))
+	_, err := s.NetworkInfo(context.Background(), "BTC")
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func Test_Retry_Success_NetworkError(t *testing.T) {
+	var calls int32
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return nil, errors.New("connection reset")
+		}
+		rec := httptest.NewRecorder()
+		rec.Write([]byte(`{"status":"success"}`))
+		return rec.Result(), nil
+	})
+
+	s := NewSochain(WithTransport(rt), WithRetryPolicy(testRetryPolicy()))
+	_, err := s.NetworkInfo(context.Background(), "BTC")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func Test_Retry_Error_ContextCanceledDuringBackoff(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	p := testRetryPolicy()
+	p.BaseDelay = time.Second
+	p.MaxDelay = time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	s := NewSochain(WithBaseURL(srv.URL), WithRetryPolicy(p))
+	_, err := s.NetworkInfo(ctx, "BTC")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func Test_RetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for attempt, upper := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 400 * time.Millisecond, 10: time.Second} {
+		got := p.backoff(attempt, 0)
+		assert.True(t, got >= upper/2 && got <= upper, "attempt %d: %s", attempt, got)
+	}
+
+	assert.Equal(t, 3*time.Second, p.backoff(1, 3*time.Second))
+}
+
+func Test_RetryPolicy_Backoff_Unbounded(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond}
+
+	for attempt, upper := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 5: 1600 * time.Millisecond} {
+		got := p.backoff(attempt, 0)
+		assert.True(t, got >= upper/2 && got <= upper, "attempt %d: %s", attempt, got)
+	}
+
+	assert.True(t, p.backoff(100, 0) > 0)
+}
+
+func Test_ParseRetryAfter(t *testing.T) {
+	now := time.Date(2022, 3, 29, 18, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 5*time.Second, parseRetryAfter("5", now))
+	assert.Equal(t, 30*time.Second, parseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(now.Add(-time.Second).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("invalid", now))
+}
@@ -0,0 +1,327 @@
+// Package sochaintest provides a conformance suite for sochain.Connector implementations. Every implementation serves
+// the same chain data from its own upstream fixtures & has to pass Run
+package sochaintest
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sochain-client/pkg/sochain"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Chain data of the fixtures
+const (
+	TipHeight      = 800000
+	UnconfirmedTxs = 12345
+
+	BlockHeight = 100000
+	BlockHash   = "000000000003ba27aa200b1cecaad478d2b00432346c3f1f3986da1afd33e506"
+
+	// coinbase transaction of the genesis block
+	TxHash = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	TxHex  = "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff4d04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73ffffffff0100f2052a01000000434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac00000000"
+	// unknown to the upstream
+	MissingTxHash = "0000000000000000000000000000000000000000000000000000000000000001"
+
+	// genesis address, funded by the genesis coinbase & DonationTxHash
+	Address        = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+	DonationTxHash = "3433844a443e80708d701b27c3442d17911a71d7269f6495309237829d82fbc3"
+
+	// accepted on btc, rejected as already known on btctest
+	BroadcastTxHex = "0200000000010184fd9bac333ad79154348296204fa7f8c537a96e08983e5f73b3f5aca8e8edf70100000000fdffffff02f049020000000000160014ca978112ca1bbdcafac231b39a23dc4da786eff890a6f802000000001600143e23e8160039594a33894f6564e1b1348bbd7a00024730440220454349e422f05297191ead13e21d3db520e5abef52055e4964b82fb213f593a10220043a718774c572bd8a25adbeb1bfcd5c0256ae11cecf9f9c3f925d0e52beaf890121020017dea7770f7ecff7ab3c20506546129e96bdeba2f544bb8e5414eb797861220a000000"
+	BroadcastTxid  = "79d1188c6480a21ad4d89c38569ba52fe8f372ce044a389dc8a285b773d25fe8"
+)
+
+// Fixture is an upstream response
+type Fixture struct {
+	// defaults to 200
+	Status int
+	// path of the body, relative to the package of the test
+	File string
+}
+
+// NewServer serves fixtures keyed by method & path, e.g. "GET /get_info/BTC". Unknown requests fail t
+func NewServer(t *testing.T, fixtures map[string]Fixture) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, ok := fixtures[r.Method+" "+r.URL.Path]
+		if !ok {
+			t.Errorf("no fixture for %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+
+		body, err := ioutil.ReadFile(f.File)
+		if err != nil {
+			t.Errorf("unable to read fixture: %s", err)
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+
+		if strings.HasSuffix(f.File, ".json") {
+			w.Header().Set("Content-Type", "application/json")
+		} else {
+			w.Header().Set("Content-Type", "text/plain")
+		}
+		if f.Status != 0 {
+			w.WriteHeader(f.Status)
+		}
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// Run checks c against the chain data of the fixtures. Methods failing with sochain.ErrUnsupported are skipped
+func Run(t *testing.T, c sochain.Connector) {
+	ctx := context.Background()
+
+	t.Run("NetworkInfo", func(t *testing.T) {
+		got, err := c.NetworkInfo(ctx, "btc")
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		assert.Equal(t, "BTC", got.Data.Network)
+		assert.Equal(t, "BTC", got.Data.Acronym)
+		assert.Equal(t, TipHeight, got.Data.Blocks)
+		assert.Equal(t, UnconfirmedTxs, got.Data.UnconfirmedTxs)
+	})
+
+	t.Run("BlockHeight", func(t *testing.T) {
+		got, err := c.BlockHeight(ctx, "btc", BlockHeight)
+		if assert.Nil(t, err) {
+			assertBlock(t, got)
+		}
+	})
+
+	t.Run("BlockHash", func(t *testing.T) {
+		got, err := c.BlockHash(ctx, "btc", BlockHash)
+		if assert.Nil(t, err) {
+			assertBlock(t, got)
+		}
+	})
+
+	t.Run("Transaction", func(t *testing.T) {
+		got, err := c.Transaction(ctx, "btc", TxHash)
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		d := got.Data
+		assert.Equal(t, "BTC", d.Network)
+		assert.Equal(t, TxHash, d.Txid)
+		assert.Equal(t, "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f", d.Blockhash)
+		assert.Equal(t, 0, d.BlockNo)
+		assert.Equal(t, TipHeight+1, d.Confirmations)
+		assert.Equal(t, 1231006505, d.Time)
+		assert.Equal(t, 204, d.Size)
+		assert.Equal(t, 204, d.Vsize)
+		assert.Equal(t, 1, d.Version)
+		assert.Equal(t, 0, d.Locktime)
+		assert.Equal(t, sochain.Amount(5000000000), d.SentValue)
+		assert.Equal(t, sochain.Amount(0), d.Fee)
+		assert.Equal(t, TxHex, d.TxHex)
+		if assert.Len(t, d.Inputs, 1) {
+			_, ok := d.Inputs[0].ReceivedFrom.Ref("btc")
+			assert.False(t, ok, "coinbase input received from an output")
+		}
+		if assert.Len(t, d.Outputs, 1) {
+			o := d.Outputs[0]
+			assert.Equal(t, 0, o.OutputNo)
+			assert.Equal(t, Address, o.Address)
+			assert.Equal(t, sochain.Amount(5000000000), o.Value)
+			assert.Equal(t, "pubkey", o.Type)
+			assert.Nil(t, o.Spent)
+			assert.Equal(t, "4104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac", o.ScriptHex)
+		}
+	})
+
+	t.Run("Transactions", func(t *testing.T) {
+		got, err := c.Transactions(ctx, "btc", []string{MissingTxHash, TxHash, "xyz"})
+		if !assert.Nil(t, err) || !assert.Len(t, got, 3) {
+			return
+		}
+
+		assert.Equal(t, MissingTxHash, got[0].Hash)
+		assert.ErrorIs(t, got[0].Err, sochain.ErrNotFound)
+		assert.Equal(t, TxHash, got[1].Hash)
+		if assert.Nil(t, got[1].Err) {
+			assert.Equal(t, TxHash, got[1].Tx.Data.Txid)
+		}
+		assert.ErrorIs(t, got[2].Err, sochain.ErrInvalidHash)
+
+		_, err = c.Transactions(ctx, "xyz", []string{TxHash})
+		assert.ErrorIs(t, err, sochain.ErrInvalidNetwork)
+	})
+
+	t.Run("OutputSpender", func(t *testing.T) {
+		got, err := c.OutputSpender(ctx, "btc", TxHash, 0)
+		skipUnsupported(t, err)
+		if assert.Nil(t, err) {
+			assert.Nil(t, got)
+		}
+
+		_, err = c.OutputSpender(ctx, "btc", TxHash, 1)
+		assert.ErrorIs(t, err, sochain.ErrNotFound)
+	})
+
+	t.Run("AddressBalance", func(t *testing.T) {
+		got, err := c.AddressBalance(ctx, "btc", Address)
+		skipUnsupported(t, err)
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		assert.Equal(t, "BTC", got.Data.Network)
+		assert.Equal(t, Address, got.Data.Address)
+		assert.Equal(t, sochain.Amount(5000005000), got.Data.ConfirmedBalance)
+		assert.Equal(t, sochain.Amount(1000), got.Data.UnconfirmedBalance)
+	})
+
+	t.Run("ReceivedTransactions", func(t *testing.T) {
+		got, err := c.ReceivedTransactions(ctx, "btc", Address, "")
+		skipUnsupported(t, err)
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		assert.Equal(t, Address, got.Data.Address)
+		assert.ElementsMatch(t, []sochain.ReceivedTx{genesisReceived(), donationReceived()}, withoutScripts(got.Data.Txs))
+
+		got, err = c.ReceivedTransactions(ctx, "btc", Address, DonationTxHash)
+		if assert.Nil(t, err) {
+			assert.Equal(t, []sochain.ReceivedTx{genesisReceived()}, withoutScripts(got.Data.Txs))
+		}
+	})
+
+	t.Run("SpentTransactions", func(t *testing.T) {
+		got, err := c.SpentTransactions(ctx, "btc", Address, "")
+		skipUnsupported(t, err)
+		if assert.Nil(t, err) {
+			assert.Equal(t, Address, got.Data.Address)
+			assert.Empty(t, got.Data.Txs)
+		}
+	})
+
+	t.Run("UnspentOutputs", func(t *testing.T) {
+		got, err := c.UnspentOutputs(ctx, "btc", Address, "")
+		skipUnsupported(t, err)
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		assert.Equal(t, Address, got.Data.Address)
+		assert.ElementsMatch(t, []string{TxHash, DonationTxHash}, unspentTxids(got.Data.Txs))
+
+		got, err = c.UnspentOutputs(ctx, "btc", Address, DonationTxHash)
+		if assert.Nil(t, err) {
+			assert.Equal(t, []string{TxHash}, unspentTxids(got.Data.Txs))
+		}
+	})
+
+	t.Run("BroadcastTransaction", func(t *testing.T) {
+		got, err := c.BroadcastTransaction(ctx, "btc", BroadcastTxHex)
+		if assert.Nil(t, err) {
+			assert.Equal(t, BroadcastTxid, got.Data.Txid)
+		}
+
+		_, err = c.BroadcastTransaction(ctx, "btctest", BroadcastTxHex)
+		var bErr *sochain.BroadcastError
+		if assert.True(t, errors.As(err, &bErr)) {
+			assert.Equal(t, sochain.RejectAlreadyKnown, bErr.Reason)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := c.Transaction(ctx, "btc", MissingTxHash)
+		assert.ErrorIs(t, err, sochain.ErrNotFound)
+
+		_, err = c.NetworkInfo(ctx, "xyz")
+		assert.ErrorIs(t, err, sochain.ErrInvalidNetwork)
+
+		_, err = c.BlockHash(ctx, "btc", "xyz")
+		assert.ErrorIs(t, err, sochain.ErrInvalidHash)
+
+		_, err = c.Transaction(ctx, "btc", "xyz")
+		assert.ErrorIs(t, err, sochain.ErrInvalidHash)
+
+		_, err = c.AddressBalance(ctx, "btc", "xyz")
+		assert.ErrorIs(t, err, sochain.ErrInvalidAddress)
+	})
+}
+
+// skipUnsupported skips the test of a method c doesn't implement
+func skipUnsupported(t *testing.T, err error) {
+	t.Helper()
+
+	if errors.Is(err, sochain.ErrUnsupported) {
+		t.Skipf("unsupported: %s", err)
+	}
+}
+
+func assertBlock(t *testing.T, got *sochain.Block) {
+	t.Helper()
+
+	d := got.Data
+	assert.Equal(t, "BTC", d.Network)
+	assert.Equal(t, BlockHash, d.Blockhash)
+	assert.Equal(t, BlockHeight, d.BlockNo)
+	assert.Equal(t, 1293623863, d.Time)
+	assert.Equal(t, TipHeight-BlockHeight+1, d.Confirmations)
+	assert.False(t, d.IsOrphan)
+	assert.Equal(t, "f3e94742aca4b5ef85488dc37c06c3282295ffec960994b2c0d5ac2a25a95766", d.Merkleroot)
+	assert.Equal(t, "000000000002d01c1fccc21636b607dfd930d31d01c3a62104612a1719011250", d.PreviousBlockhash)
+	assert.Equal(t, "00000000000080b66c911bd5ba14a74260057311eaeb1982802f7010f1a9f090", d.NextBlockhash)
+	assert.Equal(t, 957, d.Size)
+	assert.Equal(t, []string{
+		"8c14f0db3df150123e6f3dbbf30f8b955a8249b62ac1d1ff16284aefa3d06d87",
+		"fff2525b8931402dd09222c50775608f75787bd2b87e56995a7bdd30f79702c4",
+		"6359f0868171b1d194cbee1af2f16ea598ae8fad666d9b012c8ed2b79a236ec4",
+		"e9a66845e05d5abc0ad04ec80f774a7e585c6e8db975962d069a522137b80c1d",
+	}, d.Txs)
+	assert.Equal(t, sochain.MerkleVerified, d.MerkleStatus())
+
+	// providers reporting the full header must report the one hashing to the block
+	if header, ok := d.Header(); ok {
+		hash, err := header.Hash()
+		assert.Nil(t, err)
+		assert.Equal(t, BlockHash, hash)
+	}
+}
+
+func genesisReceived() sochain.ReceivedTx {
+	return sochain.ReceivedTx{Txid: TxHash, OutputNo: 0, Value: 5000000000, Confirmations: TipHeight + 1, Time: 1231006505}
+}
+
+func donationReceived() sochain.ReceivedTx {
+	return sochain.ReceivedTx{Txid: DonationTxHash, OutputNo: 1, Value: 5000, Confirmations: TipHeight - 500000 + 1, Time: 1513622125}
+}
+
+// withoutScripts drops the scripts of txs, upstreams disagree on the notation of script_asm
+func withoutScripts(txs []sochain.ReceivedTx) []sochain.ReceivedTx {
+	r := make([]sochain.ReceivedTx, len(txs))
+	for i, tx := range txs {
+		tx.ScriptAsm, tx.ScriptHex = "", ""
+		r[i] = tx
+	}
+
+	return r
+}
+
+func unspentTxids(outputs []sochain.UnspentOutput) []string {
+	r := make([]string, len(outputs))
+	for i, o := range outputs {
+		r[i] = o.Txid
+	}
+
+	return r
+}
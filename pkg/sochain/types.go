@@ -1,6 +1,10 @@
 package sochain
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 type NetworkInfos []NetworkInfo
 type NetworkInfo struct {
@@ -9,18 +13,18 @@ type NetworkInfo struct {
 }
 
 type NetworkData struct {
-	Name             string `json:"name"`
-	Acronym          string `json:"acronym"`
-	Network          string `json:"network"`
-	SymbolHtmlcode   string `json:"symbol_htmlcode"`
-	URL              string `json:"url"`
-	MiningDifficulty string `json:"mining_difficulty"`
-	UnconfirmedTxs   int    `json:"unconfirmed_txs"`
-	Blocks           int    `json:"blocks"`
-	Price            string `json:"price"`
-	PriceBase        string `json:"price_base"`
-	PriceUpdateTime  int    `json:"price_update_time"`
-	Hashrate         string `json:"hashrate"`
+	Name             string  `json:"name"`
+	Acronym          string  `json:"acronym"`
+	Network          string  `json:"network"`
+	SymbolHtmlcode   string  `json:"symbol_htmlcode"`
+	URL              string  `json:"url"`
+	MiningDifficulty string  `json:"mining_difficulty"`
+	UnconfirmedTxs   int     `json:"unconfirmed_txs"`
+	Blocks           int     `json:"blocks"`
+	Price            Decimal `json:"price"`
+	PriceBase        string  `json:"price_base"`
+	PriceUpdateTime  int     `json:"price_update_time"`
+	Hashrate         string  `json:"hashrate"`
 }
 
 type Blocks []Block
@@ -42,6 +46,32 @@ type BlockData struct {
 	PreviousBlockhash string   `json:"previous_blockhash"`
 	NextBlockhash     string   `json:"next_blockhash"`
 	Size              int      `json:"size"`
+	// header fields sochain doesn't report, nil if unknown
+	Version *int32  `json:"version,omitempty"`
+	Bits    *uint32 `json:"bits,omitempty"`
+	Nonce   *uint32 `json:"nonce,omitempty"`
+}
+
+// Copy returns a deep copy of b sharing no slices or pointers with it
+func (b *Block) Copy() *Block {
+	c := *b
+	if b.Data.Txs != nil {
+		c.Data.Txs = append([]string(nil), b.Data.Txs...)
+	}
+	if b.Data.Version != nil {
+		v := *b.Data.Version
+		c.Data.Version = &v
+	}
+	if b.Data.Bits != nil {
+		v := *b.Data.Bits
+		c.Data.Bits = &v
+	}
+	if b.Data.Nonce != nil {
+		v := *b.Data.Nonce
+		c.Data.Nonce = &v
+	}
+
+	return &c
 }
 
 type BlockResponse struct {
@@ -50,6 +80,8 @@ type BlockResponse struct {
 	PreviousHash string               `json:"previoushash"`
 	NextHash     string               `json:"nexthash"`
 	Size         int                  `json:"size"`
+	MerkleRoot   string               `json:"merkleroot"`
+	MerkleStatus MerkleStatus         `json:"merkle_status"`
 	Transactions TransactionResponses `json:"transactions"`
 }
 
@@ -60,6 +92,35 @@ func (b *Block) Response() BlockResponse {
 		PreviousHash: b.Data.PreviousBlockhash,
 		NextHash:     b.Data.NextBlockhash,
 		Size:         b.Data.Size,
+		MerkleRoot:   b.Data.Merkleroot,
+		MerkleStatus: b.Data.MerkleStatus(),
+	}
+}
+
+// BlockSummaryResponse is a block without its transactions, as listed by block ranges
+type BlockSummaryResponse struct {
+	Blocknumber  int          `json:"blocknumber"`
+	Blockhash    string       `json:"blockhash"`
+	Timestamp    string       `json:"timestamp"`
+	PreviousHash string       `json:"previoushash"`
+	NextHash     string       `json:"nexthash"`
+	Size         int          `json:"size"`
+	MerkleRoot   string       `json:"merkleroot"`
+	MerkleStatus MerkleStatus `json:"merkle_status"`
+	TxCount      int          `json:"tx_count"`
+}
+
+func (b *Block) SummaryResponse() BlockSummaryResponse {
+	return BlockSummaryResponse{
+		Blocknumber:  b.Data.BlockNo,
+		Blockhash:    b.Data.Blockhash,
+		Timestamp:    time.Unix(int64(b.Data.Time), 0).Format(time.RFC3339),
+		PreviousHash: b.Data.PreviousBlockhash,
+		NextHash:     b.Data.NextBlockhash,
+		Size:         b.Data.Size,
+		MerkleRoot:   b.Data.Merkleroot,
+		MerkleStatus: b.Data.MerkleStatus(),
+		TxCount:      len(b.Data.Txs),
 	}
 }
 
@@ -82,13 +143,47 @@ type TransactionData struct {
 	Vsize         int     `json:"vsize"`
 	Version       int     `json:"version"`
 	Locktime      int     `json:"locktime"`
-	SentValue     string  `json:"sent_value"`
-	Fee           string  `json:"fee"`
+	SentValue     Amount  `json:"sent_value"`
+	Fee           Amount  `json:"fee"`
 	Inputs        Inputs  `json:"inputs"`
 	Outputs       Outputs `json:"outputs"`
 	TxHex         string  `json:"tx_hex"`
 }
 
+// Copy returns a deep copy of t sharing no slices or pointers with it
+func (t *Transaction) Copy() *Transaction {
+	c := *t
+	if t.Data.Inputs != nil {
+		c.Data.Inputs = make(Inputs, len(t.Data.Inputs))
+		for i, in := range t.Data.Inputs {
+			if in.ReceivedFrom != nil {
+				r := *in.ReceivedFrom
+				in.ReceivedFrom = &r
+			}
+			if in.Witness != nil {
+				in.Witness = append([]string(nil), in.Witness...)
+			}
+			c.Data.Inputs[i] = in
+		}
+	}
+	if t.Data.Outputs != nil {
+		c.Data.Outputs = make(Outputs, len(t.Data.Outputs))
+		for i, out := range t.Data.Outputs {
+			if out.ReqSigs != nil {
+				n := *out.ReqSigs
+				out.ReqSigs = &n
+			}
+			if out.Spent != nil {
+				s := *out.Spent
+				out.Spent = &s
+			}
+			c.Data.Outputs[i] = out
+		}
+	}
+
+	return &c
+}
+
 type TransactionResponses []TransactionResponse
 
 func (t Transactions) Response() TransactionResponses {
@@ -112,29 +207,272 @@ func (t Transaction) Response() TransactionResponse {
 type TransactionResponse struct {
 	TxID      string `json:"txid,omitempty"`
 	Timestamp string `json:"time,omitempty"`
-	Fee       string `json:"fee,omitempty"`
-	Value     string `json:"sent_value,omitempty"`
+	Fee       Amount `json:"fee"`
+	Value     Amount `json:"sent_value"`
+	// set on request only
+	Decoded *DecodedTxResponse `json:"decoded,omitempty"`
+}
+
+// SpenderResponse is the transaction spending an output & the number of the spending input
+type SpenderResponse struct {
+	TransactionResponse
+	InputNo int `json:"input_no"`
 }
 
 type Inputs []Input
 type Input struct {
-	InputNo      int         `json:"input_no"`
-	Address      string      `json:"address"`
-	Value        string      `json:"value"`
-	ReceivedFrom interface{} `json:"received_from"`
-	ScriptAsm    string      `json:"script_asm"`
-	ScriptHex    interface{} `json:"script_hex"`
-	Witness      []string    `json:"witness"`
+	InputNo int    `json:"input_no"`
+	Address string `json:"address"`
+	Value   Amount `json:"value"`
+	// nil for coinbase inputs
+	ReceivedFrom *ReceivedFrom `json:"received_from"`
+	ScriptAsm    string        `json:"script_asm"`
+	ScriptHex    string        `json:"script_hex"`
+	Witness      []string      `json:"witness"`
+}
+
+// ReceivedFrom is the output spent by an input
+type ReceivedFrom struct {
+	Txid     string `json:"txid"`
+	OutputNo int    `json:"output_no"`
+}
+
+// Ref references the output in networkID, ok is false for coinbase inputs
+func (r *ReceivedFrom) Ref(networkID string) (ref OutputRef, ok bool) {
+	if r == nil {
+		return OutputRef{}, false
+	}
+
+	return OutputRef{Network: strings.ToLower(networkID), Txid: r.Txid, OutputNo: r.OutputNo}, true
 }
 
 type Outputs []Output
 type Output struct {
-	OutputNo  int         `json:"output_no"`
-	Address   string      `json:"address"`
-	Value     string      `json:"value"`
-	Type      string      `json:"type"`
-	ReqSigs   interface{} `json:"req_sigs"`
-	Spent     interface{} `json:"spent"`
-	ScriptAsm string      `json:"script_asm"`
-	ScriptHex string      `json:"script_hex"`
+	OutputNo int    `json:"output_no"`
+	Address  string `json:"address"`
+	Value    Amount `json:"value"`
+	Type     string `json:"type"`
+	// nil for scripts without a fixed number of signatures
+	ReqSigs *int `json:"req_sigs"`
+	// nil while unspent or if the provider doesn't know spending inputs
+	Spent     *SpentBy `json:"spent"`
+	ScriptAsm string   `json:"script_asm"`
+	ScriptHex string   `json:"script_hex"`
+}
+
+// SpentBy is the input spending an output
+type SpentBy struct {
+	Txid    string `json:"txid"`
+	InputNo int    `json:"input_no"`
+}
+
+// Ref references the input in networkID, ok is false for unspent outputs
+func (s *SpentBy) Ref(networkID string) (ref InputRef, ok bool) {
+	if s == nil {
+		return InputRef{}, false
+	}
+
+	return InputRef{Network: strings.ToLower(networkID), Txid: s.Txid, InputNo: s.InputNo}, true
+}
+
+// OutputRef references an output of a transaction
+type OutputRef struct {
+	Network  string
+	Txid     string
+	OutputNo int
+}
+
+// TxPath returns the API path of the transaction of r
+func (r OutputRef) TxPath() string {
+	return TxPath(r.Network, r.Txid)
+}
+
+// SpenderPath returns the API path of the transaction spending r
+func (r OutputRef) SpenderPath() string {
+	return fmt.Sprintf("%s/outputs/%d/spender", r.TxPath(), r.OutputNo)
+}
+
+// InputRef references an input of a transaction
+type InputRef struct {
+	Network string
+	Txid    string
+	InputNo int
+}
+
+// TxPath returns the API path of the transaction of r
+func (r InputRef) TxPath() string {
+	return TxPath(r.Network, r.Txid)
+}
+
+// TxPath returns the API path of transaction txid in networkID
+func TxPath(networkID, txid string) string {
+	return fmt.Sprintf("/network/%s/tx/%s", strings.ToLower(networkID), txid)
+}
+
+// Sochain returns address transaction lists in pages of up to 100 transactions
+const AddressTxPageSize = 100
+
+type AddressBalance struct {
+	Status string             `json:"status"`
+	Data   AddressBalanceData `json:"data"`
+}
+
+type AddressBalanceData struct {
+	Network            string `json:"network"`
+	Address            string `json:"address"`
+	ConfirmedBalance   Amount `json:"confirmed_balance"`
+	UnconfirmedBalance Amount `json:"unconfirmed_balance"`
+}
+
+type AddressBalanceResponse struct {
+	Address            string `json:"address"`
+	ConfirmedBalance   Amount `json:"confirmed_balance"`
+	UnconfirmedBalance Amount `json:"unconfirmed_balance"`
+}
+
+func (b AddressBalance) Response() AddressBalanceResponse {
+	return AddressBalanceResponse{
+		Address:            b.Data.Address,
+		ConfirmedBalance:   b.Data.ConfirmedBalance,
+		UnconfirmedBalance: b.Data.UnconfirmedBalance,
+	}
+}
+
+type ReceivedTxs struct {
+	Status string          `json:"status"`
+	Data   ReceivedTxsData `json:"data"`
+}
+
+type ReceivedTxsData struct {
+	Network string       `json:"network"`
+	Address string       `json:"address"`
+	Txs     []ReceivedTx `json:"txs"`
+}
+
+type ReceivedTx struct {
+	Txid          string `json:"txid"`
+	OutputNo      int    `json:"output_no"`
+	ScriptAsm     string `json:"script_asm"`
+	ScriptHex     string `json:"script_hex"`
+	Value         Amount `json:"value"`
+	Confirmations int    `json:"confirmations"`
+	Time          int    `json:"time"`
+}
+
+type SpentTxs struct {
+	Status string       `json:"status"`
+	Data   SpentTxsData `json:"data"`
+}
+
+type SpentTxsData struct {
+	Network string    `json:"network"`
+	Address string    `json:"address"`
+	Txs     []SpentTx `json:"txs"`
+}
+
+type SpentTx struct {
+	Txid          string `json:"txid"`
+	InputNo       int    `json:"input_no"`
+	Value         Amount `json:"value"`
+	Confirmations int    `json:"confirmations"`
+	Time          int    `json:"time"`
+}
+
+type UnspentOutputs struct {
+	Status string             `json:"status"`
+	Data   UnspentOutputsData `json:"data"`
+}
+
+type UnspentOutputsData struct {
+	Network string          `json:"network"`
+	Address string          `json:"address"`
+	Txs     []UnspentOutput `json:"txs"`
+}
+
+type UnspentOutput struct {
+	Txid          string `json:"txid"`
+	OutputNo      int    `json:"output_no"`
+	ScriptAsm     string `json:"script_asm"`
+	ScriptHex     string `json:"script_hex"`
+	Value         Amount `json:"value"`
+	Confirmations int    `json:"confirmations"`
+	Time          int    `json:"time"`
+}
+
+// AddressTxsResponse is a page of address transactions. After is the cursor of the next page, empty on the last page
+type AddressTxsResponse struct {
+	Address      string             `json:"address"`
+	Transactions AddressTxResponses `json:"transactions"`
+	After        string             `json:"after,omitempty"`
+}
+
+type AddressTxResponses []AddressTxResponse
+type AddressTxResponse struct {
+	TxID          string `json:"txid"`
+	OutputNo      *int   `json:"output_no,omitempty"`
+	InputNo       *int   `json:"input_no,omitempty"`
+	Value         Amount `json:"value"`
+	Confirmations int    `json:"confirmations"`
+	Timestamp     string `json:"time"`
+}
+
+func (t ReceivedTxs) Response() AddressTxsResponse {
+	r := make(AddressTxResponses, len(t.Data.Txs))
+	for i, tx := range t.Data.Txs {
+		outputNo := tx.OutputNo
+		r[i] = AddressTxResponse{
+			TxID:          tx.Txid,
+			OutputNo:      &outputNo,
+			Value:         tx.Value,
+			Confirmations: tx.Confirmations,
+			Timestamp:     time.Unix(int64(tx.Time), 0).Format(time.RFC3339),
+		}
+	}
+
+	return newAddressTxsResponse(t.Data.Address, r)
+}
+
+func (t SpentTxs) Response() AddressTxsResponse {
+	r := make(AddressTxResponses, len(t.Data.Txs))
+	for i, tx := range t.Data.Txs {
+		inputNo := tx.InputNo
+		r[i] = AddressTxResponse{
+			TxID:          tx.Txid,
+			InputNo:       &inputNo,
+			Value:         tx.Value,
+			Confirmations: tx.Confirmations,
+			Timestamp:     time.Unix(int64(tx.Time), 0).Format(time.RFC3339),
+		}
+	}
+
+	return newAddressTxsResponse(t.Data.Address, r)
+}
+
+func (o UnspentOutputs) Response() AddressTxsResponse {
+	r := make(AddressTxResponses, len(o.Data.Txs))
+	for i, tx := range o.Data.Txs {
+		outputNo := tx.OutputNo
+		r[i] = AddressTxResponse{
+			TxID:          tx.Txid,
+			OutputNo:      &outputNo,
+			Value:         tx.Value,
+			Confirmations: tx.Confirmations,
+			Timestamp:     time.Unix(int64(tx.Time), 0).Format(time.RFC3339),
+		}
+	}
+
+	return newAddressTxsResponse(o.Data.Address, r)
+}
+
+// newAddressTxsResponse sets the cursor of the next page if txs fills a whole page
+func newAddressTxsResponse(address string, txs AddressTxResponses) AddressTxsResponse {
+	r := AddressTxsResponse{
+		Address:      address,
+		Transactions: txs,
+	}
+	if len(txs) >= AddressTxPageSize {
+		r.After = txs[len(txs)-1].TxID
+	}
+
+	return r
 }
@@ -0,0 +1,92 @@
+package sochain
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NetworkInfo_Price(t *testing.T) {
+	var info NetworkInfo
+	err := json.Unmarshal([]byte(`{"status":"success","data":{"network":"BTC","blocks":800000,"price":"29174.123456789012","price_base":"USD"}}`), &info)
+	assert.Nil(t, err)
+	assert.Equal(t, "29174.123456789012", info.Data.Price.String())
+	assert.Equal(t, 0, big.NewRat(29174123456789012, 1e12).Cmp(info.Data.Price.Rat()))
+	assert.Equal(t, 800000, info.Data.Blocks)
+
+	b, err := json.Marshal(info.Data)
+	assert.Nil(t, err)
+	assert.Contains(t, string(b), `"price":"29174.123456789012"`)
+}
+
+func Test_ParseDecimal(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "0.00000000", want: "0.00000000"},
+		{value: "29174.12", want: "29174.12"},
+		{value: "-0.5", want: "-0.5"},
+		{value: "42", want: "42"},
+		{value: "1e999999999", wantErr: true},
+		{value: "1/3", wantErr: true},
+		{value: ".5", wantErr: true},
+		{value: "abc", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDecimal(tt.value)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+
+	var zero Decimal
+	assert.Equal(t, "0", zero.String())
+	assert.Equal(t, 0, zero.Rat().Sign())
+}
+
+func Test_Transaction_Provenance(t *testing.T) {
+	var d TransactionData
+	err := json.Unmarshal([]byte(`{
+		"inputs":[
+			{"input_no":0,"received_from":{"txid":"a","output_no":1},"script_hex":"00"},
+			{"input_no":1,"received_from":null,"script_hex":null}
+		],
+		"outputs":[
+			{"output_no":0,"req_sigs":1,"spent":{"txid":"c","input_no":2}},
+			{"output_no":1,"req_sigs":null,"spent":null}
+		]}`), &d)
+	assert.Nil(t, err)
+
+	ref, ok := d.Inputs[0].ReceivedFrom.Ref("BTC")
+	assert.True(t, ok)
+	assert.Equal(t, OutputRef{Network: "btc", Txid: "a", OutputNo: 1}, ref)
+	assert.Equal(t, "/network/btc/tx/a", ref.TxPath())
+	assert.Equal(t, "/network/btc/tx/a/outputs/1/spender", ref.SpenderPath())
+	assert.Equal(t, "00", d.Inputs[0].ScriptHex)
+
+	_, ok = d.Inputs[1].ReceivedFrom.Ref("btc")
+	assert.False(t, ok)
+	assert.Equal(t, "", d.Inputs[1].ScriptHex)
+
+	spender, ok := d.Outputs[0].Spent.Ref("btc")
+	assert.True(t, ok)
+	assert.Equal(t, InputRef{Network: "btc", Txid: "c", InputNo: 2}, spender)
+	assert.Equal(t, "/network/btc/tx/c", spender.TxPath())
+	if assert.NotNil(t, d.Outputs[0].ReqSigs) {
+		assert.Equal(t, 1, *d.Outputs[0].ReqSigs)
+	}
+
+	_, ok = d.Outputs[1].Spent.Ref("btc")
+	assert.False(t, ok)
+	assert.Nil(t, d.Outputs[1].ReqSigs)
+}
@@ -0,0 +1,263 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sochain-client/pkg/network"
+	"sochain-client/pkg/sochain"
+
+	"go.uber.org/zap"
+)
+
+// Option configures a ReadThrough connector created by NewReadThrough
+type Option func(*ReadThrough)
+
+// WithNetworks takes the finality thresholds from the Confirmations of r, defaults to network.DefaultRegistry
+func WithNetworks(r *network.Registry) Option {
+	return func(rt *ReadThrough) {
+		rt.networks = r
+	}
+}
+
+// WithOffline serves from the store only. Blocks & transactions missing in the store are not found, all other
+// requests are unsupported
+func WithOffline() Option {
+	return func(rt *ReadThrough) {
+		rt.offline = true
+	}
+}
+
+// WithLogger logs failed writes to the store to l
+func WithLogger(l *zap.Logger) Option {
+	return func(rt *ReadThrough) {
+		rt.logger = l
+	}
+}
+
+var _ sochain.Connector = (*ReadThrough)(nil)
+
+// ReadThrough serves blocks & transactions from the store & stores those fetched from next once they are final.
+// Stored entries keep the confirmations they were stored with
+type ReadThrough struct {
+	store    *Store
+	next     sochain.Connector
+	networks *network.Registry
+	offline  bool
+	logger   *zap.Logger
+}
+
+// NewReadThrough puts s in front of next, next is unused in offline mode
+func NewReadThrough(s *Store, next sochain.Connector, opts ...Option) *ReadThrough {
+	rt := &ReadThrough{
+		store:    s,
+		next:     next,
+		networks: network.DefaultRegistry(),
+		logger:   zap.NewNop(),
+	}
+
+	for _, opt := range opts {
+		opt(rt)
+	}
+
+	return rt
+}
+
+func (rt *ReadThrough) NetworkInfo(ctx context.Context, networkID string) (*sochain.NetworkInfo, error) {
+	if rt.offline {
+		return nil, unsupportedErr("network info")
+	}
+
+	return rt.next.NetworkInfo(ctx, networkID)
+}
+
+func (rt *ReadThrough) BlockHeight(ctx context.Context, networkID string, height int) (*sochain.Block, error) {
+
+	b, ok, err := rt.store.BlockHeight(networkID, height)
+	if err != nil {
+		rt.logger.Warn("unable to read block from store", zap.String("network", networkID), zap.Int("height", height), zap.Error(err))
+	} else if ok {
+		return &sochain.Block{Status: "success", Data: b}, nil
+	}
+
+	if rt.offline {
+		return nil, notFoundErr(fmt.Sprintf("height '%d'", height))
+	}
+
+	block, err := rt.next.BlockHeight(ctx, networkID, height)
+	if err != nil {
+		return nil, err
+	}
+
+	rt.putBlock(networkID, block)
+	return block, nil
+}
+
+func (rt *ReadThrough) BlockHash(ctx context.Context, networkID, blockHash string) (*sochain.Block, error) {
+
+	b, ok, err := rt.store.BlockHash(networkID, blockHash)
+	if err != nil {
+		rt.logger.Warn("unable to read block from store", zap.String("network", networkID), zap.String("blockhash", blockHash), zap.Error(err))
+	} else if ok {
+		return &sochain.Block{Status: "success", Data: b}, nil
+	}
+
+	if rt.offline {
+		return nil, notFoundErr(fmt.Sprintf("blockhash '%s'", blockHash))
+	}
+
+	block, err := rt.next.BlockHash(ctx, networkID, blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	rt.putBlock(networkID, block)
+	return block, nil
+}
+
+func (rt *ReadThrough) Transaction(ctx context.Context, networkID, txHash string) (*sochain.Transaction, error) {
+
+	t, ok, err := rt.store.Transaction(networkID, txHash)
+	if err != nil {
+		rt.logger.Warn("unable to read transaction from store", zap.String("network", networkID), zap.String("txhash", txHash), zap.Error(err))
+	} else if ok {
+		return &sochain.Transaction{Status: "success", Data: t}, nil
+	}
+
+	if rt.offline {
+		return nil, notFoundErr(fmt.Sprintf("txhash '%s'", txHash))
+	}
+
+	tx, err := rt.next.Transaction(ctx, networkID, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	rt.putTransaction(networkID, tx)
+	return tx, nil
+}
+
+// Transactions serves the stored transactions of hashes & fetches the others from next in one batch
+func (rt *ReadThrough) Transactions(ctx context.Context, networkID string, hashes []string) ([]sochain.TxResult, error) {
+
+	results := make([]sochain.TxResult, len(hashes))
+	var missing []string
+	var missingIdx []int
+	for i, hash := range hashes {
+		results[i].Hash = hash
+
+		t, ok, err := rt.store.Transaction(networkID, hash)
+		if err != nil {
+			rt.logger.Warn("unable to read transaction from store", zap.String("network", networkID), zap.String("txhash", hash), zap.Error(err))
+		} else if ok {
+			results[i].Tx = &sochain.Transaction{Status: "success", Data: t}
+			continue
+		}
+
+		if rt.offline {
+			results[i].Err = notFoundErr(fmt.Sprintf("txhash '%s'", hash))
+			continue
+		}
+		missing = append(missing, hash)
+		missingIdx = append(missingIdx, i)
+	}
+	if len(missing) == 0 {
+		return results, nil
+	}
+
+	fetched, err := rt.next.Transactions(ctx, networkID, missing)
+	if err != nil {
+		return nil, err
+	}
+	for j, r := range fetched {
+		if r.Err == nil {
+			rt.putTransaction(networkID, r.Tx)
+		}
+		results[missingIdx[j]] = r
+	}
+
+	return results, nil
+}
+
+func (rt *ReadThrough) AddressBalance(ctx context.Context, networkID, address string) (*sochain.AddressBalance, error) {
+	if rt.offline {
+		return nil, unsupportedErr("address lookups")
+	}
+
+	return rt.next.AddressBalance(ctx, networkID, address)
+}
+
+func (rt *ReadThrough) ReceivedTransactions(ctx context.Context, networkID, address, afterTxid string) (*sochain.ReceivedTxs, error) {
+	if rt.offline {
+		return nil, unsupportedErr("address lookups")
+	}
+
+	return rt.next.ReceivedTransactions(ctx, networkID, address, afterTxid)
+}
+
+// OutputSpender is never stored, outputs of final transactions are spent later
+func (rt *ReadThrough) OutputSpender(ctx context.Context, networkID, txHash string, outputNo int) (*sochain.SpentBy, error) {
+	if rt.offline {
+		return nil, unsupportedErr("spending inputs")
+	}
+
+	return rt.next.OutputSpender(ctx, networkID, txHash, outputNo)
+}
+
+func (rt *ReadThrough) SpentTransactions(ctx context.Context, networkID, address, afterTxid string) (*sochain.SpentTxs, error) {
+	if rt.offline {
+		return nil, unsupportedErr("address lookups")
+	}
+
+	return rt.next.SpentTransactions(ctx, networkID, address, afterTxid)
+}
+
+func (rt *ReadThrough) UnspentOutputs(ctx context.Context, networkID, address, afterTxid string) (*sochain.UnspentOutputs, error) {
+	if rt.offline {
+		return nil, unsupportedErr("address lookups")
+	}
+
+	return rt.next.UnspentOutputs(ctx, networkID, address, afterTxid)
+}
+
+func (rt *ReadThrough) BroadcastTransaction(ctx context.Context, networkID, txHex string) (*sochain.BroadcastTx, error) {
+	if rt.offline {
+		return nil, unsupportedErr("broadcasts")
+	}
+
+	return rt.next.BroadcastTransaction(ctx, networkID, txHex)
+}
+
+func (rt *ReadThrough) putBlock(networkID string, b *sochain.Block) {
+	if b.Data.IsOrphan || !rt.final(networkID, b.Data.Confirmations) {
+		return
+	}
+
+	if err := rt.store.PutBlock(networkID, b.Data); err != nil {
+		rt.logger.Warn("unable to store block", zap.String("network", networkID), zap.String("blockhash", b.Data.Blockhash), zap.Error(err))
+	}
+}
+
+func (rt *ReadThrough) putTransaction(networkID string, tx *sochain.Transaction) {
+	if !rt.final(networkID, tx.Data.Confirmations) {
+		return
+	}
+
+	if err := rt.store.PutTransaction(networkID, tx.Data); err != nil {
+		rt.logger.Warn("unable to store transaction", zap.String("network", networkID), zap.String("txhash", tx.Data.Txid), zap.Error(err))
+	}
+}
+
+// final reports whether confirmations reach the finality threshold of networkID
+func (rt *ReadThrough) final(networkID string, confirmations int) bool {
+	n, err := rt.networks.Lookup(networkID)
+	return err == nil && n.Confirmations > 0 && confirmations >= n.Confirmations
+}
+
+func notFoundErr(subject string) error {
+	return sochain.NewClientErr(fmt.Errorf("%s not in store", subject), http.StatusNotFound)
+}
+
+func unsupportedErr(subject string) error {
+	return sochain.NewClientErr(fmt.Errorf("%s are not supported offline", subject), http.StatusNotImplemented)
+}
@@ -0,0 +1,313 @@
+// Package store persists final blocks & transactions in an embedded bbolt database. ReadThrough serves them in front
+// of a sochain.Connector, so a restart doesn't have to re-fetch them upstream
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sochain-client/pkg/sochain"
+	"strconv"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// SchemaVersion is the version of the layout written by this package
+const SchemaVersion = 1
+
+// ErrSchemaVersion is returned by Open if the store was written by a newer version of this package
+var ErrSchemaVersion = errors.New("unsupported schema version")
+
+// ErrLocked is returned by OpenReadOnly while a writer, e.g. the server, holds the store open
+var ErrLocked = errors.New("store locked by another process")
+
+var (
+	metaBucket    = []byte("meta")
+	blocksBucket  = []byte("blocks")
+	heightsBucket = []byte("heights")
+	txsBucket     = []byte("txs")
+
+	schemaVersionKey = []byte("schema_version")
+)
+
+// migrations[i] upgrades a store of schema version i+1 to i+2
+var migrations []func(tx *bolt.Tx) error
+
+// Store keeps BlockData keyed by network/hash & network/height and TransactionData keyed by network/txid
+type Store struct {
+	db   *bolt.DB
+	path string
+}
+
+// Open opens or creates the store at path & upgrades its schema
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Update(initSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db, path: path}, nil
+}
+
+// OpenReadOnly opens the existing store at path for reading, it is never created, upgraded or written. Any number of
+// readers can share a store, writers lock it exclusively
+func OpenReadOnly(path string) (*Store, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second, ReadOnly: true})
+	if errors.Is(err, bolt.ErrTimeout) {
+		return nil, fmt.Errorf("%w: %s", ErrLocked, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.View(checkSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db, path: path}, nil
+}
+
+// checkSchema verifies a store can be read without initSchema
+func checkSchema(tx *bolt.Tx) error {
+	for _, name := range [][]byte{metaBucket, blocksBucket, heightsBucket, txsBucket} {
+		if tx.Bucket(name) == nil {
+			return fmt.Errorf("not a store, bucket '%s' missing", name)
+		}
+	}
+
+	v := tx.Bucket(metaBucket).Get(schemaVersionKey)
+	version, err := strconv.Atoi(string(v))
+	if err != nil {
+		return fmt.Errorf("invalid schema version '%s'", v)
+	}
+	if version != SchemaVersion {
+		return fmt.Errorf("%w %d, expected %d", ErrSchemaVersion, version, SchemaVersion)
+	}
+
+	return nil
+}
+
+func initSchema(tx *bolt.Tx) error {
+	for _, name := range [][]byte{metaBucket, blocksBucket, heightsBucket, txsBucket} {
+		if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+			return err
+		}
+	}
+
+	meta := tx.Bucket(metaBucket)
+	version := SchemaVersion
+	if v := meta.Get(schemaVersionKey); v != nil {
+		var err error
+		if version, err = strconv.Atoi(string(v)); err != nil {
+			return fmt.Errorf("invalid schema version '%s'", v)
+		}
+	}
+
+	if version > SchemaVersion {
+		return fmt.Errorf("%w %d, expected up to %d", ErrSchemaVersion, version, SchemaVersion)
+	}
+	for ; version < SchemaVersion; version++ {
+		if err := migrations[version-1](tx); err != nil {
+			return fmt.Errorf("unable to migrate schema version %d: %w", version, err)
+		}
+	}
+
+	return meta.Put(schemaVersionKey, []byte(strconv.Itoa(SchemaVersion)))
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func blockKey(networkID, hash string) []byte {
+	return []byte(strings.ToLower(networkID) + "/" + strings.ToLower(hash))
+}
+
+// heightKey pads height to keep heights of a network in order
+func heightKey(networkID string, height int) []byte {
+	return []byte(fmt.Sprintf("%s/%010d", strings.ToLower(networkID), height))
+}
+
+func txKey(networkID, txid string) []byte {
+	return []byte(strings.ToLower(networkID) + "/" + strings.ToLower(txid))
+}
+
+// BlockHash returns the stored block of networkID, ok is false if it isn't stored
+func (s *Store) BlockHash(networkID, hash string) (b sochain.BlockData, ok bool, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		ok, err = get(tx.Bucket(blocksBucket), blockKey(networkID, hash), &b)
+		return err
+	})
+
+	return b, ok, err
+}
+
+// BlockHeight returns the stored block of networkID at height, ok is false if it isn't stored
+func (s *Store) BlockHeight(networkID string, height int) (b sochain.BlockData, ok bool, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		hash := tx.Bucket(heightsBucket).Get(heightKey(networkID, height))
+		if hash == nil {
+			return nil
+		}
+
+		ok, err = get(tx.Bucket(blocksBucket), blockKey(networkID, string(hash)), &b)
+		return err
+	})
+
+	return b, ok, err
+}
+
+// Transaction returns the stored transaction of networkID, ok is false if it isn't stored
+func (s *Store) Transaction(networkID, txid string) (t sochain.TransactionData, ok bool, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		ok, err = get(tx.Bucket(txsBucket), txKey(networkID, txid), &t)
+		return err
+	})
+
+	return t, ok, err
+}
+
+// PutBlock stores b by hash & height, replacing a stored block of the same height
+func (s *Store) PutBlock(networkID string, b sochain.BlockData) error {
+	v, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(blocksBucket).Put(blockKey(networkID, b.Blockhash), v); err != nil {
+			return err
+		}
+
+		return tx.Bucket(heightsBucket).Put(heightKey(networkID, b.BlockNo), []byte(strings.ToLower(b.Blockhash)))
+	})
+}
+
+func (s *Store) PutTransaction(networkID string, t sochain.TransactionData) error {
+	v, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(txsBucket).Put(txKey(networkID, t.Txid), v)
+	})
+}
+
+func get(b *bolt.Bucket, key []byte, v interface{}) (bool, error) {
+	data := b.Get(key)
+	if data == nil {
+		return false, nil
+	}
+
+	return true, json.Unmarshal(data, v)
+}
+
+// Stats describes the size & contents of a store
+type Stats struct {
+	SchemaVersion int
+	// size of the database file in bytes
+	Size int64
+	// entries per network
+	Blocks       map[string]int
+	Transactions map[string]int
+}
+
+func (s *Store) Stats() (Stats, error) {
+	stats := Stats{
+		Blocks:       map[string]int{},
+		Transactions: map[string]int{},
+	}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		if stats.SchemaVersion, err = strconv.Atoi(string(tx.Bucket(metaBucket).Get(schemaVersionKey))); err != nil {
+			return err
+		}
+		stats.Size = tx.Size()
+
+		count := func(name []byte, counts map[string]int) error {
+			return tx.Bucket(name).ForEach(func(k, _ []byte) error {
+				counts[strings.SplitN(string(k), "/", 2)[0]]++
+				return nil
+			})
+		}
+		if err := count(blocksBucket, stats.Blocks); err != nil {
+			return err
+		}
+		return count(txsBucket, stats.Transactions)
+	})
+
+	return stats, err
+}
+
+// Compact rewrites the store at path without the free pages left by updates & returns its size before and after.
+// The store must not be open
+func Compact(path string) (before, after int64, err error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	before = fi.Size()
+
+	src, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second, ReadOnly: true})
+	if err != nil {
+		return 0, 0, err
+	}
+	defer src.Close()
+
+	// a temp file of its own, never the leftover of a crashed run
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".compact-*")
+	if err != nil {
+		return 0, 0, err
+	}
+	tmp := f.Name()
+	f.Close()
+	if err := os.Chmod(tmp, fi.Mode().Perm()); err != nil {
+		os.Remove(tmp)
+		return 0, 0, err
+	}
+
+	dst, err := bolt.Open(tmp, fi.Mode(), &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		os.Remove(tmp)
+		return 0, 0, err
+	}
+
+	if err := bolt.Compact(dst, src, 64<<20); err != nil {
+		dst.Close()
+		os.Remove(tmp)
+		return 0, 0, err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmp)
+		return 0, 0, err
+	}
+	src.Close()
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return 0, 0, err
+	}
+
+	fi, err = os.Stat(path)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return before, fi.Size(), nil
+}
@@ -0,0 +1,236 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sochain-client/pkg/sochain"
+	mock_client "sochain-client/pkg/sochain/mock"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	blockHash = "000000000003ba27aa200b1cecaad478d2b00432346c3f1f3986da1afd33e506"
+	txHash    = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+)
+
+func openTestStore(t *testing.T) (*Store, string) {
+	path := filepath.Join(t.TempDir(), "store.db")
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	return s, path
+}
+
+func testBlock(confirmations int) *sochain.Block {
+	return &sochain.Block{Status: "success", Data: sochain.BlockData{Blockhash: blockHash, BlockNo: 100000, Confirmations: confirmations, Txs: []string{txHash}}}
+}
+
+func testTx(confirmations int) *sochain.Transaction {
+	return &sochain.Transaction{Status: "success", Data: sochain.TransactionData{Txid: txHash, Blockhash: blockHash, BlockNo: 100000, Confirmations: confirmations}}
+}
+
+func Test_Store(t *testing.T) {
+	s, _ := openTestStore(t)
+
+	_, ok, err := s.BlockHeight("btc", 100000)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	assert.Nil(t, s.PutBlock("btc", testBlock(6).Data))
+	assert.Nil(t, s.PutTransaction("btc", testTx(6).Data))
+
+	b, ok, err := s.BlockHeight("BTC", 100000)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, testBlock(6).Data, b)
+
+	b, ok, err = s.BlockHash("btc", blockHash)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, testBlock(6).Data, b)
+
+	tx, ok, err := s.Transaction("btc", txHash)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, testTx(6).Data, tx)
+
+	_, ok, _ = s.Transaction("ltc", txHash)
+	assert.False(t, ok)
+
+	stats, err := s.Stats()
+	assert.Nil(t, err)
+	assert.Equal(t, SchemaVersion, stats.SchemaVersion)
+	assert.Equal(t, map[string]int{"btc": 1}, stats.Blocks)
+	assert.Equal(t, map[string]int{"btc": 1}, stats.Transactions)
+}
+
+func Test_Open_SchemaVersion(t *testing.T) {
+	s, path := openTestStore(t)
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(metaBucket).Put(schemaVersionKey, []byte("2"))
+	})
+	assert.Nil(t, err)
+	s.Close()
+
+	_, err = Open(path)
+	assert.True(t, errors.Is(err, ErrSchemaVersion))
+}
+
+func Test_OpenReadOnly(t *testing.T) {
+	s, path := openTestStore(t)
+	assert.Nil(t, s.PutBlock("btc", testBlock(6).Data))
+
+	_, err := OpenReadOnly(path)
+	assert.ErrorIs(t, err, ErrLocked)
+	s.Close()
+
+	r, err := OpenReadOnly(path)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer r.Close()
+
+	stats, err := r.Stats()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"btc": 1}, stats.Blocks)
+	assert.NotNil(t, r.PutBlock("btc", testBlock(6).Data))
+
+	// readers share the store
+	r2, err := OpenReadOnly(path)
+	if assert.Nil(t, err) {
+		r2.Close()
+	}
+
+	// missing stores aren't created
+	missing := filepath.Join(t.TempDir(), "missing.db")
+	_, err = OpenReadOnly(missing)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(missing)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func Test_Compact(t *testing.T) {
+	s, path := openTestStore(t)
+	for i := 0; i < 100; i++ {
+		b := testBlock(6).Data
+		b.BlockNo = i
+		b.Blockhash = blockHash[:60] + string(rune('a'+i%26)) + string(rune('a'+i/26)) + "00"
+		assert.Nil(t, s.PutBlock("btc", b))
+	}
+	s.Close()
+
+	// leftover of a crashed run
+	assert.Nil(t, os.WriteFile(path+".compact", []byte("garbage"), 0600))
+
+	before, after, err := Compact(path)
+	assert.Nil(t, err)
+	assert.True(t, after <= before)
+	tmps, _ := filepath.Glob(path + ".compact-*")
+	assert.Empty(t, tmps)
+
+	s, err = Open(path)
+	assert.Nil(t, err)
+	defer s.Close()
+
+	stats, err := s.Stats()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"btc": 100}, stats.Blocks)
+}
+
+func Test_ReadThrough_Final(t *testing.T) {
+	s, _ := openTestStore(t)
+	m := mock_client.NewMockConnector(gomock.NewController(t))
+	rt := NewReadThrough(s, m)
+
+	m.EXPECT().BlockHeight(gomock.Any(), "btc", 100000).Return(testBlock(6), nil).Times(1)
+	m.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(testTx(6), nil).Times(1)
+
+	for i := 0; i < 2; i++ {
+		b, err := rt.BlockHeight(context.Background(), "btc", 100000)
+		assert.Nil(t, err)
+		assert.Equal(t, testBlock(6), b)
+
+		tx, err := rt.Transaction(context.Background(), "btc", txHash)
+		assert.Nil(t, err)
+		assert.Equal(t, testTx(6), tx)
+	}
+
+	b, err := rt.BlockHash(context.Background(), "btc", blockHash)
+	assert.Nil(t, err)
+	assert.Equal(t, testBlock(6), b)
+}
+
+func Test_ReadThrough_Transactions(t *testing.T) {
+	s, _ := openTestStore(t)
+	m := mock_client.NewMockConnector(gomock.NewController(t))
+	rt := NewReadThrough(s, m)
+
+	other := "0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098"
+	assert.Nil(t, s.PutTransaction("btc", testTx(6).Data))
+
+	m.EXPECT().Transactions(gomock.Any(), "btc", []string{other}).Return([]sochain.TxResult{{Hash: other, Err: errors.New("some")}}, nil)
+
+	got, err := rt.Transactions(context.Background(), "btc", []string{txHash, other})
+	assert.Nil(t, err)
+	assert.Equal(t, []sochain.TxResult{{Hash: txHash, Tx: testTx(6)}, {Hash: other, Err: errors.New("some")}}, got)
+
+	offline := NewReadThrough(s, nil, WithOffline())
+	got, err = offline.Transactions(context.Background(), "btc", []string{txHash, other})
+	assert.Nil(t, err)
+	assert.Equal(t, testTx(6), got[0].Tx)
+	assert.True(t, errors.Is(got[1].Err, sochain.ErrNotFound))
+}
+
+func Test_ReadThrough_NotFinal(t *testing.T) {
+	s, _ := openTestStore(t)
+	m := mock_client.NewMockConnector(gomock.NewController(t))
+	rt := NewReadThrough(s, m)
+
+	orphan := testBlock(10)
+	orphan.Data.IsOrphan = true
+	m.EXPECT().BlockHash(gomock.Any(), "btc", blockHash).Return(testBlock(5), nil)
+	m.EXPECT().BlockHash(gomock.Any(), "btc", blockHash).Return(orphan, nil)
+	m.EXPECT().Transaction(gomock.Any(), "btc", txHash).Return(testTx(0), nil).Times(2)
+
+	rt.BlockHash(context.Background(), "btc", blockHash)
+	rt.BlockHash(context.Background(), "btc", blockHash)
+	rt.Transaction(context.Background(), "btc", txHash)
+	rt.Transaction(context.Background(), "btc", txHash)
+
+	stats, err := s.Stats()
+	assert.Nil(t, err)
+	assert.Empty(t, stats.Blocks)
+	assert.Empty(t, stats.Transactions)
+}
+
+func Test_ReadThrough_Offline(t *testing.T) {
+	s, _ := openTestStore(t)
+	assert.Nil(t, s.PutBlock("btc", testBlock(6).Data))
+	rt := NewReadThrough(s, nil, WithOffline())
+
+	b, err := rt.BlockHash(context.Background(), "btc", blockHash)
+	assert.Nil(t, err)
+	assert.Equal(t, testBlock(6), b)
+
+	var cErr *sochain.ClientError
+	_, err = rt.Transaction(context.Background(), "btc", txHash)
+	assert.True(t, errors.As(err, &cErr))
+	assert.Equal(t, http.StatusNotFound, cErr.Code())
+
+	_, err = rt.NetworkInfo(context.Background(), "btc")
+	assert.True(t, errors.Is(err, sochain.ErrUnsupported))
+
+	_, err = rt.BroadcastTransaction(context.Background(), "btc", "00")
+	assert.True(t, errors.Is(err, sochain.ErrUnsupported))
+}
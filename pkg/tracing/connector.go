@@ -0,0 +1,133 @@
+package tracing
+
+import (
+	"context"
+	"sochain-client/pkg/sochain"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ sochain.Connector = (*Connector)(nil)
+
+// Connector spans every call of a provider
+type Connector struct {
+	next     sochain.Connector
+	provider string
+	t        *Tracing
+}
+
+// Connector traces the calls of next, labelled by provider. Batches are fetched hash by hash through Transaction so
+// that every transaction gets a span of its own below the span of the batch
+func (t *Tracing) Connector(provider string, next sochain.Connector) *Connector {
+	return &Connector{
+		next:     next,
+		provider: provider,
+		t:        t,
+	}
+}
+
+func (c *Connector) NetworkInfo(ctx context.Context, networkID string) (*sochain.NetworkInfo, error) {
+	ctx, span := c.start(ctx, "NetworkInfo", networkID)
+	info, err := c.next.NetworkInfo(ctx, networkID)
+	end(span, err)
+	return info, err
+}
+
+func (c *Connector) BlockHeight(ctx context.Context, networkID string, height int) (*sochain.Block, error) {
+	ctx, span := c.start(ctx, "BlockHeight", networkID, attribute.Int(attrHeight, height))
+	b, err := c.next.BlockHeight(ctx, networkID, height)
+	end(span, err)
+	return b, err
+}
+
+func (c *Connector) BlockHash(ctx context.Context, networkID, blockHash string) (*sochain.Block, error) {
+	ctx, span := c.start(ctx, "BlockHash", networkID, attribute.String(attrHash, blockHash))
+	b, err := c.next.BlockHash(ctx, networkID, blockHash)
+	end(span, err)
+	return b, err
+}
+
+func (c *Connector) Transaction(ctx context.Context, networkID, txHash string) (*sochain.Transaction, error) {
+	ctx, span := c.start(ctx, "Transaction", networkID, attribute.String(attrHash, txHash))
+	tx, err := c.next.Transaction(ctx, networkID, txHash)
+	end(span, err)
+	return tx, err
+}
+
+func (c *Connector) Transactions(ctx context.Context, networkID string, hashes []string) ([]sochain.TxResult, error) {
+	ctx, span := c.start(ctx, "Transactions", networkID, attribute.Int(attrTxCount, len(hashes)))
+	results := sochain.FetchTransactions(ctx, hashes, c.t.concurrency, func(ctx context.Context, hash string) (*sochain.Transaction, error) {
+		return c.Transaction(ctx, networkID, hash)
+	})
+	end(span, nil)
+	return results, nil
+}
+
+func (c *Connector) AddressBalance(ctx context.Context, networkID, address string) (*sochain.AddressBalance, error) {
+	ctx, span := c.start(ctx, "AddressBalance", networkID, attribute.String(attrAddress, address))
+	b, err := c.next.AddressBalance(ctx, networkID, address)
+	end(span, err)
+	return b, err
+}
+
+func (c *Connector) ReceivedTransactions(ctx context.Context, networkID, address, afterTxid string) (*sochain.ReceivedTxs, error) {
+	ctx, span := c.start(ctx, "ReceivedTransactions", networkID, attribute.String(attrAddress, address), attribute.String(attrHash, afterTxid))
+	txs, err := c.next.ReceivedTransactions(ctx, networkID, address, afterTxid)
+	end(span, err)
+	return txs, err
+}
+
+func (c *Connector) OutputSpender(ctx context.Context, networkID, txHash string, outputNo int) (*sochain.SpentBy, error) {
+	ctx, span := c.start(ctx, "OutputSpender", networkID, attribute.String(attrHash, txHash), attribute.Int(attrOutputNo, outputNo))
+	spent, err := c.next.OutputSpender(ctx, networkID, txHash, outputNo)
+	end(span, err)
+	return spent, err
+}
+
+func (c *Connector) SpentTransactions(ctx context.Context, networkID, address, afterTxid string) (*sochain.SpentTxs, error) {
+	ctx, span := c.start(ctx, "SpentTransactions", networkID, attribute.String(attrAddress, address), attribute.String(attrHash, afterTxid))
+	txs, err := c.next.SpentTransactions(ctx, networkID, address, afterTxid)
+	end(span, err)
+	return txs, err
+}
+
+func (c *Connector) UnspentOutputs(ctx context.Context, networkID, address, afterTxid string) (*sochain.UnspentOutputs, error) {
+	ctx, span := c.start(ctx, "UnspentOutputs", networkID, attribute.String(attrAddress, address), attribute.String(attrHash, afterTxid))
+	outputs, err := c.next.UnspentOutputs(ctx, networkID, address, afterTxid)
+	end(span, err)
+	return outputs, err
+}
+
+func (c *Connector) BroadcastTransaction(ctx context.Context, networkID, txHex string) (*sochain.BroadcastTx, error) {
+	ctx, span := c.start(ctx, "BroadcastTransaction", networkID)
+	tx, err := c.next.BroadcastTransaction(ctx, networkID, txHex)
+	if err == nil {
+		span.SetAttributes(attribute.String(attrHash, tx.Data.Txid))
+	}
+	end(span, err)
+	return tx, err
+}
+
+// start opens the client span of method, a child of the span in ctx
+func (c *Connector) start(ctx context.Context, method, networkID string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs,
+		attribute.String(attrProvider, c.provider),
+		attribute.String(attrMethod, method),
+		attribute.String(attrNetwork, strings.ToLower(networkID)),
+	)
+
+	return c.t.tracer.Start(ctx, "sochain."+method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// end closes span with the outcome of its call
+func end(span trace.Span, err error) {
+	span.SetAttributes(attribute.Int(attrStatusCode, sochain.StatusCode(err)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
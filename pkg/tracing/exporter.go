@@ -0,0 +1,60 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Exporters selectable by NewExporter
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+)
+
+// NewExporter creates the span exporter called name, stdout writes every span as a JSON line to w. None returns a
+// nil exporter, tracing is disabled then. Tests keep spans in memory by tracetest.NewInMemoryExporter
+func NewExporter(name string, w io.Writer) (sdktrace.SpanExporter, error) {
+	switch name {
+	case ExporterNone, "":
+		return nil, nil
+	case ExporterStdout:
+		return NewWriterExporter(w), nil
+	}
+
+	return nil, fmt.Errorf("unknown trace exporter '%s'", name)
+}
+
+var _ sdktrace.SpanExporter = (*WriterExporter)(nil)
+
+// WriterExporter writes every span as a JSON line
+type WriterExporter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func NewWriterExporter(w io.Writer) *WriterExporter {
+	return &WriterExporter{enc: json.NewEncoder(w)}
+}
+
+func (e *WriterExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, s := range tracetest.SpanStubsFromReadOnlySpans(spans) {
+		if err := e.enc.Encode(s); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *WriterExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
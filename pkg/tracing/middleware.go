@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware starts a server span per request named by its route, requests matching no route are named by their path.
+// Handlers reach the span by the context of their request
+func (t *Tracing) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		req := ctx.Request
+		parent := t.propagator.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+		route := ctx.FullPath()
+		name := route
+		if name == "" {
+			name = req.URL.Path
+		}
+
+		spanCtx, span := t.tracer.Start(parent, fmt.Sprintf("%s %s", req.Method, name),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest("", route, req)...),
+		)
+		defer span.End()
+
+		ctx.Request = req.WithContext(spanCtx)
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(status)...)
+		span.SetStatus(semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(status, trace.SpanKindServer))
+		for _, err := range ctx.Errors {
+			span.RecordError(err.Err)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package tracing
+
+import "go.opentelemetry.io/otel/propagation"
+
+// Option configures a Tracing created by New
+type Option func(*Tracing)
+
+// WithPropagator extracts the trace context of incoming requests by p, default W3C trace context
+func WithPropagator(p propagation.TextMapPropagator) Option {
+	return func(t *Tracing) {
+		t.propagator = p
+	}
+}
+
+// WithConcurrency limits the parallel lookups of a traced Transactions batch to n, default sochain.DefaultConcurrency
+func WithConcurrency(n int) Option {
+	return func(t *Tracing) {
+		t.concurrency = n
+	}
+}
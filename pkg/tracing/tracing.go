@@ -0,0 +1,47 @@
+// Package tracing traces requests with OpenTelemetry: a server span per HTTP request continuing the W3C trace context
+// of its caller and a child span per Connector call of every provider, including each transaction of a batch
+package tracing
+
+import (
+	"sochain-client/pkg/sochain"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "sochain-client"
+
+// Span attributes of Connector calls
+const (
+	attrProvider   = "sochain.provider"
+	attrMethod     = "sochain.method"
+	attrNetwork    = "sochain.network"
+	attrHash       = "sochain.hash"
+	attrOutputNo   = "sochain.output_no"
+	attrHeight     = "sochain.height"
+	attrAddress    = "sochain.address"
+	attrTxCount    = "sochain.tx_count"
+	attrStatusCode = "sochain.status_code"
+)
+
+// Tracing creates the spans of the service
+type Tracing struct {
+	tracer      trace.Tracer
+	propagator  propagation.TextMapPropagator
+	concurrency int
+}
+
+// New traces by tp, incoming requests are continued by their traceparent & tracestate headers
+func New(tp trace.TracerProvider, opts ...Option) *Tracing {
+	t := &Tracing{
+		tracer:      tp.Tracer(instrumentationName),
+		propagator:  propagation.TraceContext{},
+		concurrency: sochain.DefaultConcurrency,
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
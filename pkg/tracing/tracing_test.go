@@ -0,0 +1,165 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sochain-client/pkg/sochain"
+	mock_client "sochain-client/pkg/sochain/mock"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const txHash = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+func newTestTracing() (*Tracing, *tracetest.InMemoryExporter) {
+	exp := tracetest.NewInMemoryExporter()
+	return New(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))), exp
+}
+
+// attr returns the value of key in span, invalid if span lacks key
+func attr(span tracetest.SpanStub, key string) attribute.Value {
+	for _, kv := range span.Attributes {
+		if string(kv.Key) == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func Test_Middleware(t *testing.T) {
+	tr, exp := newTestTracing()
+	m := mock_client.NewMockConnector(gomock.NewController(t))
+	m.EXPECT().BlockHeight(gomock.Any(), "btc", 1).Return(&sochain.Block{}, nil)
+	client := tr.Connector("esplora", m)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(tr.Middleware())
+	r.GET("/network/:id", func(ctx *gin.Context) {
+		_, err := client.BlockHeight(ctx.Request.Context(), ctx.Param("id"), 1)
+		assert.Nil(t, err)
+		ctx.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/network/btc", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exp.GetSpans()
+	assert.Len(t, spans, 2)
+	upstream, server := spans[0], spans[1]
+
+	// the trace of the caller is continued
+	assert.Equal(t, "GET /network/:id", server.Name)
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+	assert.Equal(t, int64(200), attr(server, "http.status_code").AsInt64())
+	assert.Equal(t, codes.Unset, server.Status.Code)
+
+	assert.Equal(t, "sochain.BlockHeight", upstream.Name)
+	assert.Equal(t, server.SpanContext.SpanID(), upstream.Parent.SpanID())
+	assert.Equal(t, "esplora", attr(upstream, "sochain.provider").AsString())
+	assert.Equal(t, "btc", attr(upstream, "sochain.network").AsString())
+	assert.Equal(t, int64(1), attr(upstream, "sochain.height").AsInt64())
+	assert.Equal(t, int64(200), attr(upstream, "sochain.status_code").AsInt64())
+}
+
+func Test_Middleware_ServerError(t *testing.T) {
+	tr, exp := newTestTracing()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(tr.Middleware())
+	r.GET("/network/:id", func(ctx *gin.Context) {
+		ctx.Status(http.StatusBadGateway)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/network/btc", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/nope", nil))
+
+	spans := exp.GetSpans()
+	assert.Len(t, spans, 2)
+	assert.False(t, spans[0].Parent.IsValid())
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	// a client error doesn't fail the server span
+	assert.Equal(t, "GET /nope", spans[1].Name)
+	assert.Equal(t, codes.Unset, spans[1].Status.Code)
+}
+
+func Test_Connector_Transactions(t *testing.T) {
+	tr, exp := newTestTracing()
+	m := mock_client.NewMockConnector(gomock.NewController(t))
+
+	other := "0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098"
+	m.EXPECT().Transaction(gomock.Any(), "BTC", txHash).Return(&sochain.Transaction{}, nil)
+	m.EXPECT().Transaction(gomock.Any(), "BTC", other).Return(nil, sochain.NewClientErr(errors.New("not found"), http.StatusNotFound))
+
+	results, err := tr.Connector("sochain", m).Transactions(context.Background(), "BTC", []string{txHash, other})
+	assert.Nil(t, err)
+	assert.Nil(t, results[0].Err)
+	assert.True(t, errors.Is(results[1].Err, sochain.ErrNotFound))
+
+	spans := exp.GetSpans()
+	assert.Len(t, spans, 3)
+
+	batch := spans[2]
+	assert.Equal(t, "sochain.Transactions", batch.Name)
+	assert.Equal(t, int64(2), attr(batch, "sochain.tx_count").AsInt64())
+
+	// every transaction of the batch has its own span
+	byHash := map[string]tracetest.SpanStub{}
+	for _, s := range spans[:2] {
+		assert.Equal(t, "sochain.Transaction", s.Name)
+		assert.Equal(t, trace.SpanKindClient, s.SpanKind)
+		assert.Equal(t, batch.SpanContext.SpanID(), s.Parent.SpanID())
+		byHash[attr(s, "sochain.hash").AsString()] = s
+	}
+	assert.Equal(t, int64(200), attr(byHash[txHash], "sochain.status_code").AsInt64())
+	assert.Equal(t, codes.Unset, byHash[txHash].Status.Code)
+	assert.Equal(t, int64(404), attr(byHash[other], "sochain.status_code").AsInt64())
+	assert.Equal(t, codes.Error, byHash[other].Status.Code)
+	assert.Len(t, byHash[other].Events, 1)
+}
+
+func Test_WriterExporter(t *testing.T) {
+	var buf bytes.Buffer
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(NewWriterExporter(&buf)))
+
+	_, span := tp.Tracer("test").Start(context.Background(), "first")
+	span.End()
+	_, span = tp.Tracer("test").Start(context.Background(), "second")
+	span.End()
+
+	dec := json.NewDecoder(&buf)
+	for _, want := range []string{"first", "second"} {
+		var got struct{ Name string }
+		assert.Nil(t, dec.Decode(&got))
+		assert.Equal(t, want, got.Name)
+	}
+}
+
+func Test_NewExporter(t *testing.T) {
+	exp, err := NewExporter(ExporterNone, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, exp)
+
+	exp, err = NewExporter(ExporterStdout, &bytes.Buffer{})
+	assert.Nil(t, err)
+	assert.IsType(t, &WriterExporter{}, exp)
+
+	_, err = NewExporter("jaeger", nil)
+	assert.NotNil(t, err)
+}
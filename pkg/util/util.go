@@ -2,7 +2,10 @@ package util
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"sochain-client/pkg/network"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -14,18 +17,20 @@ const (
 	EnvironmentProd = "production"
 )
 
-func GetParamNetwork(ctx *gin.Context, key string) (string, error) {
+// Returns the network of path param key, ignoring case. Unknown & disabled networks are rejected
+func GetParamNetwork(ctx *gin.Context, registry *network.Registry, key string) (network.Network, error) {
 
 	v := ctx.Param(key)
 	if v == "" {
-		return "", errors.New("path param: Network id is missing")
+		return network.Network{}, errors.New("path param: Network id is missing")
 	}
 
-	if v != "btc" && v != "ltc" && v != "doge" {
-		return "", errors.New("path param: Network 'id' can only be 'btc', 'eth' or 'ltc'")
+	n, err := registry.Lookup(v)
+	if err != nil {
+		return network.Network{}, fmt.Errorf("path param: Network 'id' can only be one of '%s': %w", strings.Join(registry.IDs(), "', '"), err)
 	}
 
-	return v, nil
+	return n, nil
 }
 
 func NewLogger(ciEnv string) (*zap.Logger, error) {
@@ -0,0 +1,47 @@
+package watcher
+
+import (
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Option configures a Watcher created by NewWatcher
+type Option func(*Watcher)
+
+// WithInterval sets the time between polls of the chain tip of networks without an interval of their own, default 30s
+func WithInterval(d time.Duration) Option {
+	return func(w *Watcher) {
+		w.interval = d
+	}
+}
+
+// WithNetworkInterval sets the time between polls of the chain tip of networkID
+func WithNetworkInterval(networkID string, d time.Duration) Option {
+	return func(w *Watcher) {
+		w.intervals[strings.ToLower(networkID)] = d
+	}
+}
+
+// WithBuffer sets the number of blocks buffered per subscription, default 16
+func WithBuffer(n int) Option {
+	return func(w *Watcher) {
+		w.buffer = n
+	}
+}
+
+// WithMaxGap limits the number of blocks fetched after the tip advanced, subscriptions facing a larger gap are closed
+// with ErrGap. Default 0, every gap is fetched
+func WithMaxGap(n int) Option {
+	return func(w *Watcher) {
+		w.maxGap = n
+	}
+}
+
+// WithLogger logs polling failures & dropped subscriptions to l
+func WithLogger(l *zap.Logger) Option {
+	return func(w *Watcher) {
+		w.logger = l
+	}
+}
@@ -0,0 +1,256 @@
+// Package watcher polls the chain tip of networks & publishes every new block to subscribers. A network is polled
+// while it has at least one subscription
+package watcher
+
+import (
+	"context"
+	"errors"
+	"sochain-client/pkg/sochain"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// the subscriber didn't keep up & its buffer overflowed
+	ErrSlowSubscriber = errors.New("watcher: subscriber too slow")
+	// the tip advanced by more blocks than WithMaxGap allows to fetch
+	ErrGap    = errors.New("watcher: gap of new blocks too large")
+	ErrClosed = errors.New("watcher: closed")
+)
+
+// Subscription receives the new blocks of a network in order of their height. Blocks are never dropped: a subscription
+// whose buffer overflows is closed with ErrSlowSubscriber, one facing a gap beyond WithMaxGap with ErrGap.
+// Subscribers may resubscribe & fetch the missed blocks
+type Subscription struct {
+	w         *Watcher
+	p         *poller
+	networkID string
+	c         chan *sochain.Block
+
+	// guarded by w.mu
+	closed bool
+	err    error
+}
+
+// Blocks returns the channel of new blocks, it's closed once the subscription ends
+func (s *Subscription) Blocks() <-chan *sochain.Block {
+	return s.c
+}
+
+// Err returns why the subscription ended, nil if it's open or was closed by Close
+func (s *Subscription) Err() error {
+	s.w.mu.Lock()
+	defer s.w.mu.Unlock()
+
+	return s.err
+}
+
+// Close ends the subscription, the network stops being polled once its last subscription is closed
+func (s *Subscription) Close() {
+	s.w.mu.Lock()
+	defer s.w.mu.Unlock()
+
+	s.w.remove(s, nil)
+}
+
+type poller struct {
+	cancel context.CancelFunc
+	subs   map[*Subscription]struct{}
+}
+
+type Watcher struct {
+	client    sochain.Connector
+	logger    *zap.Logger
+	interval  time.Duration
+	intervals map[string]time.Duration
+	buffer    int
+	maxGap    int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	pollers map[string]*poller
+	closed  bool
+}
+
+// NewWatcher polls the chain tips of client
+func NewWatcher(client sochain.Connector, opts ...Option) *Watcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Watcher{
+		client:    client,
+		logger:    zap.NewNop(),
+		interval:  30 * time.Second,
+		intervals: map[string]time.Duration{},
+		buffer:    16,
+		ctx:       ctx,
+		cancel:    cancel,
+		pollers:   map[string]*poller{},
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// Subscribe publishes the blocks of networkID found after the next poll to the returned subscription
+func (w *Watcher) Subscribe(networkID string) (*Subscription, error) {
+	networkID = strings.ToLower(networkID)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil, ErrClosed
+	}
+
+	p, ok := w.pollers[networkID]
+	if !ok {
+		ctx, cancel := context.WithCancel(w.ctx)
+		p = &poller{cancel: cancel, subs: map[*Subscription]struct{}{}}
+		w.pollers[networkID] = p
+		go w.poll(ctx, networkID, p)
+	}
+
+	s := &Subscription{w: w, p: p, networkID: networkID, c: make(chan *sochain.Block, w.buffer)}
+	p.subs[s] = struct{}{}
+	return s, nil
+}
+
+// Close stops polling & closes all subscriptions with ErrClosed
+func (w *Watcher) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+	for _, p := range w.pollers {
+		for s := range p.subs {
+			w.remove(s, ErrClosed)
+		}
+	}
+	w.cancel()
+}
+
+// remove ends s with err & stops polling its network once it has no subscription left, w.mu must be held
+func (w *Watcher) remove(s *Subscription, err error) {
+	if s.closed {
+		return
+	}
+	s.closed, s.err = true, err
+	close(s.c)
+
+	delete(s.p.subs, s)
+	if len(s.p.subs) == 0 {
+		s.p.cancel()
+		if w.pollers[s.networkID] == s.p {
+			delete(w.pollers, s.networkID)
+		}
+	}
+}
+
+// publish sends b to all subscriptions of p without blocking
+func (w *Watcher) publish(p *poller, networkID string, b *sochain.Block) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for s := range p.subs {
+		select {
+		case s.c <- b:
+		default:
+			w.logger.Warn("dropping slow subscriber", zap.String("network", networkID), zap.Int("height", b.Data.BlockNo))
+			w.remove(s, ErrSlowSubscriber)
+		}
+	}
+}
+
+// end closes all subscriptions of p with err, which stops polling their network
+func (w *Watcher) end(p *poller, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for s := range p.subs {
+		w.remove(s, err)
+	}
+}
+
+func (w *Watcher) intervalOf(networkID string) time.Duration {
+	if d, ok := w.intervals[networkID]; ok {
+		return d
+	}
+	return w.interval
+}
+
+// poll publishes the blocks of networkID above the tip found by the first poll until ctx is done
+func (w *Watcher) poll(ctx context.Context, networkID string, p *poller) {
+	ticker := time.NewTicker(w.intervalOf(networkID))
+	defer ticker.Stop()
+
+	var last *sochain.Block
+	height := -1
+	for {
+		tip, err := w.tip(ctx, networkID)
+		switch {
+		case err != nil:
+		case height < 0:
+			height = tip
+		case tip > height:
+			last, height = w.fetch(ctx, networkID, p, last, height, tip)
+		case tip < height:
+			// the tip moved back, e.g. by a reorg or a lagging provider after failover, publish from there once it advances
+			w.logger.Info("chain tip moved back", zap.String("network", networkID), zap.Int("from", height), zap.Int("to", tip))
+			last, height = nil, tip
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Watcher) tip(ctx context.Context, networkID string) (int, error) {
+	info, err := w.client.NetworkInfo(ctx, networkID)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.logger.Warn("unable to poll chain tip", zap.String("network", networkID), zap.Error(err))
+		}
+		return 0, err
+	}
+
+	return info.Data.Blocks, nil
+}
+
+// fetch publishes the blocks above height up to tip & returns the last published one with its height. last is the
+// previously published block, nil if unknown
+func (w *Watcher) fetch(ctx context.Context, networkID string, p *poller, last *sochain.Block, height, tip int) (*sochain.Block, int) {
+	if w.maxGap > 0 && tip-height > w.maxGap {
+		w.logger.Warn("ending subscriptions at large gap", zap.String("network", networkID), zap.Int("from", height+1), zap.Int("to", tip))
+		w.end(p, ErrGap)
+		return last, height
+	}
+
+	it := sochain.NewBlockIterator(ctx, w.client, networkID, sochain.HeightRef(height+1), sochain.HeightRef(tip))
+	defer it.Close()
+
+	for it.Next() {
+		b := it.Block()
+		if last != nil && !strings.EqualFold(b.Data.PreviousBlockhash, last.Data.Blockhash) {
+			w.logger.Info("chain reorganized", zap.String("network", networkID), zap.Int("height", b.Data.BlockNo), zap.String("previous", last.Data.Blockhash))
+		}
+
+		w.publish(p, networkID, b)
+		last, height = b, b.Data.BlockNo
+	}
+	if err := it.Err(); err != nil && ctx.Err() == nil {
+		// the missing blocks are fetched by the next poll
+		w.logger.Warn("unable to fetch new blocks", zap.String("network", networkID), zap.Int("height", height+1), zap.Error(err))
+	}
+
+	return last, height
+}
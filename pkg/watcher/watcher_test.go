@@ -0,0 +1,150 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sochain-client/pkg/sochain"
+	mock_client "sochain-client/pkg/sochain/mock"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestWatcher serves a chain whose tip is the value of tip & counts the polls of the tip in polls
+func newTestWatcher(t *testing.T, tip, polls *int64, opts ...Option) *Watcher {
+	mCtrl := gomock.NewController(t)
+	t.Cleanup(mCtrl.Finish)
+
+	hash := func(height int) string {
+		return fmt.Sprintf("%064x", height+1)
+	}
+
+	m := mock_client.NewMockConnector(mCtrl)
+	m.EXPECT().NetworkInfo(gomock.Any(), "btc").AnyTimes().DoAndReturn(func(_ context.Context, _ string) (*sochain.NetworkInfo, error) {
+		atomic.AddInt64(polls, 1)
+		return &sochain.NetworkInfo{Data: sochain.NetworkData{Blocks: int(atomic.LoadInt64(tip))}}, nil
+	})
+	m.EXPECT().BlockHeight(gomock.Any(), "btc", gomock.Any()).AnyTimes().DoAndReturn(func(_ context.Context, _ string, height int) (*sochain.Block, error) {
+		return &sochain.Block{Data: sochain.BlockData{BlockNo: height, Blockhash: hash(height), PreviousBlockhash: hash(height - 1)}}, nil
+	})
+
+	w := NewWatcher(m, append([]Option{WithInterval(time.Millisecond)}, opts...)...)
+	t.Cleanup(w.Close)
+	return w
+}
+
+// receive returns the heights of the next n blocks of s
+func receive(t *testing.T, s *Subscription, n int) []int {
+	t.Helper()
+
+	var heights []int
+	for len(heights) < n {
+		select {
+		case b, ok := <-s.Blocks():
+			if !ok {
+				return heights
+			}
+			heights = append(heights, b.Data.BlockNo)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for blocks")
+		}
+	}
+	return heights
+}
+
+// waitPolled waits until the first poll of the tip completed
+func waitPolled(polls *int64) {
+	for atomic.LoadInt64(polls) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func Test_Watcher(t *testing.T) {
+	var tip, polls int64 = 10, 0
+	w := newTestWatcher(t, &tip, &polls, WithBuffer(128))
+
+	s, err := w.Subscribe("BTC")
+	assert.Nil(t, err)
+	waitPolled(&polls)
+
+	atomic.StoreInt64(&tip, 11)
+	assert.Equal(t, []int{11}, receive(t, s, 1))
+
+	// skipped heights are fetched
+	atomic.StoreInt64(&tip, 14)
+	assert.Equal(t, []int{12, 13, 14}, receive(t, s, 3))
+
+	// however large the gap
+	atomic.StoreInt64(&tip, 128)
+	assert.Len(t, receive(t, s, 114), 114)
+
+	s.Close()
+	_, ok := <-s.Blocks()
+	assert.False(t, ok)
+	assert.Nil(t, s.Err())
+
+	w.mu.Lock()
+	assert.Empty(t, w.pollers)
+	w.mu.Unlock()
+}
+
+func Test_Watcher_MaxGap(t *testing.T) {
+	var tip, polls int64 = 10, 0
+	w := newTestWatcher(t, &tip, &polls, WithMaxGap(2))
+
+	s, err := w.Subscribe("btc")
+	assert.Nil(t, err)
+	waitPolled(&polls)
+
+	atomic.StoreInt64(&tip, 12)
+	assert.Equal(t, []int{11, 12}, receive(t, s, 2))
+
+	// no block of the gap is published, the subscriber learns about it to backfill
+	atomic.StoreInt64(&tip, 20)
+	assert.Empty(t, receive(t, s, 1))
+	assert.True(t, errors.Is(s.Err(), ErrGap))
+
+	w.mu.Lock()
+	assert.Empty(t, w.pollers)
+	w.mu.Unlock()
+}
+
+func Test_Watcher_SlowSubscriber(t *testing.T) {
+	var tip, polls int64 = 10, 0
+	w := newTestWatcher(t, &tip, &polls, WithBuffer(1))
+
+	slow, err := w.Subscribe("btc")
+	assert.Nil(t, err)
+	fast, err := w.Subscribe("btc")
+	assert.Nil(t, err)
+	waitPolled(&polls)
+
+	for height := 11; height <= 13; height++ {
+		atomic.StoreInt64(&tip, int64(height))
+		assert.Equal(t, []int{height}, receive(t, fast, 1))
+	}
+
+	// the slow subscriber keeps the blocks it buffered
+	assert.Equal(t, []int{11}, receive(t, slow, 3))
+	assert.True(t, errors.Is(slow.Err(), ErrSlowSubscriber))
+}
+
+func Test_Watcher_Close(t *testing.T) {
+	var tip, polls int64 = 10, 0
+	w := newTestWatcher(t, &tip, &polls)
+
+	s, err := w.Subscribe("btc")
+	assert.Nil(t, err)
+
+	w.Close()
+	_, ok := <-s.Blocks()
+	assert.False(t, ok)
+	assert.True(t, errors.Is(s.Err(), ErrClosed))
+
+	_, err = w.Subscribe("btc")
+	assert.True(t, errors.Is(err, ErrClosed))
+}